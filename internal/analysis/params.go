@@ -0,0 +1,251 @@
+// Package analysis derives reports from the experiment history, such as
+// which hyperparameters move the metric.
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// ParamReport summarizes how each recorded parameter relates to a metric.
+type ParamReport struct {
+	Metric      string       `yaml:"metric"`
+	Direction   string       `yaml:"direction"`
+	Experiments int          `yaml:"experiments"` // non-failed experiments recording the metric
+	Params      []ParamStats `yaml:"params"`      // most important first
+}
+
+// ParamStats describes one parameter. Importance is the larger of the spread
+// between its best and worst value means and its mean absolute delta when
+// changed, divided by the metric's observed range: 0 means no visible effect,
+// 1 means an effect as large as everything seen so far.
+type ParamStats struct {
+	Name        string        `yaml:"name"`
+	Importance  float64       `yaml:"importance"`
+	Experiments int           `yaml:"experiments"`          // experiments with a known value
+	Changes     int           `yaml:"changes"`              // parent → child changes of this param
+	MeanDelta   *float64      `yaml:"mean_delta,omitempty"` // mean metric change when it changed
+	Values      []ValueStats  `yaml:"values,omitempty"`
+	ChangeLog   []ParamChange `yaml:"change_log,omitempty"`
+}
+
+// ValueStats is the metric distribution across experiments using one value.
+type ValueStats struct {
+	Value string  `yaml:"value"`
+	Count int     `yaml:"count"`
+	Mean  float64 `yaml:"mean"`
+	Min   float64 `yaml:"min"`
+	Max   float64 `yaml:"max"`
+	Best  string  `yaml:"best"` // experiment with the best metric for this value
+}
+
+// ParamChange is one parent → child change of a parameter and the metric
+// delta that went with it. Other changes made at the same step share the
+// same delta.
+type ParamChange struct {
+	Experiment string  `yaml:"experiment"`
+	Parent     string  `yaml:"parent"`
+	From       string  `yaml:"from,omitempty"`
+	To         string  `yaml:"to,omitempty"`
+	Delta      float64 `yaml:"delta"`
+}
+
+// Params builds the report for metricName, or the primary metric when it is
+// empty. Parameter values come from each experiment's params, falling back to
+// the values in its param changes; deltas come from ChangesFrom. Failed
+// experiments are ignored.
+func Params(exps []model.Experiment, proj model.Project, metricName string) (ParamReport, error) {
+	metric := proj.PrimaryMetric()
+	primary := true
+	if metricName != "" && metricName != metric.Name {
+		def, ok := proj.FindMetric(metricName)
+		if !ok {
+			return ParamReport{}, fmt.Errorf("unknown metric %q: declare it in marrow.yaml", metricName)
+		}
+		metric, primary = def, false
+	}
+	valueOf := func(e model.Experiment) (float64, bool) {
+		if e.Status == "failed" {
+			return 0, false
+		}
+		if primary {
+			return e.PrimaryValue(metric), true
+		}
+		return e.MetricValue(metric.Name)
+	}
+
+	report := ParamReport{Metric: metric.Name, Direction: metric.Direction}
+	higher := metric.HigherIsBetter()
+
+	byID := make(map[string]model.Experiment, len(exps))
+	known := make(map[string]bool) // names that appear in some params map
+	for _, e := range exps {
+		byID[e.ID] = e
+		for k := range e.Params {
+			known[k] = true
+		}
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	values := make(map[string]map[string][]sample) // param → value → samples
+	changes := make(map[string][]ParamChange)
+	for _, e := range exps {
+		v, ok := valueOf(e)
+		if !ok {
+			continue
+		}
+		report.Experiments++
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+
+		for name, val := range experimentValues(e) {
+			if values[name] == nil {
+				values[name] = make(map[string][]sample)
+			}
+			values[name][val] = append(values[name][val], sample{id: e.ID, v: v})
+		}
+
+		for pid, cs := range e.ChangesFrom {
+			parent, ok := byID[pid]
+			if !ok {
+				continue
+			}
+			pv, ok := valueOf(parent)
+			if !ok {
+				continue
+			}
+			for _, c := range cs {
+				name := c.Param
+				if c.Type != "param" {
+					if (c.Type != "added" && c.Type != "removed") || !known[c.What] {
+						continue
+					}
+					name = c.What
+				}
+				changes[name] = append(changes[name], ParamChange{
+					Experiment: e.ID, Parent: pid, From: c.From, To: c.To, Delta: v - pv,
+				})
+			}
+		}
+	}
+
+	span := hi - lo
+	names := make(map[string]bool, len(values)+len(changes))
+	for n := range values {
+		names[n] = true
+	}
+	for n := range changes {
+		names[n] = true
+	}
+	for name := range names {
+		ps := ParamStats{Name: name, ChangeLog: changes[name], Changes: len(changes[name])}
+		sort.SliceStable(ps.ChangeLog, func(i, j int) bool {
+			a, b := ps.ChangeLog[i], ps.ChangeLog[j]
+			if a.Experiment != b.Experiment {
+				return a.Experiment < b.Experiment
+			}
+			return a.Parent < b.Parent
+		})
+		for val, samples := range values[name] {
+			ps.Experiments += len(samples)
+			ps.Values = append(ps.Values, valueStats(val, samples, higher))
+		}
+		sortValues(ps.Values)
+
+		effect := 0.0
+		if len(ps.ChangeLog) > 0 {
+			sum, abs := 0.0, 0.0
+			for _, c := range ps.ChangeLog {
+				sum += c.Delta
+				abs += math.Abs(c.Delta)
+			}
+			mean := sum / float64(len(ps.ChangeLog))
+			ps.MeanDelta = &mean
+			effect = abs / float64(len(ps.ChangeLog))
+		}
+		spread := 0.0
+		if len(ps.Values) > 1 {
+			best, worst := math.Inf(-1), math.Inf(1)
+			for _, vs := range ps.Values {
+				best, worst = math.Max(best, vs.Mean), math.Min(worst, vs.Mean)
+			}
+			spread = best - worst
+		}
+		if span > 0 {
+			ps.Importance = math.Round(math.Min(1, math.Max(spread, effect)/span)*1000) / 1000
+		}
+		report.Params = append(report.Params, ps)
+	}
+
+	sort.Slice(report.Params, func(i, j int) bool {
+		a, b := report.Params[i], report.Params[j]
+		if a.Importance != b.Importance {
+			return a.Importance > b.Importance
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+type sample struct {
+	id string
+	v  float64
+}
+
+// experimentValues returns the value of every parameter e records: its
+// params, plus the target of any param change for names params lacks.
+func experimentValues(e model.Experiment) map[string]string {
+	out := make(map[string]string, len(e.Params))
+	for k, v := range e.Params {
+		out[k] = util.FormatParam(v)
+	}
+	for _, cs := range e.ChangesFrom {
+		for _, c := range cs {
+			if c.Type == "param" && c.To != "" {
+				if _, ok := out[c.Param]; !ok {
+					out[c.Param] = c.To
+				}
+			}
+		}
+	}
+	return out
+}
+
+func valueStats(val string, samples []sample, higher bool) ValueStats {
+	vs := ValueStats{Value: val, Count: len(samples), Min: math.Inf(1), Max: math.Inf(-1)}
+	sum := 0.0
+	bestVal := 0.0
+	for i, s := range samples {
+		sum += s.v
+		vs.Min, vs.Max = math.Min(vs.Min, s.v), math.Max(vs.Max, s.v)
+		if i == 0 || (higher && s.v > bestVal) || (!higher && s.v < bestVal) {
+			bestVal, vs.Best = s.v, s.id
+		}
+	}
+	vs.Mean = sum / float64(len(samples))
+	return vs
+}
+
+// sortValues orders values numerically when they all parse as numbers and
+// lexically otherwise.
+func sortValues(vals []ValueStats) {
+	numeric := true
+	for _, v := range vals {
+		if _, err := strconv.ParseFloat(v.Value, 64); err != nil {
+			numeric = false
+			break
+		}
+	}
+	sort.Slice(vals, func(i, j int) bool {
+		if numeric {
+			a, _ := strconv.ParseFloat(vals[i].Value, 64)
+			b, _ := strconv.ParseFloat(vals[j].Value, 64)
+			return a < b
+		}
+		return vals[i].Value < vals[j].Value
+	})
+}
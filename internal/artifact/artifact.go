@@ -0,0 +1,250 @@
+// Package artifact links files to experiments by content hash, so a
+// checkpoint or submission can be checked later against what was recorded.
+// Small files can also be copied into the project's content-addressed
+// store under .marrow/artifacts/.
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+// MaxCopySize is the largest file Attach copies into the store. Larger
+// files, like most checkpoints, are linked in place.
+const MaxCopySize = 10 << 20
+
+// Options controls how a file is attached.
+type Options struct {
+	Kind string // default: guessed from the name
+	Copy bool   // keep a copy in .marrow/artifacts/
+}
+
+var kindsByExt = map[string]string{
+	".pt": "checkpoint", ".pth": "checkpoint", ".ckpt": "checkpoint", ".safetensors": "checkpoint",
+	".bin": "checkpoint", ".h5": "checkpoint", ".keras": "checkpoint", ".onnx": "checkpoint",
+	".pkl": "checkpoint", ".joblib": "checkpoint", ".cbm": "checkpoint", ".ubj": "checkpoint",
+	".csv": "predictions", ".parquet": "predictions", ".npy": "predictions", ".jsonl": "predictions",
+	".png": "plot", ".jpg": "plot", ".jpeg": "plot", ".svg": "plot", ".pdf": "plot", ".html": "plot",
+}
+
+// GuessKind names the kind of artifact at path from its name. Files named
+// like a submission are submissions and directories are checkpoints (as
+// save_pretrained writes them); anything unrecognized is a "file".
+func GuessKind(path string, dir bool) string {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.Contains(base, "submission"):
+		return "submission"
+	case dir:
+		return "checkpoint"
+	}
+	if kind, ok := kindsByExt[filepath.Ext(base)]; ok {
+		return kind
+	}
+	return "file"
+}
+
+// Hash returns the size and sha256 of a file, or of a directory's files
+// taken in lexical order, each contributing its relative path and size as
+// well as its contents.
+func Hash(path string) (int64, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, "", err
+	}
+	h := sha256.New()
+	if !info.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, "", err
+		}
+		defer f.Close()
+		n, err := io.Copy(h, f)
+		if err != nil {
+			return 0, "", err
+		}
+		return n, hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	var total int64
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), fi.Size())
+		n, err := io.Copy(h, f)
+		total += n
+		return err
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return total, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Resolve returns the absolute path of an artifact.
+func Resolve(s *store.Store, a model.Artifact) string {
+	p := filepath.FromSlash(a.Path)
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(s.Root()), p)
+}
+
+// Attach prepares the file or directory at path and records it on exp.
+// The caller writes exp.
+func Attach(s *store.Store, exp *model.Experiment, path string, opts Options) (model.Artifact, error) {
+	a, err := Prepare(s, path, opts)
+	if err != nil {
+		return model.Artifact{}, err
+	}
+	Record(exp, a)
+	return a, nil
+}
+
+// Prepare hashes the file or directory at path and, with opts.Copy, copies
+// a file of at most MaxCopySize into the store. Hashing a large checkpoint
+// takes a while, so call it before taking the store lock.
+func Prepare(s *store.Store, path string, opts Options) (model.Artifact, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return model.Artifact{}, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return model.Artifact{}, err
+	}
+	if opts.Copy && info.IsDir() {
+		return model.Artifact{}, fmt.Errorf("%s is a directory; only files can be copied, link it instead", path)
+	}
+	if opts.Copy && info.Size() > MaxCopySize {
+		return model.Artifact{}, fmt.Errorf("%s is %s, over the %s copy limit; link it instead", path, FormatSize(info.Size()), FormatSize(MaxCopySize))
+	}
+
+	size, sum, err := Hash(abs)
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("hashing %s: %w", path, err)
+	}
+	a := model.Artifact{Path: projectRelative(s, abs), Kind: opts.Kind, Size: size, SHA256: sum}
+	if a.Kind == "" {
+		a.Kind = GuessKind(abs, info.IsDir())
+	}
+	if opts.Copy {
+		if err := s.StoreArtifact(abs, sum); err != nil {
+			return model.Artifact{}, fmt.Errorf("storing %s: %w", path, err)
+		}
+		a.Stored = true
+	}
+	return a, nil
+}
+
+// Record adds a to exp, replacing an earlier record of the same path.
+func Record(exp *model.Experiment, a model.Artifact) {
+	artifacts := make([]model.Artifact, 0, len(exp.Artifacts)+1)
+	for _, old := range exp.Artifacts {
+		if old.Path != a.Path {
+			artifacts = append(artifacts, old)
+		}
+	}
+	exp.Artifacts = append(artifacts, a)
+}
+
+// projectRelative returns abs relative to the project directory when it is
+// inside it, and as is otherwise, with forward slashes.
+func projectRelative(s *store.Store, abs string) string {
+	rel, err := filepath.Rel(filepath.Dir(s.Root()), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Problem is an artifact that no longer matches its record.
+type Problem struct {
+	Experiment string
+	Artifact   model.Artifact
+	Issue      string // missing | modified | copy missing | copy modified
+	Detail     string
+}
+
+// Verify rehashes every artifact of exps and reports the ones that are
+// missing or modified, along with stored copies that are. It also returns
+// how many artifacts were checked.
+func Verify(s *store.Store, exps []model.Experiment) ([]Problem, int, error) {
+	var problems []Problem
+	checked := 0
+	for _, e := range exps {
+		for _, a := range e.Artifacts {
+			checked++
+			problem := Problem{Experiment: e.ID, Artifact: a}
+			size, sum, err := Hash(Resolve(s, a))
+			switch {
+			case os.IsNotExist(err):
+				problem.Issue = "missing"
+				if a.Stored {
+					problem.Detail = "a copy is stored at " + projectRelative(s, s.ArtifactCopyPath(a.SHA256))
+				}
+				problems = append(problems, problem)
+			case err != nil:
+				return nil, checked, fmt.Errorf("%s: hashing %s: %w", e.ID, a.Path, err)
+			case sum != a.SHA256:
+				problem.Issue = "modified"
+				problem.Detail = fmt.Sprintf("%s → %s", FormatSize(a.Size), FormatSize(size))
+				problems = append(problems, problem)
+			}
+
+			if !a.Stored {
+				continue
+			}
+			problem = Problem{Experiment: e.ID, Artifact: a}
+			_, sum, err = Hash(s.ArtifactCopyPath(a.SHA256))
+			switch {
+			case os.IsNotExist(err):
+				problem.Issue = "copy missing"
+				problems = append(problems, problem)
+			case err != nil:
+				return nil, checked, fmt.Errorf("%s: hashing the stored copy of %s: %w", e.ID, a.Path, err)
+			case sum != a.SHA256:
+				problem.Issue = "copy modified"
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems, checked, nil
+}
+
+// FormatSize renders a byte count with a binary unit, e.g. "1.5 MiB".
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
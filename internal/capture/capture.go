@@ -0,0 +1,309 @@
+// Package capture records the software, hardware, data and code an
+// experiment ran with, for "marrow exp new" and log_experiment.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// DefaultPackages are recorded when marrow.yaml does not list any.
+var DefaultPackages = []string{
+	"torch", "tensorflow", "jax", "transformers", "scikit-learn",
+	"xgboost", "lightgbm", "catboost", "numpy", "pandas",
+}
+
+// commandTimeout bounds each external command so a hung pip or nvidia-smi
+// cannot stall logging.
+const commandTimeout = 15 * time.Second
+
+// Environment captures the current environment. projectDir is the directory
+// holding .marrow/; relative paths in cfg are resolved against it. Anything
+// that cannot be captured is skipped and reported as a warning.
+func Environment(projectDir string, cfg model.EnvConfig) (*model.Environment, []string) {
+	env := &model.Environment{}
+	var warnings []string
+
+	python, err := findPython(cfg.Python)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	} else if v, err := pythonVersion(python); err != nil {
+		warnings = append(warnings, err.Error())
+	} else {
+		env.Python = v
+	}
+
+	pkgs, err := pipPackages(python, cfg.Packages)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	} else {
+		env.KeyPackages = pkgs
+	}
+
+	if gpu, err := gpuName(); err != nil {
+		warnings = append(warnings, err.Error())
+	} else {
+		env.GPU = gpu
+	}
+
+	if len(cfg.Data) > 0 {
+		if h, err := HashPaths(projectDir, cfg.Data); err != nil {
+			warnings = append(warnings, fmt.Sprintf("data hash: %v", err))
+		} else {
+			env.DataHash = h
+		}
+	}
+	if len(cfg.Preprocessing) > 0 {
+		if h, err := HashPaths(projectDir, cfg.Preprocessing); err != nil {
+			warnings = append(warnings, fmt.Sprintf("preprocessing hash: %v", err))
+		} else {
+			env.PreprocessingHash = h
+		}
+	}
+
+	return env, warnings
+}
+
+// Merge returns base with every field set in override replacing it. Either
+// may be nil.
+func Merge(base, override *model.Environment) *model.Environment {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	out := *base
+	if override.Python != "" {
+		out.Python = override.Python
+	}
+	if override.GPU != "" {
+		out.GPU = override.GPU
+	}
+	if override.DataHash != "" {
+		out.DataHash = override.DataHash
+	}
+	if override.PreprocessingHash != "" {
+		out.PreprocessingHash = override.PreprocessingHash
+	}
+	if override.GitCommit != "" {
+		// The branch and dirty state describe the commit, so they come along.
+		out.GitCommit, out.GitBranch, out.GitDirty = override.GitCommit, override.GitBranch, override.GitDirty
+	} else if override.GitBranch != "" {
+		out.GitBranch = override.GitBranch
+	}
+	if override.SplitSeed != nil {
+		out.SplitSeed = override.SplitSeed
+	}
+	if len(override.KeyPackages) > 0 {
+		pkgs := make(map[string]string, len(base.KeyPackages)+len(override.KeyPackages))
+		for k, v := range base.KeyPackages {
+			pkgs[k] = v
+		}
+		for k, v := range override.KeyPackages {
+			pkgs[k] = v
+		}
+		out.KeyPackages = pkgs
+	}
+	return &out
+}
+
+func run(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return out.Bytes(), nil
+}
+
+func findPython(configured string) (string, error) {
+	candidates := []string{"python", "python3"}
+	if configured != "" {
+		candidates = []string{configured}
+	}
+	for _, c := range candidates {
+		if path, err := exec.LookPath(c); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("python not found on PATH (tried %s)", strings.Join(candidates, ", "))
+}
+
+// pythonVersion returns the version from "Python 3.11.4". Python 2 prints it
+// on stderr, which run captures too.
+func pythonVersion(python string) (string, error) {
+	out, err := run(python, "--version")
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(out))
+	v = strings.TrimSpace(strings.TrimPrefix(v, "Python"))
+	if v == "" {
+		return "", fmt.Errorf("%s --version printed nothing", python)
+	}
+	return v, nil
+}
+
+// pipPackages runs pip freeze and keeps the wanted packages. It asks python
+// (the interpreter whose version is recorded) via "python -m pip", so both
+// describe the same environment; pip from PATH is used only when no
+// interpreter was found. Names are compared the way pip normalises them, so
+// scikit_learn matches scikit-learn.
+func pipPackages(python string, wanted []string) (map[string]string, error) {
+	if len(wanted) == 0 {
+		wanted = DefaultPackages
+	}
+	var out []byte
+	var err error
+	if python != "" {
+		out, err = run(python, "-m", "pip", "freeze")
+	} else {
+		if _, err := exec.LookPath("pip"); err != nil {
+			return nil, fmt.Errorf("pip not found on PATH; package versions not recorded")
+		}
+		out, err = run("pip", "freeze")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	want := make(map[string]string, len(wanted))
+	for _, name := range wanted {
+		want[normalizePackage(name)] = name
+	}
+
+	pkgs := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		name, version, ok := strings.Cut(strings.TrimSpace(sc.Text()), "==")
+		if !ok {
+			continue // editable installs, "pkg @ url" and comments carry no version
+		}
+		if label, ok := want[normalizePackage(name)]; ok {
+			pkgs[label] = strings.TrimSpace(version)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	return pkgs, nil
+}
+
+func normalizePackage(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// gpuName lists the GPUs nvidia-smi reports, e.g. "2x NVIDIA A100-SXM4-80GB".
+// A machine without nvidia-smi has no GPU to record and is not an error.
+func gpuName() (string, error) {
+	if _, err := exec.LookPath("nvidia-smi"); err != nil {
+		return "", nil
+	}
+	out, err := run("nvidia-smi", "--query-gpu=name", "--format=csv,noheader")
+	if err != nil {
+		return "", err
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, line := range strings.Split(string(out), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		if counts[name] == 0 {
+			order = append(order, name)
+		}
+		counts[name]++
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, name := range order {
+		if counts[name] > 1 {
+			parts = append(parts, fmt.Sprintf("%dx %s", counts[name], name))
+		} else {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// HashPaths hashes the named files and directories into a single digest,
+// "sha256:" followed by 16 hex digits. Directories are walked in lexical order and each
+// file contributes its path relative to dir as well as its contents, so
+// renames change the hash.
+func HashPaths(dir string, paths []string) (string, error) {
+	h := sha256.New()
+	for _, p := range paths {
+		root := p
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(dir, root)
+		}
+		if _, err := os.Stat(root); err != nil {
+			return "", err
+		}
+
+		var files []string
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		sort.Strings(files)
+
+		for _, f := range files {
+			rel, err := filepath.Rel(dir, f)
+			if err != nil {
+				rel = f
+			}
+			if err := hashFile(h, f, filepath.ToSlash(rel)); err != nil {
+				return "", err
+			}
+		}
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// hashFile writes name, size and contents so that neighbouring files cannot
+// run together into the same byte stream.
+func hashFile(w io.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s\x00%d\x00", name, info.Size())
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,146 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// outsideMarrow limits git commands to the whole work tree minus .marrow/,
+// which logging an experiment writes to. It is relative to the project dir.
+var outsideMarrow = []string{"--", ":/", ":!.marrow"}
+
+// Git returns the HEAD commit, branch and dirty state of the git work tree
+// holding projectDir, or nil outside one, before the first commit, or when
+// git is not installed. Untracked files count as changes; ignored files and
+// anything under .marrow/ do not. The branch is empty on a detached HEAD.
+func Git(projectDir string) *model.Environment {
+	out, err := gitOutput(projectDir, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return nil
+	}
+	env := &model.Environment{GitCommit: strings.TrimSpace(string(out))}
+	if out, err := gitOutput(projectDir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		env.GitBranch = strings.TrimSpace(string(out))
+	}
+	args := append([]string{"status", "--porcelain"}, outsideMarrow...)
+	if out, err := gitOutput(projectDir, args...); err == nil {
+		env.GitDirty = len(bytes.TrimSpace(out)) > 0
+	}
+	return env
+}
+
+// GitDiff returns the uncommitted changes to tracked files against HEAD as
+// a patch that "git apply" takes from the top of the work tree. Untracked
+// files and .marrow/ are left out.
+func GitDiff(projectDir string) ([]byte, error) {
+	args := append([]string{"diff", "--binary", "HEAD"}, outsideMarrow...)
+	return gitOutput(projectDir, args...)
+}
+
+// GitPatch returns the uncommitted changes to save with an experiment whose
+// environment is env, or nil when its tree was clean. It fails outside a
+// repository and when env names a commit other than HEAD, since the diff
+// would not apply to it.
+func GitPatch(projectDir string, env *model.Environment) ([]byte, error) {
+	if env == nil || env.GitCommit == "" {
+		return nil, fmt.Errorf("not in a git repository; no diff saved")
+	}
+	if !env.GitDirty {
+		return nil, nil
+	}
+	head, err := gitOutput(projectDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if h := strings.TrimSpace(string(head)); !strings.HasPrefix(h, env.GitCommit) {
+		return nil, fmt.Errorf("git_commit %s is not HEAD (%s); no diff saved", env.GitCommit, h[:min(12, len(h))])
+	}
+	return GitDiff(projectDir)
+}
+
+// CommitRange lists the commits reachable from to but not from, newest
+// first, as "<short hash> <subject>" lines. It fails when either commit is
+// unknown to the repository holding projectDir.
+func CommitRange(projectDir, from, to string) ([]string, error) {
+	from, err := resolveCommit(projectDir, from)
+	if err != nil {
+		return nil, err
+	}
+	if to, err = resolveCommit(projectDir, to); err != nil {
+		return nil, err
+	}
+	out, err := gitOutput(projectDir, "log", "--format=%h %s", "--end-of-options", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// gitOutput runs git in dir and returns its stdout. The error carries
+// git's stderr.
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// AddWorktree checks out commit into a new worktree at path with a
+// detached HEAD, leaving the current checkout alone.
+func AddWorktree(projectDir, path, commit string) error {
+	commit, err := resolveCommit(projectDir, commit)
+	if err != nil {
+		return err
+	}
+	_, err = gitOutput(projectDir, "worktree", "add", "--detach", "--", path, commit)
+	return err
+}
+
+// RemoveWorktree removes a worktree created by AddWorktree, along with any
+// changes made in it.
+func RemoveWorktree(projectDir, path string) error {
+	_, err := gitOutput(projectDir, "worktree", "remove", "--force", "--", path)
+	return err
+}
+
+// resolveCommit turns a recorded commit hash into the full hash of a commit
+// in the repository. Recorded values can come from imports or hand edits,
+// so anything but a hex hash is refused before git sees it.
+func resolveCommit(projectDir, commit string) (string, error) {
+	if !util.ValidGitCommit(commit) {
+		return "", fmt.Errorf("invalid git commit %q: must be a hex commit hash", commit)
+	}
+	out, err := gitOutput(projectDir, "rev-parse", "--verify", "--quiet", "--end-of-options", commit+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("commit %s not found in this repository", commit)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ApplyPatch applies a patch saved by GitDiff to the work tree at dir.
+func ApplyPatch(dir, patchFile string) error {
+	_, err := gitOutput(dir, "apply", "--whitespace=nowarn", patchFile)
+	return err
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rzzdr/marrow/internal/analysis"
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze the experiment history",
+}
+
+var (
+	analyzeParamsMetric string
+	analyzeParamsDepth  string
+	analyzeParamsFormat string
+)
+
+var analyzeParamsCmd = &cobra.Command{
+	Use:   "params",
+	Short: "Rank hyperparameters by how much they move the metric",
+	Long: `For each recorded parameter, show the metric distribution across its values,
+the mean metric delta when it changed between parent and child, and an
+importance score from 0 (no visible effect) to 1 (as large as the metric's
+whole observed range). Parameters come from experiment params and param
+changes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if analyzeParamsFormat != "text" && analyzeParamsFormat != "yaml" {
+			return fmt.Errorf("invalid format %q: must be text|yaml", analyzeParamsFormat)
+		}
+
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		proj, err := s.ReadProject()
+		if err != nil {
+			return err
+		}
+		exps, err := s.ListExperiments()
+		if err != nil {
+			return err
+		}
+
+		report, err := analysis.Params(exps, proj, analyzeParamsMetric)
+		if err != nil {
+			return err
+		}
+
+		depth := model.ParseDepth(analyzeParamsDepth)
+		if analyzeParamsFormat == "yaml" {
+			out, err := format.MarshalYAMLString(format.FilterParamReport(report, depth))
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		}
+		fmt.Print(format.ParamReportText(report, depth))
+		return nil
+	},
+}
+
+func init() {
+	analyzeParamsCmd.Flags().StringVar(&analyzeParamsMetric, "metric", "", "Metric to analyze (default: the primary metric)")
+	analyzeParamsCmd.Flags().StringVar(&analyzeParamsDepth, "depth", "standard", "summary|standard|full")
+	analyzeParamsCmd.Flags().StringVar(&analyzeParamsFormat, "format", "text", "text|yaml")
+
+	analyzeCmd.AddCommand(analyzeParamsCmd)
+}
@@ -2,12 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/rzzdr/marrow/internal/capture"
 	"github.com/rzzdr/marrow/internal/format"
 	"github.com/rzzdr/marrow/internal/index"
 	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
 	"github.com/rzzdr/marrow/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -21,9 +25,23 @@ var (
 	expBaseModel string
 	expParents   string
 	expMetric    float64
+	expMetrics   string
 	expStatus    string
 	expTags      string
 	expNotes     string
+
+	expChanges       []string
+	expReasoning     string
+	expReasoningType string
+	expEvidence      []string
+	expLocalCV       float64
+	expPublicLB      float64
+	expDataVersion   int
+	expEnv           string
+	expCaptureEnv    bool
+	expParamsFile    string
+	expNoGit         bool
+	expGitDiff       bool
 )
 
 var validStatuses = map[string]bool{
@@ -48,63 +66,151 @@ var expNewCmd = &cobra.Command{
 			return err
 		}
 
-		id, err := s.NextExperimentID()
+		metric := proj.PrimaryMetric()
+
+		var extraMetrics map[string]float64
+		if expMetrics != "" {
+			extraMetrics, err = util.ParseMetrics(expMetrics)
+			if err != nil {
+				return err
+			}
+			if err := proj.ValidateMetricValues(extraMetrics); err != nil {
+				return err
+			}
+		}
+
+		parents := util.SplitTags(expParents)
+		changes, err := util.ParseChanges(expChanges, parents)
 		if err != nil {
 			return err
 		}
-
-		exp := model.Experiment{
-			ID:        id,
-			Timestamp: time.Now().UTC(),
-			BaseModel: expBaseModel,
-			Status:    expStatus,
-			Metric: model.MetricResult{
-				Name:  proj.Metric.Name,
-				Value: expMetric,
-			},
-			Notes: expNotes,
+		reasoning, err := util.ParseReasoning(expReasoning, expReasoningType, expEvidence)
+		if err != nil {
+			return err
 		}
-
-		if expParents != "" {
-			exp.Parents = util.SplitTags(expParents)
-			for _, pid := range exp.Parents {
-				if _, err := s.ReadExperiment(pid); err != nil {
-					return fmt.Errorf("parent experiment %q not found", pid)
+		env, err := util.ParseEnvironment(expEnv)
+		if err != nil {
+			return err
+		}
+		if expCaptureEnv {
+			var cfg model.EnvConfig
+			if proj.Environment != nil {
+				cfg = *proj.Environment
+			}
+			captured, warnings := capture.Environment(filepath.Dir(s.Root()), cfg)
+			for _, w := range warnings {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+			}
+			env = capture.Merge(captured, env)
+		}
+		var patch []byte
+		if !expNoGit {
+			env = capture.Merge(capture.Git(filepath.Dir(s.Root())), env)
+			if expGitDiff {
+				if patch, err = capture.GitPatch(filepath.Dir(s.Root()), env); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
 				}
 			}
 		}
-		if expTags != "" {
-			exp.Tags = util.SplitTags(expTags)
+		var params map[string]any
+		if expParamsFile != "" {
+			if params, err = util.LoadParamsFile(expParamsFile); err != nil {
+				return err
+			}
+		}
+		if expDataVersion < 0 {
+			return fmt.Errorf("invalid data version %d: must not be negative", expDataVersion)
 		}
 
-		// Compute delta relative to best parent or current best
-		if len(exp.Parents) > 0 {
-			if parent, err := s.ReadExperiment(exp.Parents[0]); err == nil {
-				exp.Metric.Baseline = parent.Metric.Value
-				exp.Metric.Delta = exp.Metric.Value - parent.Metric.Value
+		var id string
+		err = s.WithLock(func(s *store.Store) error {
+			var err error
+			id, err = s.NextExperimentID()
+			if err != nil {
+				return err
 			}
-		} else {
-			curIdx, err := s.ReadIndex()
-			if err == nil && curIdx.Computed.BestMetric != nil {
-				exp.Metric.Baseline = curIdx.Computed.BestMetric.Value
-				exp.Metric.Delta = exp.Metric.Value - curIdx.Computed.BestMetric.Value
+
+			exp := model.Experiment{
+				ID:        id,
+				Timestamp: time.Now().UTC(),
+				BaseModel: expBaseModel,
+				Status:    expStatus,
+				Metric: model.MetricResult{
+					Name:  metric.Name,
+					Value: expMetric,
+				},
+				Metrics:     extraMetrics,
+				Parents:     parents,
+				ChangesFrom: changes,
+				Params:      params,
+				Reasoning:   reasoning,
+				Environment: env,
+				DataVersion: expDataVersion,
+				Notes:       expNotes,
+			}
+			if cmd.Flags().Changed("local-cv") {
+				exp.LocalCV = &expLocalCV
+			}
+			if cmd.Flags().Changed("public-lb") {
+				exp.PublicLB = &expPublicLB
 			}
-		}
 
-		if err := s.WriteExperiment(exp); err != nil {
-			return err
-		}
+			for _, pid := range exp.Parents {
+				parent, err := s.ReadExperiment(pid)
+				if err != nil {
+					return fmt.Errorf("parent experiment %q not found", pid)
+				}
+				exp.ChangesFrom = util.AddParamChanges(exp.ChangesFrom, pid, parent.Params, exp.Params)
+			}
+			for eid := range exp.Reasoning.Evidence {
+				if _, err := s.ReadExperiment(eid); err != nil {
+					return fmt.Errorf("evidence experiment %q not found", eid)
+				}
+			}
+			if expTags != "" {
+				exp.Tags = util.SplitTags(expTags)
+			}
+			if len(patch) > 0 {
+				a, err := s.WriteGitDiff(id, patch)
+				if err != nil {
+					return err
+				}
+				exp.Artifacts = append(exp.Artifacts, a)
+			}
 
-		if _, err := index.UpdateIncremental(s, exp); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: index update failed: %v\n", err)
-		}
+			// Compute delta relative to best parent or current best
+			if len(exp.Parents) > 0 {
+				if parent, err := s.ReadExperiment(exp.Parents[0]); err == nil {
+					exp.Metric.Baseline = parent.PrimaryValue(metric)
+					exp.Metric.Delta = exp.Metric.Value - exp.Metric.Baseline
+				}
+			} else {
+				curIdx, err := s.ReadIndex()
+				if err == nil && curIdx.Computed.BestMetric != nil {
+					exp.Metric.Baseline = curIdx.Computed.BestMetric.Value
+					exp.Metric.Delta = exp.Metric.Value - curIdx.Computed.BestMetric.Value
+				}
+			}
+
+			if err := s.WriteExperiment(exp); err != nil {
+				return err
+			}
 
-		if err := s.AppendChangelog(model.ChangelogEntry{
-			Action:  "exp_logged",
-			ID:      id,
-			Summary: format.ExperimentOneLiner(exp),
-		}); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+			if _, err := index.UpdateIncremental(s, exp); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: index update failed: %v\n", err)
+			}
+
+			if err := s.AppendChangelog(model.ChangelogEntry{
+				Action:  "exp_logged",
+				ID:      id,
+				Summary: format.ExperimentOneLiner(exp),
+			}); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		fmt.Printf("Created experiment %s\n", id)
@@ -116,6 +222,7 @@ var (
 	expListStatus string
 	expListTag    string
 	expListLimit  int
+	expListSort   string
 )
 
 var (
@@ -166,6 +273,18 @@ var expListCmd = &cobra.Command{
 			exps = filtered
 		}
 
+		if expListSort != "" {
+			proj, err := s.ReadProject()
+			if err != nil {
+				return err
+			}
+			def, ok := proj.FindMetric(expListSort)
+			if !ok {
+				return fmt.Errorf("unknown metric %q", expListSort)
+			}
+			sortByMetric(exps, def)
+		}
+
 		if len(exps) == 0 {
 			fmt.Println("No experiments match.")
 			return nil
@@ -182,6 +301,23 @@ var expListCmd = &cobra.Command{
 	},
 }
 
+// sortByMetric orders experiments worst to best on def, so that --limit keeps
+// the leaders. Experiments without a value for def sort first.
+func sortByMetric(exps []model.Experiment, def model.MetricDef) {
+	higher := def.HigherIsBetter()
+	sort.SliceStable(exps, func(i, j int) bool {
+		vi, oki := exps[i].MetricValue(def.Name)
+		vj, okj := exps[j].MetricValue(def.Name)
+		if oki != okj {
+			return !oki
+		}
+		if higher {
+			return vi < vj
+		}
+		return vi > vj
+	})
+}
+
 var expShowCmd = &cobra.Command{
 	Use:   "show [id]",
 	Short: "Show full details of an experiment",
@@ -210,14 +346,28 @@ func init() {
 	expNewCmd.Flags().StringVar(&expBaseModel, "model", "", "Base model family (e.g. xgboost, resnet)")
 	expNewCmd.Flags().StringVar(&expParents, "parents", "", "Comma-separated parent experiment IDs")
 	expNewCmd.Flags().Float64Var(&expMetric, "metric", 0, "Primary metric value")
+	expNewCmd.Flags().StringVar(&expMetrics, "metrics", "", "Secondary metric values (e.g. f1=0.71,latency_ms=12)")
 	expNewCmd.Flags().StringVar(&expStatus, "status", "neutral", "Outcome: improved|degraded|neutral|failed")
 	expNewCmd.Flags().StringVar(&expTags, "tags", "", "Comma-separated tags")
 	expNewCmd.Flags().StringVar(&expNotes, "notes", "", "Freeform notes")
+	expNewCmd.Flags().StringArrayVar(&expChanges, "change", nil, "Change from a parent, repeatable (e.g. param:lr:0.1->0.01, added:target_encoding, exp_002:removed:dropout)")
+	expNewCmd.Flags().StringVar(&expReasoning, "reasoning", "", "Why this experiment was run")
+	expNewCmd.Flags().StringVar(&expReasoningType, "reasoning-type", "", "How well-founded the reasoning is: proven|assumption|unknown (default unknown when reasoning is given)")
+	expNewCmd.Flags().StringArrayVar(&expEvidence, "evidence", nil, "Supporting observation from an earlier experiment, repeatable (e.g. exp_003=lr too high, loss diverged)")
+	expNewCmd.Flags().Float64Var(&expLocalCV, "local-cv", 0, "Local cross-validation score")
+	expNewCmd.Flags().Float64Var(&expPublicLB, "public-lb", 0, "Public leaderboard score")
+	expNewCmd.Flags().IntVar(&expDataVersion, "data-version", 0, "Version of the dataset used")
+	expNewCmd.Flags().StringVar(&expEnv, "env", "", "Environment (e.g. python=3.11,gpu=A100,split_seed=42,torch=2.3)")
+	expNewCmd.Flags().StringVar(&expParamsFile, "params-file", "", "YAML or JSON file of hyperparameters; changes from each parent are computed from it")
+	expNewCmd.Flags().BoolVar(&expCaptureEnv, "capture-env", false, "Record python, package and GPU versions and hash the data paths in marrow.yaml (--env values win)")
+	expNewCmd.Flags().BoolVar(&expNoGit, "no-git", false, "Don't record the git commit, branch and dirty state")
+	expNewCmd.Flags().BoolVar(&expGitDiff, "git-diff", false, "Save uncommitted changes to tracked files as a diff artifact")
 	_ = expNewCmd.MarkFlagRequired("metric")
 
 	expListCmd.Flags().StringVar(&expListStatus, "status", "", "Filter by status: improved|degraded|neutral|failed")
 	expListCmd.Flags().StringVar(&expListTag, "tag", "", "Filter by tag (comma-separated)")
 	expListCmd.Flags().IntVar(&expListLimit, "limit", 0, "Show only the last N experiments")
+	expListCmd.Flags().StringVar(&expListSort, "sort", "", "Sort by a declared metric, best last")
 
 	expEditCmd.Flags().StringVar(&expEditNotes, "notes", "", "New notes")
 	expEditCmd.Flags().StringVar(&expEditStatus, "status", "", "New status: improved|degraded|neutral|failed")
@@ -240,46 +390,52 @@ var expEditCmd = &cobra.Command{
 			return err
 		}
 
-		exp, err := s.ReadExperiment(args[0])
-		if err != nil {
-			return fmt.Errorf("reading experiment %s: %w", args[0], err)
-		}
+		err = s.WithLock(func(s *store.Store) error {
+			exp, err := s.ReadExperiment(args[0])
+			if err != nil {
+				return fmt.Errorf("reading experiment %s: %w", args[0], err)
+			}
 
-		changed := false
-		if cmd.Flags().Changed("notes") {
-			exp.Notes = expEditNotes
-			changed = true
-		}
-		if cmd.Flags().Changed("status") {
-			if !validStatuses[expEditStatus] {
-				return fmt.Errorf("invalid status %q: must be improved|degraded|neutral|failed", expEditStatus)
+			changed := false
+			if cmd.Flags().Changed("notes") {
+				exp.Notes = expEditNotes
+				changed = true
+			}
+			if cmd.Flags().Changed("status") {
+				if !validStatuses[expEditStatus] {
+					return fmt.Errorf("invalid status %q: must be improved|degraded|neutral|failed", expEditStatus)
+				}
+				exp.Status = expEditStatus
+				changed = true
+			}
+			if cmd.Flags().Changed("tags") {
+				exp.Tags = util.SplitTags(expEditTags)
+				changed = true
 			}
-			exp.Status = expEditStatus
-			changed = true
-		}
-		if cmd.Flags().Changed("tags") {
-			exp.Tags = util.SplitTags(expEditTags)
-			changed = true
-		}
 
-		if !changed {
-			return fmt.Errorf("nothing to edit; use --notes, --status, or --tags")
-		}
+			if !changed {
+				return fmt.Errorf("nothing to edit; use --notes, --status, or --tags")
+			}
 
-		if err := s.WriteExperiment(exp); err != nil {
-			return err
-		}
+			if err := s.WriteExperiment(exp); err != nil {
+				return err
+			}
 
-		if _, err := index.Rebuild(s); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: index rebuild failed: %v\n", err)
-		}
+			if _, err := index.Rebuild(s); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: index rebuild failed: %v\n", err)
+			}
 
-		if err := s.AppendChangelog(model.ChangelogEntry{
-			Action:  "exp_edited",
-			ID:      args[0],
-			Summary: "edited experiment " + args[0],
-		}); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+			if err := s.AppendChangelog(model.ChangelogEntry{
+				Action:  "exp_edited",
+				ID:      args[0],
+				Summary: "edited experiment " + args[0],
+			}); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		fmt.Printf("Updated experiment %s\n", args[0])
@@ -297,28 +453,34 @@ var expDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		refs, err := s.FindParentRefs(args[0])
-		if err != nil {
-			return err
-		}
-		if len(refs) > 0 {
-			return fmt.Errorf("cannot delete %s: referenced as parent by %s", args[0], strings.Join(refs, ", "))
-		}
+		err = s.WithLock(func(s *store.Store) error {
+			refs, err := s.FindParentRefs(args[0])
+			if err != nil {
+				return err
+			}
+			if len(refs) > 0 {
+				return fmt.Errorf("cannot delete %s: referenced as parent by %s", args[0], strings.Join(refs, ", "))
+			}
 
-		if err := s.DeleteExperiment(args[0]); err != nil {
-			return err
-		}
+			if err := s.DeleteExperiment(args[0]); err != nil {
+				return err
+			}
 
-		if err := s.AppendChangelog(model.ChangelogEntry{
-			Action:  "exp_deleted",
-			ID:      args[0],
-			Summary: "deleted experiment " + args[0],
-		}); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
-		}
+			if err := s.AppendChangelog(model.ChangelogEntry{
+				Action:  "exp_deleted",
+				ID:      args[0],
+				Summary: "deleted experiment " + args[0],
+			}); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+			}
 
-		if _, err := index.Rebuild(s); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: index rebuild failed: %v\n", err)
+			if _, err := index.Rebuild(s); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: index rebuild failed: %v\n", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		fmt.Printf("Deleted experiment %s\n", args[0])
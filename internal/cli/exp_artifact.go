@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rzzdr/marrow/internal/artifact"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expAttachCopy bool
+	expAttachLink bool
+	expAttachKind string
+)
+
+var expAttachCmd = &cobra.Command{
+	Use:   "attach <id> <path>",
+	Short: "Link a checkpoint, predictions, plot or other file to an experiment",
+	Long: `Record a file or directory on an experiment with its size and sha256,
+so it can be checked later with "marrow exp verify".
+
+  marrow exp attach exp_007 checkpoints/best.pt
+  marrow exp attach exp_007 out/submission.csv --copy
+  marrow exp attach exp_007 runs/exp7/model --kind checkpoint
+
+--link (the default) records the path and leaves the file where it is.
+--copy also keeps a copy in .marrow/artifacts/, named by its sha256, for
+files up to 10 MiB. Paths inside the project are recorded relative to it.
+The kind (checkpoint, predictions, plot, submission or file) is guessed from
+the name unless --kind is given. Attaching the same path again updates its
+record.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		id, path := args[0], args[1]
+		if _, err := s.ReadExperiment(id); err != nil {
+			return fmt.Errorf("reading experiment %s: %w", id, err)
+		}
+
+		// Hash (and copy) before taking the lock; a large checkpoint would
+		// otherwise keep other writers waiting past their lock timeout.
+		a, err := artifact.Prepare(s, path, artifact.Options{Kind: expAttachKind, Copy: expAttachCopy})
+		if err != nil {
+			return err
+		}
+
+		err = s.WithLock(func(s *store.Store) error {
+			exp, err := s.ReadExperiment(id)
+			if err != nil {
+				return fmt.Errorf("reading experiment %s: %w", id, err)
+			}
+			artifact.Record(&exp, a)
+			if err := s.WriteExperiment(exp); err != nil {
+				return err
+			}
+
+			if err := s.AppendChangelog(model.ChangelogEntry{
+				Action:  "artifact_attached",
+				ID:      id,
+				Type:    a.Kind,
+				Summary: fmt.Sprintf("attached %s to %s", a.Path, id),
+			}); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Attached %s to %s (%s, %s, sha256 %s)\n", a.Path, id, a.Kind, artifact.FormatSize(a.Size), a.SHA256[:12])
+		if a.Stored {
+			fmt.Println("A copy is stored in .marrow/artifacts/")
+		}
+		return nil
+	},
+}
+
+var expVerifyCmd = &cobra.Command{
+	Use:   "verify [id...]",
+	Short: "Check that attached artifacts still match their recorded hashes",
+	Long: `Rehash the artifacts of the given experiments, or of every experiment,
+and report any that are missing or modified. Stored copies in
+.marrow/artifacts/ are checked too. Exits non-zero when anything is off.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+
+		var exps []model.Experiment
+		if len(args) == 0 {
+			if exps, err = s.ListExperiments(); err != nil {
+				return err
+			}
+		}
+		for _, id := range args {
+			exp, err := s.ReadExperiment(id)
+			if err != nil {
+				return fmt.Errorf("reading experiment %s: %w", id, err)
+			}
+			exps = append(exps, exp)
+		}
+
+		problems, checked, err := artifact.Verify(s, exps)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			fmt.Printf("%-8s %-13s %s", p.Experiment, p.Issue, p.Artifact.Path)
+			if p.Detail != "" {
+				fmt.Printf(" (%s)", p.Detail)
+			}
+			fmt.Println()
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%d of %d artifacts failed verification", len(problems), checked)
+		}
+		fmt.Printf("All %d artifacts match their recorded hashes\n", checked)
+		return nil
+	},
+}
+
+func init() {
+	expAttachCmd.Flags().BoolVar(&expAttachCopy, "copy", false, "Also keep a copy in .marrow/artifacts/ (files up to 10 MiB)")
+	expAttachCmd.Flags().BoolVar(&expAttachLink, "link", false, "Record the path only and leave the file in place (the default)")
+	expAttachCmd.Flags().StringVar(&expAttachKind, "kind", "", "checkpoint|predictions|plot|submission|file (default: guessed from the name)")
+	expAttachCmd.MarkFlagsMutuallyExclusive("copy", "link")
+
+	expCmd.AddCommand(expAttachCmd)
+	expCmd.AddCommand(expVerifyCmd)
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rzzdr/marrow/internal/artifact"
+	"github.com/rzzdr/marrow/internal/capture"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var expCheckoutWorktree string
+
+var expCheckoutCmd = &cobra.Command{
+	Use:   "checkout <id>",
+	Short: "Print the git commit an experiment ran at, or check it out in a worktree",
+	Long: `Print the commit recorded when the experiment was logged, or create a git
+worktree at it with --worktree. The current checkout is never touched.
+
+  marrow exp checkout exp_004                         # prints the commit
+  git diff $(marrow exp checkout exp_004)             # what changed since
+  marrow exp checkout exp_004 --worktree ../exp_004   # a detached worktree
+
+If the experiment had uncommitted changes and they were saved with
+"exp new --git-diff", the worktree gets them applied.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		id := args[0]
+		exp, err := s.ReadExperiment(id)
+		if err != nil {
+			return fmt.Errorf("reading experiment %s: %w", id, err)
+		}
+		if exp.Environment == nil || exp.Environment.GitCommit == "" {
+			return fmt.Errorf("%s has no git commit recorded", id)
+		}
+		commit := exp.Environment.GitCommit
+
+		var diff *model.Artifact
+		for i, a := range exp.Artifacts {
+			if a.Kind == "diff" {
+				diff = &exp.Artifacts[i]
+			}
+		}
+
+		if expCheckoutWorktree == "" {
+			fmt.Println(commit)
+			switch {
+			case exp.Environment.GitDirty && diff != nil:
+				fmt.Fprintf(cmd.ErrOrStderr(), "note: %s also had uncommitted changes, saved in %s\n", id, diff.Path)
+			case exp.Environment.GitDirty:
+				fmt.Fprintf(cmd.ErrOrStderr(), "note: %s also had uncommitted changes that were not saved\n", id)
+			}
+			return nil
+		}
+
+		worktree, err := filepath.Abs(expCheckoutWorktree)
+		if err != nil {
+			return err
+		}
+		var patch string
+		if diff != nil {
+			// Check the saved diff before creating anything.
+			patch = artifact.Resolve(s, *diff)
+			_, sum, err := artifact.Hash(patch)
+			if err != nil {
+				return fmt.Errorf("reading uncommitted changes of %s: %w", id, err)
+			}
+			if sum != diff.SHA256 {
+				return fmt.Errorf("%s was modified since %s was logged; not applying it", diff.Path, id)
+			}
+		}
+
+		projectDir := filepath.Dir(s.Root())
+		if err := capture.AddWorktree(projectDir, worktree, commit); err != nil {
+			return err
+		}
+		fmt.Printf("Created worktree %s at %s (%s)\n", expCheckoutWorktree, shortCommit(commit), id)
+		switch {
+		case diff != nil:
+			if err := capture.ApplyPatch(worktree, patch); err != nil {
+				if rmErr := capture.RemoveWorktree(projectDir, worktree); rmErr != nil {
+					return fmt.Errorf("applying uncommitted changes from %s: %w (the worktree %s is left with the committed code only)", diff.Path, err, expCheckoutWorktree)
+				}
+				return fmt.Errorf("applying uncommitted changes from %s: %w (the worktree was removed)", diff.Path, err)
+			}
+			fmt.Printf("Applied uncommitted changes from %s\n", diff.Path)
+		case exp.Environment.GitDirty:
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s had uncommitted changes that were not saved; the worktree has the committed code only\n", id)
+		}
+		return nil
+	},
+}
+
+func shortCommit(commit string) string {
+	return commit[:min(7, len(commit))]
+}
+
+func init() {
+	expCheckoutCmd.Flags().StringVar(&expCheckoutWorktree, "worktree", "", "Create a detached git worktree at this path")
+	expCmd.AddCommand(expCheckoutCmd)
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rzzdr/marrow/internal/curve"
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expCurveMetric    string
+	expCurveAppend    []string
+	expCurveDirection string
+	expCurvePoints    int
+)
+
+var expCurveCmd = &cobra.Command{
+	Use:   "curve <id>",
+	Short: "Show or extend an experiment's per-step metric curve",
+	Long: `Show or extend the per-step series of a metric, stored in
+.marrow/experiments/<id>/curves/<metric>.csv.
+
+  marrow exp curve exp_004 --append 100,0.81 --append 200,0.84
+  marrow exp curve exp_004 --metric val_loss --direction lower_is_better --append 100,0.42
+  marrow exp curve exp_004 --points 20
+
+Appending a step that is already there replaces its value. Each change
+refreshes the curve's stats on the experiment: best and final step, the gap
+between them, and how it ended: plateaued (every point within 1% of the
+curve's range of its best over at least the last quarter of its steps) or
+declining (the final value more than 10% of the range behind the best).
+Declared metrics use their own direction; --direction sets it for other
+series.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		if len(expCurveAppend) > 0 {
+			return appendCurve(cmd, s, args[0])
+		}
+
+		proj, err := s.ReadProject()
+		if err != nil {
+			return fmt.Errorf("reading project: %w", err)
+		}
+		exp, err := s.ReadExperiment(args[0])
+		if err != nil {
+			return fmt.Errorf("reading experiment %s: %w", args[0], err)
+		}
+		name := expCurveMetric
+		if name == "" {
+			name = proj.PrimaryMetric().Name
+		}
+		points, st, err := curve.Load(s, proj, exp, name)
+		if err != nil {
+			return err
+		}
+		fmt.Print(format.CurveText(exp.ID, name, st, curve.Downsample(points, expCurvePoints, st.BestStep)))
+		return nil
+	},
+}
+
+func appendCurve(cmd *cobra.Command, s *store.Store, id string) error {
+	added := make([]model.CurvePoint, 0, len(expCurveAppend))
+	for _, raw := range expCurveAppend {
+		p, err := curve.ParsePoint(raw)
+		if err != nil {
+			return err
+		}
+		added = append(added, p)
+	}
+	if expCurveDirection != "" {
+		if err := (model.MetricDef{Direction: expCurveDirection}).Validate(); err != nil {
+			return err
+		}
+	}
+
+	var name string
+	var st model.CurveStats
+	err := s.WithLock(func(s *store.Store) error {
+		proj, err := s.ReadProject()
+		if err != nil {
+			return fmt.Errorf("reading project: %w", err)
+		}
+		exp, err := s.ReadExperiment(id)
+		if err != nil {
+			return fmt.Errorf("reading experiment %s: %w", id, err)
+		}
+		name = expCurveMetric
+		if name == "" {
+			name = proj.PrimaryMetric().Name
+		}
+
+		higher := curve.HigherIsBetter(proj, exp, name)
+		if expCurveDirection != "" {
+			if m, ok := proj.FindMetric(name); ok && m.Direction != expCurveDirection {
+				return fmt.Errorf("metric %q is declared %s in marrow.yaml", name, m.Direction)
+			}
+			higher = expCurveDirection == "higher_is_better"
+		}
+
+		existing, err := s.ReadCurve(id, name)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := curve.Record(s, &exp, name, curve.Merge(existing, added...), higher); err != nil {
+			return err
+		}
+		if err := s.WriteExperiment(exp); err != nil {
+			return err
+		}
+		st = exp.Curves[name]
+
+		if err := s.AppendChangelog(model.ChangelogEntry{
+			Action:  "curve_appended",
+			ID:      id,
+			Type:    name,
+			Summary: fmt.Sprintf("added %d points to the %s curve of %s", len(added), name, id),
+		}); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added %d points to the %s curve of %s (%d points, best %.4f at step %d)\n", len(added), name, id, st.Points, st.Best, st.BestStep)
+	return nil
+}
+
+func init() {
+	expCurveCmd.Flags().StringVar(&expCurveMetric, "metric", "", "Metric the curve tracks (default: the primary metric)")
+	expCurveCmd.Flags().StringArrayVar(&expCurveAppend, "append", nil, "Point to add as step,value, repeatable")
+	expCurveCmd.Flags().StringVar(&expCurveDirection, "direction", "", "higher_is_better|lower_is_better, for metrics not declared in marrow.yaml")
+	expCurveCmd.Flags().IntVar(&expCurvePoints, "points", 50, "Show at most N points, spread over the curve")
+
+	expCmd.AddCommand(expCurveCmd)
+}
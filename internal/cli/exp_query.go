@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/query"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expQuerySort  string
+	expQueryLimit int
+	expQueryDepth string
+)
+
+var expQueryCmd = &cobra.Command{
+	Use:   "query <expression>",
+	Short: "Find experiments with a query expression",
+	Long: `Filter experiments with field comparisons, boolean logic and text search.
+
+  marrow exp query 'model=xgboost and metric>0.85 and tag:feature_eng and since:2026-09-01'
+  marrow exp query '(status=improved or status=neutral) and not leak' --sort best --limit 5
+  marrow exp query 'public_lb>=0.8 and python~3.11 and pkg.torch=2.3.0'
+
+Fields: id, model, status, notes, reasoning, reasoning_type, tag, parent,
+metric (primary), delta, baseline, local_cv, public_lb, data_version,
+timestamp, python, gpu, data_hash, preprocessing_hash, split_seed, git_commit,
+git_branch, pkg.<name> and any secondary metric by name.
+
+Operators: = != < <= > >= and ~ (substring). Shorthands: tag:x, since:DATE,
+until:DATE, text:x. Dates are YYYY-MM-DD or RFC 3339 (since:2026-10-17T09:00:00Z).
+Unknown field names are an error. Bare words and quoted strings search notes
+and reasoning. Adjacent terms are ANDed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+
+		q, err := query.Parse(args[0])
+		if err != nil {
+			return err
+		}
+
+		proj, err := s.ReadProject()
+		if err != nil {
+			return err
+		}
+
+		exps, err := s.ListExperiments()
+		if err != nil {
+			return err
+		}
+
+		if err := q.Validate(proj, exps); err != nil {
+			return err
+		}
+		exps = q.Filter(exps, proj)
+		if err := query.Sort(exps, expQuerySort, proj); err != nil {
+			return err
+		}
+
+		if len(exps) == 0 {
+			fmt.Println("No experiments match.")
+			return nil
+		}
+
+		exps = query.Limit(exps, expQueryLimit, expQuerySort != "")
+
+		depth := model.ParseDepth(expQueryDepth)
+		for _, e := range exps {
+			if depth == model.DepthSummary {
+				fmt.Println(format.ExperimentOneLiner(e))
+				continue
+			}
+			data, err := format.MarshalYAMLString(format.FilterExperiment(e, depth))
+			if err != nil {
+				return err
+			}
+			fmt.Print(data)
+			fmt.Println("---")
+		}
+		return nil
+	},
+}
+
+func init() {
+	expQueryCmd.Flags().StringVar(&expQuerySort, "sort", "", "Sort by a field (prefix - for descending, or 'best')")
+	expQueryCmd.Flags().IntVar(&expQueryLimit, "limit", 0, "Show at most N experiments (first N when sorted, else most recent)")
+	expQueryCmd.Flags().StringVar(&expQueryDepth, "depth", "summary", "summary|standard|full")
+
+	expCmd.AddCommand(expQueryCmd)
+}
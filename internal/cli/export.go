@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the knowledge base to other formats",
+}
+
+var (
+	exportMarkdownOut    string
+	exportMarkdownBudget int
+	exportMarkdownSync   []string
+)
+
+var exportMarkdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Write a Markdown digest of the project",
+	Long: `Write a Markdown digest with an overview, the best result and its chain,
+proven learnings, open assumptions, the graveyard, do-not-try items and data
+warnings.
+
+--budget caps the digest at roughly that many tokens. Items are dropped from
+the least critical sections first (assumptions, then the graveyard, proven
+learnings and the chain), and each trimmed section says how many items were
+left out.
+
+--sync keeps a marked section of another file, such as CLAUDE.md or
+AGENTS.md, up to date. The digest goes between
+  <!-- marrow:begin -->
+  <!-- marrow:end -->
+and the rest of the file is left alone. Files without the markers get the
+section appended, and missing files are created.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		proj, err := s.ReadProject()
+		if err != nil {
+			return fmt.Errorf("reading project: %w", err)
+		}
+		idx, err := s.ReadIndex()
+		if err != nil {
+			return fmt.Errorf("reading index: %w", err)
+		}
+		learnings, err := s.ReadLearnings()
+		if err != nil {
+			return fmt.Errorf("reading learnings: %w", err)
+		}
+		graveyard, err := s.ReadGraveyard()
+		if err != nil {
+			return fmt.Errorf("reading graveyard: %w", err)
+		}
+
+		d := format.Digest{Project: proj, Index: idx, Learnings: learnings, Graveyard: graveyard}
+		for _, id := range idx.Computed.ExperimentChain {
+			exp, err := s.ReadExperiment(id)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping %s from the chain: %v\n", id, err)
+				continue
+			}
+			d.Chain = append(d.Chain, exp)
+		}
+
+		for _, path := range exportMarkdownSync {
+			if err := syncMarkdown(path, d); err != nil {
+				return err
+			}
+			fmt.Printf("Synced %s\n", path)
+		}
+
+		// With only --sync, the synced files are the output.
+		if exportMarkdownOut == "" && len(exportMarkdownSync) > 0 {
+			return nil
+		}
+		doc := format.Markdown(d, format.MarkdownOptions{Budget: exportMarkdownBudget})
+		if exportMarkdownOut == "" {
+			fmt.Print(doc)
+			return nil
+		}
+		if err := format.WriteFileAtomic(exportMarkdownOut, []byte(doc)); err != nil {
+			return fmt.Errorf("writing %s: %w", exportMarkdownOut, err)
+		}
+		fmt.Printf("Wrote %s (~%d tokens)\n", exportMarkdownOut, format.EstimateTokens(doc))
+		return nil
+	},
+}
+
+// syncMarkdown rewrites the marked section of path. The digest's headings
+// start at level 2 so it nests under the file's own title.
+func syncMarkdown(path string, d format.Digest) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	section := format.Markdown(d, format.MarkdownOptions{Budget: exportMarkdownBudget, Level: 2})
+	updated, err := format.SyncMarkedSection(string(existing), section)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if updated == string(existing) {
+		return nil
+	}
+	if err := format.WriteFileAtomic(path, []byte(updated)); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	exportMarkdownCmd.Flags().StringVar(&exportMarkdownOut, "out", "", "Output file (default: stdout)")
+	exportMarkdownCmd.Flags().IntVar(&exportMarkdownBudget, "budget", 0, "Approximate token limit (0 = no limit)")
+	exportMarkdownCmd.Flags().StringArrayVar(&exportMarkdownSync, "sync", nil, "Keep the marked section of this file (e.g. CLAUDE.md) in sync; repeatable")
+
+	exportCmd.AddCommand(exportMarkdownCmd)
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/graph"
+	"github.com/rzzdr/marrow/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat    string
+	graphTags      string
+	graphRoot      string
+	graphAncestors string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the experiment DAG as DOT, Mermaid or JSON",
+	Long: `Print the experiment DAG with one node per experiment, labelled with its
+primary metric value and status, and one edge per parent link. The winning
+chain is highlighted and the best experiment is filled.
+
+Filters combine: --tags keeps experiments with any of the tags, --root keeps
+an experiment and its descendants, --ancestors keeps an experiment and its
+ancestors.
+
+Examples:
+  marrow graph --format mermaid > lineage.md
+  marrow graph --root exp_004 | dot -Tsvg > branch.svg
+  marrow graph --ancestors exp_012 --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		proj, err := s.ReadProject()
+		if err != nil {
+			return err
+		}
+		exps, err := s.ListExperiments()
+		if err != nil {
+			return err
+		}
+		idx, err := s.ReadIndex()
+		if err != nil {
+			return err
+		}
+
+		g, err := graph.Build(exps, idx.Computed.ExperimentChain, proj, graph.Filter{
+			Tags:      util.SplitTags(graphTags),
+			Root:      graphRoot,
+			Ancestors: graphAncestors,
+		})
+		if err != nil {
+			return err
+		}
+		out, err := format.RenderGraph(g, graphFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+		return nil
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "dot|mermaid|json")
+	graphCmd.Flags().StringVar(&graphTags, "tags", "", "Only experiments with any of these comma-separated tags")
+	graphCmd.Flags().StringVar(&graphRoot, "root", "", "Only this experiment and its descendants")
+	graphCmd.Flags().StringVar(&graphAncestors, "ancestors", "", "Only this experiment and its ancestors")
+}
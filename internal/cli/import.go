@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/importer"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import runs from other experiment trackers",
+	Long: `Import runs from other experiment trackers as experiments. Imported
+experiments remember the run they came from, so importing the same runs again
+only adds the new ones.`,
+}
+
+var (
+	importMetric     string
+	importTags       string
+	importExperiment string
+	importBest       bool
+)
+
+var importMLflowCmd = &cobra.Command{
+	Use:   "mlflow <path>",
+	Short: "Import runs from an MLflow file store (mlruns/)",
+	Long: `Import runs from an MLflow file store. <path> is the mlruns directory or
+a directory containing one.
+
+Each run becomes an experiment with the latest value of --metric as its
+primary metric, its params, and its tags (key=value, or just key when the
+value is empty or "true"). Declared secondary metrics are filled from run
+metrics of the same name. mlflow.parentRunId becomes the parent and
+mlflow.note.content the notes. Failed and killed runs are imported as
+failed; other runs are improved, degraded or neutral against their parent.
+Deleted runs are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		runs, warnings, err := importer.ReadMLflow(args[0], importExperiment)
+		if err != nil {
+			return err
+		}
+		return runImport(cmd, s, "mlflow", runs, warnings, importer.Options{})
+	},
+}
+
+var importWandbCmd = &cobra.Command{
+	Use:   "wandb <dir>",
+	Short: "Import runs from W&B run directories (wandb/offline-run-*)",
+	Long: `Import runs from W&B run directories, such as those written in offline
+mode. <dir> is a single run directory, a wandb directory, or a directory
+containing one.
+
+Each run becomes an experiment with --metric from wandb-summary.json as its
+primary metric and its config.yaml as params, nested keys joined with dots.
+Declared secondary metrics are filled from summary metrics of the same name.
+wandb-metadata.json fills the environment: python version, GPU and git
+commit. W&B does not link runs, so give a run a parent by setting
+marrow_parent in its config to a W&B run ID or an experiment ID; its param
+changes from the parent are then recorded. Runs are improved, degraded or
+neutral against their parent, and neutral without one.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		runs, warnings, err := importer.ReadWandb(args[0])
+		if err != nil {
+			return err
+		}
+		return runImport(cmd, s, "wandb", runs, warnings, importer.Options{})
+	},
+}
+
+var importTensorBoardCmd = &cobra.Command{
+	Use:   "tensorboard <logdir>",
+	Short: "Import runs from TensorBoard event files",
+	Long: `Import runs from TensorBoard event files. Every directory under <logdir>
+holding events.out.tfevents.* files is a run, named by its path relative to
+<logdir>. Event files are read directly; TensorFlow is not needed.
+
+--metric names the scalar tag holding the primary metric, e.g. eval/reward.
+The experiment takes its value at the last step, or its best value with
+--best, and notes both. Declared secondary metrics are filled from scalar
+tags of the same name. The full curve of each is stored with the
+experiment under .marrow/experiments/<id>/curves/ and listed in its
+artifacts.
+
+Runs are imported as neutral. Importing again updates the runs imported
+before, so a run that is still training can be imported as it goes.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		runs, warnings, err := importer.ReadTensorBoard(args[0])
+		if err != nil {
+			return err
+		}
+		return runImport(cmd, s, "tensorboard", runs, warnings, importer.Options{Best: importBest, Update: true})
+	},
+}
+
+// runImport writes runs read from tool and reports what happened. opts
+// gets --metric and --tags.
+func runImport(cmd *cobra.Command, s *store.Store, tool string, runs []importer.Run, readWarnings []string, opts importer.Options) error {
+	for _, w := range readWarnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No %s runs found.\n", tool)
+		return nil
+	}
+
+	opts.Metric = importMetric
+	opts.Tags = util.SplitTags(importTags)
+	res, err := importer.Import(s, tool, runs, opts)
+	if err != nil {
+		return err
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+	}
+	for _, im := range res.Imported {
+		fmt.Printf("  %s ← %s\n", im.Experiment, im.RunID)
+	}
+	for _, im := range res.Updated {
+		fmt.Printf("  %s ↻ %s\n", im.Experiment, im.RunID)
+	}
+	var counts []string
+	if len(res.Updated) > 0 {
+		counts = append(counts, fmt.Sprintf("%d updated", len(res.Updated)))
+	}
+	if res.Skipped > 0 && opts.Update {
+		counts = append(counts, fmt.Sprintf("%d unchanged", res.Skipped))
+	} else if res.Skipped > 0 {
+		counts = append(counts, fmt.Sprintf("%d already imported", res.Skipped))
+	}
+	fmt.Printf("Imported %d %s runs", len(res.Imported), tool)
+	if len(counts) > 0 {
+		fmt.Printf(" (%s)", strings.Join(counts, ", "))
+	}
+	fmt.Println()
+	return nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{importMLflowCmd, importWandbCmd, importTensorBoardCmd} {
+		c.Flags().StringVar(&importMetric, "metric", "", "Run metric to use as the primary metric (default: the primary metric's name)")
+		c.Flags().StringVar(&importTags, "tags", "", "Comma-separated tags to add to every imported experiment")
+	}
+	importMLflowCmd.Flags().StringVar(&importExperiment, "experiment", "", "Only import this MLflow experiment (name or ID)")
+	importTensorBoardCmd.Flags().BoolVar(&importBest, "best", false, "Use the best value of the scalar rather than its value at the last step")
+
+	importCmd.AddCommand(importMLflowCmd)
+	importCmd.AddCommand(importWandbCmd)
+	importCmd.AddCommand(importTensorBoardCmd)
+}
@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/rzzdr/marrow/internal/format"
 	"github.com/rzzdr/marrow/internal/index"
 	"github.com/rzzdr/marrow/internal/model"
 	"github.com/spf13/cobra"
@@ -37,6 +40,9 @@ var indexRebuildCmd = &cobra.Command{
 		fmt.Printf("Index rebuilt: %d experiments, best: %s\n",
 			idx.Computed.TotalExperiments,
 			idx.Computed.BestExperiment)
+		if n := len(idx.Computed.DataGroups); n > 0 {
+			fmt.Printf("Experiments span %d data setups; see 'marrow index show'.\n", n)
+		}
 		return nil
 	},
 }
@@ -59,6 +65,53 @@ var indexShowCmd = &cobra.Command{
 	},
 }
 
+var indexParetoCmd = &cobra.Command{
+	Use:   "pareto",
+	Short: "Show the Pareto front across all declared metrics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+
+		proj, err := s.ReadProject()
+		if err != nil {
+			return fmt.Errorf("reading project: %w", err)
+		}
+		if len(proj.SecondaryMetrics()) == 0 {
+			fmt.Println("Only one metric is declared; the Pareto front is just the best experiment.")
+			fmt.Println("Declare more under metrics in .marrow/marrow.yaml.")
+			return nil
+		}
+
+		idx, err := s.ReadIndex()
+		if err != nil {
+			return fmt.Errorf("reading index: %w", err)
+		}
+
+		front := idx.Computed.ParetoFront
+		if len(front) == 0 {
+			fmt.Println("Pareto front is empty (no non-failed experiments record every metric).")
+			return nil
+		}
+
+		names := make([]string, 0, len(proj.AllMetrics()))
+		for _, m := range proj.AllMetrics() {
+			names = append(names, m.Name)
+		}
+		fmt.Printf("Pareto front over %s:\n", strings.Join(names, ", "))
+		for _, id := range front {
+			exp, err := s.ReadExperiment(id)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: reading %s: %v\n", id, err)
+				continue
+			}
+			fmt.Println("  " + format.ExperimentOneLiner(exp))
+		}
+		return nil
+	},
+}
+
 func printIndex(idx model.Index) {
 	c := idx.Computed
 	fmt.Printf("Last updated:      %s\n", c.LastUpdated.Format("2006-01-02 15:04:05"))
@@ -72,6 +125,20 @@ func printIndex(idx model.Index) {
 	if len(c.ExperimentChain) > 0 {
 		fmt.Printf("Experiment chain:  %v\n", c.ExperimentChain)
 	}
+	if len(c.ParetoFront) > 0 {
+		fmt.Printf("Pareto front:      %v\n", c.ParetoFront)
+	}
+	if len(c.BestByMetric) > 0 {
+		names := make([]string, 0, len(c.BestByMetric))
+		for name := range c.BestByMetric {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Println("Best by metric:")
+		for _, name := range names {
+			fmt.Printf("  %-16s %s\n", name, c.BestByMetric[name])
+		}
+	}
 	fmt.Printf("Proven learnings:  %d\n", c.ProvenCount)
 	fmt.Printf("Assumptions:       %d\n", c.AssumptionCount)
 	fmt.Printf("Graveyard entries: %d\n", c.GraveyardCount)
@@ -83,6 +150,13 @@ func printIndex(idx model.Index) {
 			fmt.Println("  ✗ " + d)
 		}
 	}
+	if len(c.DataGroups) > 0 {
+		fmt.Println("\n── Data Drift ──")
+		fmt.Println("  Experiments ran on different data; compare scores only within a group.")
+		for _, g := range c.DataGroups {
+			fmt.Printf("  %s: %s\n", g.Label(), strings.Join(g.Experiments, ", "))
+		}
+	}
 	if len(p.DataWarnings) > 0 {
 		fmt.Println("\n── Data Warnings ──")
 		for _, w := range p.DataWarnings {
@@ -94,4 +168,5 @@ func printIndex(idx model.Index) {
 func init() {
 	indexCmd.AddCommand(indexRebuildCmd)
 	indexCmd.AddCommand(indexShowCmd)
+	indexCmd.AddCommand(indexParetoCmd)
 }
@@ -77,7 +77,7 @@ var initCmd = &cobra.Command{
 		}
 
 		gitignorePath := filepath.Join(s.Root(), ".gitignore")
-		if err := os.WriteFile(gitignorePath, []byte("snapshots/\n.marrow-tmp-*\n"), 0644); err != nil {
+		if err := os.WriteFile(gitignorePath, []byte("snapshots/\n.marrow-tmp-*\n.lock\n"), 0644); err != nil {
 			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to create .gitignore: %v\n", err)
 		}
 
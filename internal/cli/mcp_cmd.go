@@ -1,18 +1,81 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	mcpserver "github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/util"
 	"github.com/spf13/cobra"
 )
 
+var (
+	mcpHTTPAddr string
+	mcpReadOnly bool
+	mcpTools    string
+)
+
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "Start the MCP server for AI agent integration",
+	Long: `Start the MCP server. By default it speaks stdio, one process per agent.
+
+With --http, one long-lived server serves many clients: streamable HTTP at
+/mcp and SSE at /sse. Set MARROW_MCP_TOKEN to require
+"Authorization: Bearer <token>" on every request.
+
+--read-only and --tools restrict what agents may call. Defaults can be set
+per project in .marrow/marrow.yaml:
+
+  mcp:
+    read_only: false
+    tools: [get_*, query_experiments, log_experiment]`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		s, err := getStoreFromRoot()
 		if err != nil {
 			return err
 		}
-		return mcpserver.Serve(s)
+
+		// The server can run without a readable marrow.yaml; agents see
+		// the error from get_project_summary instead.
+		proj, err := s.ReadProject()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: reading project config: %v\n", err)
+		}
+
+		opts := mcpserver.ServeOptions{Token: os.Getenv("MARROW_MCP_TOKEN")}
+		if proj.MCP != nil {
+			opts.ReadOnly = proj.MCP.ReadOnly
+			opts.Tools = proj.MCP.Tools
+		}
+		if cmd.Flags().Changed("read-only") {
+			opts.ReadOnly = mcpReadOnly
+		}
+		if cmd.Flags().Changed("tools") {
+			opts.Tools = util.SplitTags(mcpTools)
+		}
+
+		if mcpHTTPAddr == "" {
+			return mcpserver.Serve(s, opts)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Serving MCP on %s (streamable HTTP at %s, SSE at %s)\n",
+			mcpHTTPAddr, mcpserver.HTTPPath, mcpserver.SSEPath)
+		if opts.Token == "" {
+			fmt.Fprintln(cmd.ErrOrStderr(), "warning: MARROW_MCP_TOKEN is not set; any client that can reach this address has access")
+		}
+		return mcpserver.ServeHTTP(ctx, s, opts, mcpHTTPAddr)
 	},
 }
+
+func init() {
+	mcpCmd.Flags().StringVar(&mcpHTTPAddr, "http", "", "Serve over HTTP and SSE on this address (e.g. :8765) instead of stdio")
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "Disable the tools that modify .marrow/")
+	mcpCmd.Flags().StringVar(&mcpTools, "tools", "", "Comma-separated allowlist of tool names or globs (e.g. get_*,log_experiment)")
+}
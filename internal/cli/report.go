@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rzzdr/marrow/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportOut       string
+	reportChangelog int
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Render a self-contained HTML report",
+	Long: `Write an HTML dashboard to <out>/index.html: the project summary, the metric
+over time and by tag, the best chain, the experiment DAG, learnings, the
+graveyard, pinned guardrails and recent changes.
+
+The page has no scripts and loads nothing from the network, so it can be
+published as a CI artifact or opened straight from disk.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		path, err := report.Write(s, reportOut, report.Options{Changelog: reportChangelog})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Report written to %s\n", path)
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportOut, "out", "report", "Output directory")
+	reportCmd.Flags().IntVar(&reportChangelog, "changelog", report.DefaultChangelog, "Recent changelog entries to include")
+}
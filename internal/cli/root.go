@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/rzzdr/marrow/internal/store"
 	"github.com/spf13/cobra"
@@ -43,7 +44,15 @@ func getStoreFromRoot() (*store.Store, error) {
 	if err != nil {
 		return nil, err
 	}
-	return store.New(root), nil
+	s := store.New(root)
+	if v := os.Getenv("MARROW_LOCK_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MARROW_LOCK_TIMEOUT %q: %w", v, err)
+		}
+		s.SetLockTimeout(d)
+	}
+	return s, nil
 }
 
 func init() {
@@ -52,6 +61,12 @@ func init() {
 	rootCmd.AddCommand(learnCmd)
 	rootCmd.AddCommand(ctxCmd)
 	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(graphCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(tuiCmd)
 	rootCmd.AddCommand(summaryCmd)
 	rootCmd.AddCommand(snapshotCmd)
 	rootCmd.AddCommand(mcpCmd)
@@ -2,14 +2,9 @@ package cli
 
 import (
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"strings"
-	"time"
 
+	"github.com/rzzdr/marrow/internal/format"
 	"github.com/rzzdr/marrow/internal/model"
-	"github.com/rzzdr/marrow/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -29,18 +24,9 @@ var snapshotCreateCmd = &cobra.Command{
 			return err
 		}
 
-		src := s.Root()
-		if err := util.SafeName(snapshotName); err != nil {
-			return fmt.Errorf("invalid snapshot name: %w", err)
-		}
-		fullName := time.Now().UTC().Format("20060102T150405") + "_" + snapshotName
-		dst := filepath.Join(src, "snapshots", fullName)
-		if _, err := os.Stat(dst); err == nil {
-			return fmt.Errorf("snapshot %q already exists", fullName)
-		}
-
-		if err := copyDir(src, dst); err != nil {
-			return fmt.Errorf("creating snapshot: %w", err)
+		fullName, err := s.CreateSnapshot(snapshotName)
+		if err != nil {
+			return err
 		}
 
 		if err := s.AppendChangelog(model.ChangelogEntry{
@@ -63,77 +49,106 @@ var snapshotListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		dir := filepath.Join(s.Root(), "snapshots")
 
-		entries, err := os.ReadDir(dir)
+		names, err := s.ListSnapshots()
 		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Println("No snapshots.")
-				return nil
-			}
 			return err
 		}
+		if len(names) == 0 {
+			fmt.Println("No snapshots.")
+			return nil
+		}
 
-		for _, e := range entries {
-			if e.IsDir() {
-				fmt.Println("  " + e.Name())
-			}
+		for _, n := range names {
+			fmt.Println("  " + n)
 		}
 		return nil
 	},
 }
 
-func init() {
-	snapshotCreateCmd.Flags().StringVar(&snapshotName, "name", "", "Snapshot name (required)")
-	_ = snapshotCreateCmd.MarkFlagRequired("name")
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [name]",
+	Short: "Restore .marrow/ to a snapshot (the current state is snapshotted first)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
 
-	snapshotCmd.AddCommand(snapshotCreateCmd)
-	snapshotCmd.AddCommand(snapshotListCmd)
+		fullName, err := s.ResolveSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+
+		backup, err := s.RestoreSnapshot(fullName)
+		if err != nil {
+			return err
+		}
+
+		if err := s.LogRestore(fullName, backup); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
+		}
+
+		fmt.Printf("Restored snapshot %s\n", fullName)
+		fmt.Printf("  previous state saved as %s\n", backup)
+		return nil
+	},
 }
 
-func copyDir(src, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff [name]",
+	Short: "Show what changed since a snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
 		if err != nil {
 			return err
 		}
 
-		rel, _ := filepath.Rel(src, path)
-		if rel == "snapshots" || strings.HasPrefix(rel, "snapshots"+string(filepath.Separator)) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+		diff, err := s.DiffSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Print(format.SnapshotDiffText(diff))
+		return nil
+	},
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+
+		fullName, err := s.DeleteSnapshot(args[0])
+		if err != nil {
+			return err
 		}
 
-		target := filepath.Join(dst, rel)
-		if info.IsDir() {
-			return os.MkdirAll(target, info.Mode())
+		if err := s.AppendChangelog(model.ChangelogEntry{
+			Action:  "snapshot_deleted",
+			Summary: fullName,
+		}); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to append changelog: %v\n", err)
 		}
 
-		return copyFile(path, target)
-	})
+		fmt.Printf("Deleted snapshot %s\n", fullName)
+		return nil
+	},
 }
 
-func copyFile(src, dst string) error {
-	info, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer in.Close()
-
-	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
-	if err != nil {
-		return err
-	}
-
-	if _, err = io.Copy(out, in); err != nil {
-		out.Close()
-		return err
-	}
-	return out.Close()
+func init() {
+	snapshotCreateCmd.Flags().StringVar(&snapshotName, "name", "", "Snapshot name (required)")
+	_ = snapshotCreateCmd.MarkFlagRequired("name")
+
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
 }
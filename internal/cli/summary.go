@@ -30,7 +30,11 @@ var summaryCmd = &cobra.Command{
 			fmt.Printf("  %s\n", proj.Description)
 		}
 		fmt.Printf("Task:    %s\n", proj.TaskType)
-		fmt.Printf("Metric:  %s (%s)\n", proj.Metric.Name, proj.Metric.Direction)
+		metric := proj.PrimaryMetric()
+		fmt.Printf("Metric:  %s (%s)\n", metric.Name, metric.Direction)
+		for _, m := range proj.SecondaryMetrics() {
+			fmt.Printf("         %s (%s)\n", m.Name, m.Direction)
+		}
 		fmt.Println()
 		printIndex(idx)
 		return nil
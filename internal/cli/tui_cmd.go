@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"github.com/rzzdr/marrow/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse the experiment DAG interactively",
+	Long: `Browse experiments as a tree, with the winning chain starred, the selected
+experiment's full record, and side panels for learnings, graveyard and pinned
+items.
+
+Keys:
+  ↑/↓ j/k       move            b      jump to best
+  PgUp/PgDn g/G page, top/bottom  [ ]    scroll details
+  s             set status      e      edit tags
+  t             filter by tag   f      cycle status filter
+  c             clear filters   tab    switch side panel
+  r             reload          q      quit`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStoreFromRoot()
+		if err != nil {
+			return err
+		}
+		return tui.Run(s)
+	},
+}
@@ -0,0 +1,229 @@
+// Package curve handles per-step metric series: merging points, deriving
+// stats such as the best step and plateaus, downsampling for display, and
+// storing a series with its experiment.
+package curve
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+// PlateauTolerance is the share of a curve's range a plateaued curve may
+// stray from its best.
+const PlateauTolerance = 0.01
+
+// DeclineTolerance is the share of a curve's range the final value must be
+// behind the best for the curve to count as declining.
+const DeclineTolerance = 0.1
+
+// minPlateauPoints is the shortest curve that can be said to plateau.
+const minPlateauPoints = 5
+
+// ParsePoint parses "step,value".
+func ParsePoint(s string) (model.CurvePoint, error) {
+	stepStr, valueStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return model.CurvePoint{}, fmt.Errorf("invalid point %q: expected step,value", s)
+	}
+	step, err := strconv.ParseInt(strings.TrimSpace(stepStr), 10, 64)
+	if err != nil {
+		return model.CurvePoint{}, fmt.Errorf("invalid point %q: step must be an integer", s)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(valueStr), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return model.CurvePoint{}, fmt.Errorf("invalid point %q: value must be a finite number", s)
+	}
+	return model.CurvePoint{Step: step, Value: value}, nil
+}
+
+// Merge adds points to a series and returns it sorted by step. A step given
+// again takes the later value.
+func Merge(points []model.CurvePoint, added ...model.CurvePoint) []model.CurvePoint {
+	all := append(append([]model.CurvePoint(nil), points...), added...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Step < all[j].Step })
+	out := all[:0]
+	for _, p := range all {
+		if n := len(out); n > 0 && out[n-1].Step == p.Step {
+			out[n-1] = p
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// Best returns the best point of a non-empty series, the earliest on ties.
+func Best(points []model.CurvePoint, higher bool) model.CurvePoint {
+	best := points[0]
+	for _, p := range points[1:] {
+		if (higher && p.Value > best.Value) || (!higher && p.Value < best.Value) {
+			best = p
+		}
+	}
+	return best
+}
+
+// Stats derives the best and final points of a series sorted by step, the
+// gap between them, and how the curve ended. It plateaued when it came
+// within PlateauTolerance of the curve's range of its best and every later
+// point stayed there, for at least the last quarter of its steps. It is
+// declining when the final value is more than DeclineTolerance of the range
+// behind the best, as when a run overfits.
+func Stats(points []model.CurvePoint, higher bool) model.CurveStats {
+	st := model.CurveStats{Direction: "lower_is_better", Points: len(points)}
+	if higher {
+		st.Direction = "higher_is_better"
+	}
+	if len(points) == 0 {
+		return st
+	}
+	best, final := Best(points, higher), points[len(points)-1]
+	st.Best, st.BestStep = best.Value, best.Step
+	st.Final, st.FinalStep = final.Value, final.Step
+	st.Gap = math.Abs(best.Value - final.Value)
+
+	lo, hi := points[0].Value, points[0].Value
+	for _, p := range points {
+		lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+	}
+	if st.Gap > DeclineTolerance*(hi-lo) {
+		st.Declining = true
+		return st
+	}
+
+	tol := PlateauTolerance * (hi - lo)
+	reached := -1
+	for i, p := range points {
+		near := math.Abs(best.Value-p.Value) <= tol
+		switch {
+		case near && reached < 0:
+			reached = i
+		case !near:
+			reached = -1 // left the best behind again
+		}
+	}
+	if reached < 0 {
+		return st
+	}
+	since := points[reached].Step
+	span := final.Step - points[0].Step
+	if len(points) >= minPlateauPoints && span > 0 && len(points)-1-reached >= 2 && 4*(final.Step-since) >= span {
+		st.Plateau, st.PlateauSince = true, since
+	}
+	return st
+}
+
+// Downsample picks at most n points spread evenly over the series, always
+// keeping the first and last points and the one at keepStep (the best).
+func Downsample(points []model.CurvePoint, n int, keepStep int64) []model.CurvePoint {
+	if n < 2 {
+		n = 2
+	}
+	if len(points) <= n {
+		return points
+	}
+	picked := make(map[int]bool, n)
+	for i := 0; i < n; i++ {
+		picked[int(math.Round(float64(i)*float64(len(points)-1)/float64(n-1)))] = true
+	}
+	keep := sort.Search(len(points), func(i int) bool { return points[i].Step >= keepStep })
+	if keep < len(points) && points[keep].Step == keepStep && !picked[keep] {
+		// Give up the nearest interior pick for the kept point.
+		nearest := -1
+		for i := range picked {
+			if i == 0 || i == len(points)-1 {
+				continue
+			}
+			if nearest < 0 || abs(i-keep) < abs(nearest-keep) {
+				nearest = i
+			}
+		}
+		if nearest >= 0 {
+			delete(picked, nearest)
+		}
+		picked[keep] = true
+	}
+
+	out := make([]model.CurvePoint, 0, len(picked))
+	for i, p := range points {
+		if picked[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Record stores points as the named curve of exp, replacing any earlier
+// one, and refreshes its artifact and stats. The caller writes exp.
+func Record(s *store.Store, exp *model.Experiment, name string, points []model.CurvePoint, higher bool) error {
+	a, err := s.WriteCurve(exp.ID, name, points)
+	if err != nil {
+		return err
+	}
+	artifacts := make([]model.Artifact, 0, len(exp.Artifacts)+1)
+	for _, old := range exp.Artifacts {
+		if old.Path != a.Path {
+			artifacts = append(artifacts, old)
+		}
+	}
+	exp.Artifacts = append(artifacts, a)
+
+	curves := make(map[string]model.CurveStats, len(exp.Curves)+1)
+	for k, v := range exp.Curves {
+		curves[k] = v
+	}
+	curves[name] = Stats(points, higher)
+	exp.Curves = curves
+	return nil
+}
+
+// HigherIsBetter returns the direction of an experiment's curve: the
+// declared metric's when name is declared, else the one its stats were
+// computed with, else higher.
+func HigherIsBetter(proj model.Project, exp model.Experiment, name string) bool {
+	if m, ok := proj.FindMetric(name); ok {
+		return m.HigherIsBetter()
+	}
+	if st, ok := exp.Curves[name]; ok {
+		return st.HigherIsBetter()
+	}
+	return true
+}
+
+// Load reads the named curve of exp and derives its stats.
+func Load(s *store.Store, proj model.Project, exp model.Experiment, name string) ([]model.CurvePoint, model.CurveStats, error) {
+	points, err := s.ReadCurve(exp.ID, name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, model.CurveStats{}, notFound(exp, name)
+		}
+		return nil, model.CurveStats{}, err
+	}
+	return points, Stats(points, HigherIsBetter(proj, exp, name)), nil
+}
+
+func notFound(exp model.Experiment, name string) error {
+	if len(exp.Curves) == 0 {
+		return fmt.Errorf("%s has no %s curve", exp.ID, name)
+	}
+	names := make([]string, 0, len(exp.Curves))
+	for n := range exp.Curves {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%s has no %s curve; it has %s", exp.ID, name, strings.Join(names, ", "))
+}
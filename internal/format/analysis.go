@@ -0,0 +1,66 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/analysis"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// FilterParamReport trims a parameter report to depth: summary keeps the
+// per-parameter scores, standard adds the per-value metric distribution and
+// full adds every parent → child change.
+func FilterParamReport(r analysis.ParamReport, depth model.Depth) analysis.ParamReport {
+	if depth == model.DepthFull {
+		return r
+	}
+	out := r
+	out.Params = make([]analysis.ParamStats, len(r.Params))
+	for i, p := range r.Params {
+		p.ChangeLog = nil
+		if depth == model.DepthSummary {
+			p.Values = nil
+		}
+		out.Params[i] = p
+	}
+	return out
+}
+
+// ParamReportText renders a parameter report as plain text at depth.
+func ParamReportText(r analysis.ParamReport, depth model.Depth) string {
+	if len(r.Params) == 0 {
+		return "No experiment records params or param changes.\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parameter importance for %s (%s) across %d experiments:\n", r.Metric, r.Direction, r.Experiments)
+	for _, p := range r.Params {
+		line := fmt.Sprintf("  %-24s %.2f  %d values, %d changes", p.Name, p.Importance, len(p.Values), p.Changes)
+		if p.MeanDelta != nil {
+			line += fmt.Sprintf(", mean Δ %+.4f", *p.MeanDelta)
+		}
+		b.WriteString(line + "\n")
+
+		if depth == model.DepthSummary {
+			continue
+		}
+		for _, v := range p.Values {
+			fmt.Fprintf(&b, "      %-16s n=%-3d mean %.4f  [%.4f, %.4f]  best %s\n", v.Value, v.Count, v.Mean, v.Min, v.Max, v.Best)
+		}
+		if depth != model.DepthFull {
+			continue
+		}
+		for _, c := range p.ChangeLog {
+			fmt.Fprintf(&b, "      %s ← %s: %s → %s  Δ %+.4f\n", c.Experiment, c.Parent, orDash(c.From), orDash(c.To), c.Delta)
+		}
+	}
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
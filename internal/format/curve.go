@@ -0,0 +1,47 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// CurveText describes a metric curve: its stats, then the given points
+// (usually downsampled) as "step,value" lines.
+func CurveText(id, name string, st model.CurveStats, points []model.CurvePoint) string {
+	var b strings.Builder
+	direction := "higher is better"
+	if !st.HigherIsBetter() {
+		direction = "lower is better"
+	}
+	fmt.Fprintf(&b, "%s %s: %d points, %s\n", id, name, st.Points, direction)
+	if st.Points == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "  best   %.4f at step %d\n", st.Best, st.BestStep)
+	fmt.Fprintf(&b, "  final  %.4f at step %d", st.Final, st.FinalStep)
+	if st.Gap > 0 {
+		fmt.Fprintf(&b, " (%.4f behind best)", st.Gap)
+	}
+	b.WriteString("\n")
+	switch {
+	case st.Declining:
+		fmt.Fprintf(&b, "  declining since best at step %d\n", st.BestStep)
+	case st.Plateau:
+		fmt.Fprintf(&b, "  plateaued since step %d\n", st.PlateauSince)
+	case st.BestStep == st.FinalStep:
+		b.WriteString("  still improving at the last step\n")
+	}
+
+	if len(points) < st.Points {
+		fmt.Fprintf(&b, "step,value (%d of %d points)\n", len(points), st.Points)
+	} else {
+		b.WriteString("step,value\n")
+	}
+	for _, p := range points {
+		fmt.Fprintf(&b, "%d,%s\n", p.Step, strconv.FormatFloat(p.Value, 'g', -1, 64))
+	}
+	return b.String()
+}
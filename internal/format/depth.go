@@ -8,10 +8,11 @@ func FilterExperiment(e model.Experiment, depth model.Depth) model.Experiment {
 	switch depth {
 	case model.DepthSummary:
 		return model.Experiment{
-			ID:     e.ID,
-			Status: e.Status,
-			Metric: e.Metric,
-			Tags:   e.Tags,
+			ID:      e.ID,
+			Status:  e.Status,
+			Metric:  e.Metric,
+			Metrics: e.Metrics,
+			Tags:    e.Tags,
 		}
 	case model.DepthStandard:
 		return model.Experiment{
@@ -21,6 +22,8 @@ func FilterExperiment(e model.Experiment, depth model.Depth) model.Experiment {
 			Parents:     e.Parents,
 			ChangesFrom: e.ChangesFrom,
 			Metric:      e.Metric,
+			Metrics:     e.Metrics,
+			Curves:      e.Curves,
 			Status:      e.Status,
 			LocalCV:     e.LocalCV,
 			PublicLB:    e.PublicLB,
@@ -0,0 +1,44 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// SnapshotDiffText renders a snapshot diff as a compact per-section listing.
+func SnapshotDiffText(d model.SnapshotDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Changes since %s:\n", d.Snapshot)
+	if d.Empty() {
+		b.WriteString("  (none)\n")
+		return b.String()
+	}
+
+	sections := []struct {
+		title string
+		set   model.DiffSet
+	}{
+		{"Experiments", d.Experiments},
+		{"Learnings", d.Learnings},
+		{"Graveyard", d.Graveyard},
+		{"Pinned", d.Pinned},
+	}
+	for _, sec := range sections {
+		if sec.set.Empty() {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", sec.title)
+		for _, id := range sec.set.Added {
+			fmt.Fprintf(&b, "  + %s\n", id)
+		}
+		for _, id := range sec.set.Removed {
+			fmt.Fprintf(&b, "  - %s\n", id)
+		}
+		for _, id := range sec.set.Changed {
+			fmt.Fprintf(&b, "  ~ %s\n", id)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/graph"
+)
+
+// GraphFormats lists the formats RenderGraph accepts.
+var GraphFormats = []string{"dot", "mermaid", "json"}
+
+// RenderGraph renders g as Graphviz DOT, a Mermaid flowchart or JSON. The
+// winning chain is drawn with thick green edges and the best experiment is
+// filled; failed experiments are dashed.
+func RenderGraph(g graph.Graph, format string) (string, error) {
+	switch format {
+	case "dot":
+		return graphDOT(g), nil
+	case "mermaid":
+		return graphMermaid(g), nil
+	case "json":
+		out, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out) + "\n", nil
+	}
+	return "", fmt.Errorf("invalid format %q: must be %s", format, strings.Join(GraphFormats, "|"))
+}
+
+func nodeLabel(g graph.Graph, n graph.Node, sep string) string {
+	return fmt.Sprintf("%s%s%s %.4f%s%s", n.ID, sep, g.Metric, n.Value, sep, n.Status)
+}
+
+func graphDOT(g graph.Graph) string {
+	var b strings.Builder
+	b.WriteString("digraph marrow {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(nodeLabel(g, n, "\n"))}
+		switch {
+		case n.ID == g.Best:
+			attrs = append(attrs, `style="rounded,filled,bold"`, `fillcolor="#c8e6c9"`, `color="#2e7d32"`, "penwidth=2")
+		case n.OnChain:
+			attrs = append(attrs, `color="#2e7d32"`, "penwidth=2")
+		case n.Status == "failed":
+			attrs = append(attrs, `style="rounded,dashed"`, "fontcolor=gray50")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		if e.OnChain {
+			fmt.Fprintf(&b, "  %s -> %s [color=\"#2e7d32\", penwidth=2];\n", dotQuote(e.From), dotQuote(e.To))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes s as a DOT string; newlines become \n line breaks.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func graphMermaid(g graph.Graph) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	var chain, failed []string
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", n.ID, strings.ReplaceAll(nodeLabel(g, n, "<br/>"), `"`, "#quot;"))
+		switch {
+		case n.OnChain && n.ID != g.Best:
+			chain = append(chain, n.ID)
+		case n.Status == "failed" && n.ID != g.Best:
+			failed = append(failed, n.ID)
+		}
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.OnChain {
+			arrow = "==>"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", e.From, arrow, e.To)
+	}
+
+	b.WriteString("  classDef chain stroke:#2e7d32,stroke-width:3px\n")
+	b.WriteString("  classDef best fill:#c8e6c9,stroke:#2e7d32,stroke-width:3px\n")
+	b.WriteString("  classDef failed stroke-dasharray:5 5,color:#888\n")
+	if len(chain) > 0 {
+		fmt.Fprintf(&b, "  class %s chain\n", strings.Join(chain, ","))
+	}
+	for _, n := range g.Nodes {
+		if n.ID == g.Best {
+			fmt.Fprintf(&b, "  class %s best\n", n.ID)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, "  class %s failed\n", strings.Join(failed, ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,220 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// Digest is what the Markdown export summarizes.
+type Digest struct {
+	Project   model.Project
+	Index     model.Index
+	Chain     []model.Experiment // root → best
+	Learnings model.LearningsFile
+	Graveyard model.GraveyardFile
+}
+
+// MarkdownOptions controls the Markdown export.
+type MarkdownOptions struct {
+	Budget int // approximate token limit (see EstimateTokens); 0 means no limit
+	Level  int // heading level of the title; sections go one level below. 0 means 1
+}
+
+// Markers delimit the section SyncMarkedSection maintains in another file.
+const (
+	MarkerBegin = "<!-- marrow:begin -->"
+	MarkerEnd   = "<!-- marrow:end -->"
+)
+
+type mdSection struct {
+	title   string
+	intro   string
+	items   []string
+	oldest  bool   // trim from the front (oldest first) rather than the end
+	omitted int    // items trimmed to fit the budget
+	more    string // where to find trimmed items
+}
+
+// Markdown renders d as a digest with an overview, the best result and its
+// chain, proven learnings, open assumptions, the graveyard, do-not-try items
+// and data warnings. To fit opts.Budget, open assumptions are trimmed first,
+// then proven learnings, the graveyard and the chain (whichever currently
+// holds the most items), and the do-not-try and data warning guardrails
+// last. Learnings and graveyard entries lose their oldest items, and the
+// chain loses the steps furthest from the best result. Each trimmed section
+// says how many items were left out.
+func Markdown(d Digest, opts MarkdownOptions) string {
+	level := opts.Level
+	if level <= 0 {
+		level = 1
+	}
+	title := strings.Repeat("#", level) + " " + d.Project.Name
+	head := strings.Repeat("#", level+1)
+
+	overview := markdownOverview(d)
+
+	best := &mdSection{title: "Best result", oldest: true, more: "`marrow index show`"}
+	c := d.Index.Computed
+	if c.BestExperiment != "" && c.BestMetric != nil {
+		best.intro = fmt.Sprintf("**%s** with %s = %.4f.", c.BestExperiment, c.BestMetric.Name, c.BestMetric.Value)
+		if len(d.Chain) > 1 {
+			best.intro += " It was reached through this chain:"
+		}
+	} else {
+		best.intro = "No experiments yet."
+	}
+	if len(d.Chain) > 1 {
+		for _, e := range d.Chain {
+			best.items = append(best.items, ExperimentOneLiner(e))
+		}
+	}
+
+	proven := &mdSection{title: "Proven learnings", oldest: true, more: "`marrow learn list`"}
+	for _, l := range d.Learnings.Proven {
+		proven.items = append(proven.items, learningItem(l))
+	}
+	assumptions := &mdSection{title: "Open assumptions", oldest: true, more: "`marrow learn list`"}
+	for _, l := range d.Learnings.Assumptions {
+		assumptions.items = append(assumptions.items, learningItem(l))
+	}
+	graveyard := &mdSection{title: "Graveyard", oldest: true, more: "`marrow learn graveyard-list`"}
+	if len(d.Graveyard.Entries) > 0 {
+		graveyard.intro = "Approaches that did not work."
+	}
+	for _, g := range d.Graveyard.Entries {
+		item := fmt.Sprintf("**%s**: %s", g.Approach, g.Reason)
+		if g.ExperimentID != "" {
+			item += " (" + g.ExperimentID + ")"
+		}
+		graveyard.items = append(graveyard.items, item)
+	}
+	doNotTry := &mdSection{title: "Do not try", items: d.Index.Pinned.DoNotTry, more: "`marrow index show`"}
+	warnings := &mdSection{title: "Data warnings", items: d.Index.Pinned.DataWarnings, more: "`marrow index show`"}
+
+	sections := []*mdSection{best, proven, assumptions, graveyard, doNotTry, warnings}
+	tiers := [][]*mdSection{{assumptions}, {proven, graveyard, best}, {warnings, doNotTry}}
+
+	render := func() string {
+		var b strings.Builder
+		b.WriteString(title + "\n\n" + overview)
+		for _, s := range sections {
+			if len(s.items) == 0 && s.omitted == 0 && s.intro == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "\n%s %s\n\n", head, s.title)
+			if s.intro != "" {
+				b.WriteString(s.intro + "\n")
+				if len(s.items) > 0 || s.omitted > 0 {
+					b.WriteString("\n")
+				}
+			}
+			note := fmt.Sprintf("- _%d left out to fit the budget, see %s_\n", s.omitted, s.more)
+			if s.omitted > 0 && s.oldest {
+				b.WriteString(note)
+			}
+			for _, it := range s.items {
+				b.WriteString("- " + it + "\n")
+			}
+			if s.omitted > 0 && !s.oldest {
+				b.WriteString(note)
+			}
+		}
+		return b.String()
+	}
+
+	out := render()
+	for opts.Budget > 0 && EstimateTokens(out) > opts.Budget {
+		var s *mdSection
+		for _, tier := range tiers {
+			for _, candidate := range tier {
+				if len(candidate.items) > 0 && (s == nil || len(candidate.items) > len(s.items)) {
+					s = candidate
+				}
+			}
+			if s != nil {
+				break
+			}
+		}
+		if s == nil {
+			break // only the overview is left
+		}
+		if s.oldest {
+			s.items = s.items[1:]
+		} else {
+			s.items = s.items[:len(s.items)-1]
+		}
+		s.omitted++
+		out = render()
+	}
+	return out
+}
+
+func markdownOverview(d Digest) string {
+	var b strings.Builder
+	p := d.Project
+	if p.Description != "" {
+		b.WriteString(p.Description + "\n\n")
+	}
+	metric := p.PrimaryMetric()
+	if p.TaskType != "" {
+		fmt.Fprintf(&b, "- **Task:** %s\n", p.TaskType)
+	}
+	fmt.Fprintf(&b, "- **Metric:** %s (%s)\n", metric.Name, metric.Direction)
+	for _, m := range p.SecondaryMetrics() {
+		fmt.Fprintf(&b, "- **Also tracked:** %s (%s)\n", m.Name, m.Direction)
+	}
+
+	c := d.Index.Computed
+	line := fmt.Sprintf("- **Experiments:** %d", c.TotalExperiments)
+	if len(c.StatusCounts) > 0 {
+		statuses := make([]string, 0, len(c.StatusCounts))
+		for s := range c.StatusCounts {
+			statuses = append(statuses, s)
+		}
+		sort.Strings(statuses)
+		parts := make([]string, len(statuses))
+		for i, s := range statuses {
+			parts[i] = fmt.Sprintf("%d %s", c.StatusCounts[s], s)
+		}
+		line += " (" + strings.Join(parts, ", ") + ")"
+	}
+	b.WriteString(line + "\n")
+	fmt.Fprintf(&b, "- **Learnings:** %d proven, %d assumptions, %d in the graveyard\n",
+		len(d.Learnings.Proven), len(d.Learnings.Assumptions), len(d.Graveyard.Entries))
+	if len(c.DataGroups) > 1 {
+		fmt.Fprintf(&b, "- **Data drift:** experiments span %d data setups; only compare scores within one\n", len(c.DataGroups))
+	}
+	if !c.LastUpdated.IsZero() {
+		fmt.Fprintf(&b, "- **Updated:** %s\n", c.LastUpdated.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+func learningItem(l model.Learning) string {
+	item := l.Text
+	if len(l.Tags) > 0 {
+		item += " _(" + strings.Join(l.Tags, ", ") + ")_"
+	}
+	return item
+}
+
+// SyncMarkedSection replaces the text between MarkerBegin and MarkerEnd in
+// doc with content. When doc has no markers, the marked section is appended.
+func SyncMarkedSection(doc, content string) (string, error) {
+	block := MarkerBegin + "\n" + strings.TrimRight(content, "\n") + "\n" + MarkerEnd
+	begin := strings.Index(doc, MarkerBegin)
+	end := strings.Index(doc, MarkerEnd)
+	switch {
+	case begin < 0 && end < 0:
+		if doc == "" {
+			return block + "\n", nil
+		}
+		return strings.TrimRight(doc, "\n") + "\n\n" + block + "\n", nil
+	case begin < 0 || end < begin:
+		return "", fmt.Errorf("unbalanced marrow markers: expected %s before %s", MarkerBegin, MarkerEnd)
+	}
+	return doc[:begin] + block + doc[end+len(MarkerEnd):], nil
+}
@@ -21,13 +21,23 @@ func ExperimentOneLiner(e model.Experiment) string {
 		for _, c := range e.ChangesFrom[pid] {
 			switch c.Type {
 			case "param":
-				parts = append(parts, fmt.Sprintf("%s=%s", c.Param, c.To))
+				if c.From != "" {
+					parts = append(parts, fmt.Sprintf("%s=%s→%s", c.Param, c.From, c.To))
+				} else {
+					parts = append(parts, fmt.Sprintf("%s=%s", c.Param, c.To))
+				}
 			case "added":
 				parts = append(parts, "+"+c.What)
 			case "removed":
 				parts = append(parts, "-"+c.What)
 			default:
-				if c.What != "" {
+				if c.What != "" && c.To != "" {
+					if c.From != "" {
+						parts = append(parts, fmt.Sprintf("%s %s→%s", c.What, c.From, c.To))
+					} else {
+						parts = append(parts, fmt.Sprintf("%s→%s", c.What, c.To))
+					}
+				} else if c.What != "" {
 					parts = append(parts, c.What)
 				}
 			}
@@ -49,11 +59,43 @@ func ExperimentOneLiner(e model.Experiment) string {
 	if e.Metric.Delta != 0 {
 		metricStr += fmt.Sprintf(" (%+.4f)", e.Metric.Delta)
 	}
+	if len(e.Metrics) > 0 {
+		metricStr += " [" + MetricValues(e.Metrics) + "]"
+	}
+	if e.LocalCV != nil {
+		metricStr += fmt.Sprintf(", cv %.4f", *e.LocalCV)
+	}
+	if e.PublicLB != nil {
+		metricStr += fmt.Sprintf(", lb %.4f", *e.PublicLB)
+	}
+	if e.DataVersion != 0 {
+		metricStr += fmt.Sprintf(", data v%d", e.DataVersion)
+	}
+
+	status := e.Status
+	if e.Reasoning.Type != "" {
+		status += " (" + e.Reasoning.Type + ")"
+	}
 
 	if changeSummary != "" {
-		return fmt.Sprintf("%s → %s, %s, %s", e.ID, changeSummary, metricStr, e.Status)
+		return fmt.Sprintf("%s → %s, %s, %s", e.ID, changeSummary, metricStr, status)
+	}
+	return fmt.Sprintf("%s → %s, %s", e.ID, metricStr, status)
+}
+
+// MetricValues renders secondary metrics as "name value" pairs sorted by name.
+func MetricValues(metrics map[string]float64) string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %.4f", name, metrics[name]))
 	}
-	return fmt.Sprintf("%s → %s, %s", e.ID, metricStr, e.Status)
+	return strings.Join(parts, ", ")
 }
 
 func LearningOneLiner(l model.Learning) string {
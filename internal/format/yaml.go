@@ -26,7 +26,12 @@ func WriteYAML(path string, source any) error {
 	if err := enc.Close(); err != nil {
 		return err
 	}
+	return WriteFileAtomic(path, buf.Bytes())
+}
 
+// WriteFileAtomic writes data to a temp file next to path and renames it into
+// place, keeping the mode of any existing file.
+func WriteFileAtomic(path string, data []byte) error {
 	dir := filepath.Dir(path)
 	tmp, err := os.CreateTemp(dir, ".marrow-tmp-*")
 	if err != nil {
@@ -39,7 +44,7 @@ func WriteYAML(path string, source any) error {
 		mode = info.Mode()
 	}
 
-	if _, err := tmp.Write(buf.Bytes()); err != nil {
+	if _, err := tmp.Write(data); err != nil {
 		tmp.Close()
 		os.Remove(tmpName)
 		return err
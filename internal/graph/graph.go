@@ -0,0 +1,160 @@
+// Package graph builds exportable views of the experiment DAG.
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// Graph is the experiment DAG, or a filtered part of it, ready for export.
+type Graph struct {
+	Metric string `json:"metric"`
+	Best   string `json:"best,omitempty"`
+	Nodes  []Node `json:"nodes"`
+	Edges  []Edge `json:"edges"`
+}
+
+// Node is one experiment. OnChain marks the winning chain from the root
+// to the best experiment.
+type Node struct {
+	ID      string   `json:"id"`
+	Status  string   `json:"status"`
+	Value   float64  `json:"value"`
+	Tags    []string `json:"tags,omitempty"`
+	OnChain bool     `json:"on_chain,omitempty"`
+}
+
+// Edge points from a parent to a child.
+type Edge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	OnChain bool   `json:"on_chain,omitempty"`
+}
+
+// Filter narrows the graph. All set filters must hold for an experiment
+// to be kept.
+type Filter struct {
+	Tags      []string // experiments with any of these tags
+	Root      string   // Root and everything descending from it
+	Ancestors string   // Ancestors and everything it descends from
+}
+
+// Build lays out exps as a graph. chain is the index's experiment chain;
+// its nodes and the edges between consecutive chain entries are marked.
+// Parents that are missing or filtered out produce no edge.
+func Build(exps []model.Experiment, chain []string, proj model.Project, f Filter) (Graph, error) {
+	byID := make(map[string]model.Experiment, len(exps))
+	children := make(map[string][]string)
+	for _, e := range exps {
+		byID[e.ID] = e
+	}
+	for _, e := range exps {
+		for _, p := range e.Parents {
+			if _, ok := byID[p]; ok {
+				children[p] = append(children[p], e.ID)
+			}
+		}
+	}
+
+	var keep map[string]bool
+	restrict := func(ids map[string]bool) {
+		if keep == nil {
+			keep = ids
+			return
+		}
+		for id := range keep {
+			if !ids[id] {
+				delete(keep, id)
+			}
+		}
+	}
+
+	if f.Root != "" {
+		if _, ok := byID[f.Root]; !ok {
+			return Graph{}, fmt.Errorf("experiment %s not found", f.Root)
+		}
+		restrict(reach(f.Root, func(id string) []string { return children[id] }))
+	}
+	if f.Ancestors != "" {
+		if _, ok := byID[f.Ancestors]; !ok {
+			return Graph{}, fmt.Errorf("experiment %s not found", f.Ancestors)
+		}
+		restrict(reach(f.Ancestors, func(id string) []string { return byID[id].Parents }))
+	}
+	if len(f.Tags) > 0 {
+		want := make(map[string]bool, len(f.Tags))
+		for _, t := range f.Tags {
+			want[t] = true
+		}
+		tagged := make(map[string]bool)
+		for _, e := range exps {
+			for _, t := range e.Tags {
+				if want[t] {
+					tagged[e.ID] = true
+					break
+				}
+			}
+		}
+		restrict(tagged)
+	}
+
+	onChain := make(map[string]bool, len(chain))
+	chainEdge := make(map[[2]string]bool, len(chain))
+	for i, id := range chain {
+		onChain[id] = true
+		if i > 0 {
+			chainEdge[[2]string{chain[i-1], id}] = true
+		}
+	}
+
+	metric := proj.PrimaryMetric()
+	g := Graph{Metric: metric.Name, Nodes: []Node{}, Edges: []Edge{}}
+	if len(chain) > 0 {
+		g.Best = chain[len(chain)-1]
+	}
+	for _, e := range exps {
+		if keep != nil && !keep[e.ID] {
+			continue
+		}
+		g.Nodes = append(g.Nodes, Node{
+			ID:      e.ID,
+			Status:  e.Status,
+			Value:   e.PrimaryValue(metric),
+			Tags:    e.Tags,
+			OnChain: onChain[e.ID],
+		})
+		for _, p := range e.Parents {
+			if _, ok := byID[p]; !ok || (keep != nil && !keep[p]) {
+				continue
+			}
+			g.Edges = append(g.Edges, Edge{From: p, To: e.ID, OnChain: chainEdge[[2]string{p, e.ID}]})
+		}
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].To != g.Edges[j].To {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].From < g.Edges[j].From
+	})
+	return g, nil
+}
+
+// reach returns start and every experiment reachable from it through next.
+func reach(start string, next func(string) []string) map[string]bool {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, n := range next(id) {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return seen
+}
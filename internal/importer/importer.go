@@ -0,0 +1,316 @@
+// Package importer turns runs recorded by other experiment trackers into
+// marrow experiments. Each reader produces Runs; Import maps them onto the
+// project's metrics, links parents and skips runs imported before.
+package importer
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/curve"
+	"github.com/rzzdr/marrow/internal/index"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// Run is one run read from another tracker.
+type Run struct {
+	ID      string // the tool's run ID
+	Name    string
+	Group   string // MLflow experiment, W&B project, etc.
+	Parent  string // the tool's run ID of the parent run, or an experiment ID
+	Start   time.Time
+	Failed  bool
+	Metrics map[string]float64 // final value of each metric
+	Params  map[string]any
+	Tags    []string
+	Notes   string
+	Env     *model.Environment
+	Curves  map[string][]model.CurvePoint // per-step values of each metric, by step
+}
+
+// Options controls how runs become experiments.
+type Options struct {
+	Metric string   // run metric holding the primary metric; default: the primary metric's name
+	Tags   []string // added to every imported experiment
+	Best   bool     // take the best value of the metric's curve rather than the final one
+	Update bool     // refresh runs imported before instead of skipping them
+}
+
+// Imported pairs a run with the experiment created for it.
+type Imported struct {
+	RunID      string
+	Experiment string
+}
+
+// Result reports what an import did.
+type Result struct {
+	Imported []Imported
+	Updated  []Imported // with Options.Update, runs imported before that changed
+	Skipped  int        // runs imported before, and unchanged with Options.Update
+	Warnings []string
+}
+
+// Import writes an experiment for each run not imported from tool before.
+// Parents come first, so parent links resolve within the batch as well as
+// to earlier imports; a parent may also name an existing experiment. A run's
+// primary metric is opts.Metric, and declared secondary metrics are taken
+// from run metrics of the same name. Their curves, when the run has them,
+// are stored with the experiment. Status is failed for failed runs, and
+// otherwise improved, degraded or neutral against the parent, or neutral
+// for runs without one. Finished runs missing the metric are skipped with a
+// warning.
+//
+// With opts.Update, runs imported before have their metrics, status and
+// curves refreshed instead of being skipped; anything added to the
+// experiment since is kept.
+func Import(s *store.Store, tool string, runs []Run, opts Options) (Result, error) {
+	var res Result
+	err := s.WithLock(func(s *store.Store) error {
+		proj, err := s.ReadProject()
+		if err != nil {
+			return fmt.Errorf("reading project: %w", err)
+		}
+		existing, err := s.ListExperiments()
+		if err != nil {
+			return fmt.Errorf("listing experiments: %w", err)
+		}
+
+		metric := proj.PrimaryMetric()
+		source := opts.Metric
+		if source == "" {
+			source = metric.Name
+		}
+
+		// Run ID → experiment, covering earlier imports and this batch.
+		mapped := make(map[string]model.Experiment)
+		byID := make(map[string]model.Experiment, len(existing))
+		for _, e := range existing {
+			byID[e.ID] = e
+			if e.Source != nil && e.Source.Tool == tool {
+				mapped[e.Source.RunID] = e
+			}
+		}
+
+		for _, r := range ordered(runs) {
+			old, seen := mapped[r.ID]
+			if seen && !opts.Update {
+				res.Skipped++
+				continue
+			}
+			value, ok := r.Metrics[source]
+			points := r.Curves[source]
+			if opts.Best && len(points) > 0 {
+				value, ok = curve.Best(points, metric.HigherIsBetter()).Value, true
+			}
+			if !ok && !r.Failed {
+				res.Warnings = append(res.Warnings, fmt.Sprintf("run %s has no %q metric; skipped", runLabel(r), source))
+				continue
+			}
+
+			var exp model.Experiment
+			var parent *model.Experiment
+			if seen {
+				exp = old
+				exp.Tags = mergeTags(old.Tags, r.Tags, opts.Tags)
+				if len(old.Parents) > 0 {
+					if p, ok := byID[old.Parents[0]]; ok {
+						parent = &p
+					}
+				}
+			} else {
+				id, err := s.NextExperimentID()
+				if err != nil {
+					return err
+				}
+				exp = model.Experiment{
+					ID:          id,
+					Timestamp:   r.Start.UTC(),
+					Params:      r.Params,
+					Tags:        mergeTags(r.Tags, opts.Tags),
+					Notes:       r.Notes,
+					Environment: r.Env,
+					Source:      &model.Source{Tool: tool, RunID: r.ID, Name: r.Name, Group: r.Group},
+				}
+				if exp.Timestamp.IsZero() {
+					exp.Timestamp = time.Now().UTC()
+				}
+				if r.Parent != "" {
+					p, ok := mapped[r.Parent]
+					if !ok {
+						p, ok = byID[r.Parent]
+					}
+					if !ok {
+						res.Warnings = append(res.Warnings, fmt.Sprintf("run %s: parent run %s was not imported; recorded without a parent", runLabel(r), r.Parent))
+					} else {
+						parent = &p
+						exp.Parents = []string{p.ID}
+						exp.ChangesFrom = util.AddParamChanges(nil, p.ID, p.Params, exp.Params)
+					}
+				}
+			}
+
+			exp.Metric = model.MetricResult{Name: metric.Name, Value: value}
+			exp.Metrics = nil
+			for _, m := range proj.SecondaryMetrics() {
+				if v, ok := r.Metrics[m.Name]; ok {
+					if exp.Metrics == nil {
+						exp.Metrics = make(map[string]float64)
+					}
+					exp.Metrics[m.Name] = v
+				}
+			}
+			if note := curveNote(source, points, metric.HigherIsBetter()); note != "" && r.Notes == "" {
+				// Refresh the note this import wrote before, never one added since.
+				if exp.Notes == "" || strings.HasPrefix(exp.Notes, source+": final ") {
+					exp.Notes = note
+				}
+			}
+
+			exp.Status = "neutral"
+			if r.Failed {
+				exp.Status = "failed"
+			}
+			// A failed run without the metric has no score to compare.
+			if parent != nil && ok {
+				exp.Metric.Baseline = parent.PrimaryValue(metric)
+				exp.Metric.Delta = exp.Metric.Value - exp.Metric.Baseline
+				if !r.Failed && parent.Status != "failed" {
+					exp.Status = compare(exp.Metric.Value, exp.Metric.Baseline, metric.HigherIsBetter())
+				}
+			}
+
+			for _, m := range proj.AllMetrics() {
+				name := m.Name
+				if name == metric.Name {
+					name = source
+				}
+				if len(r.Curves[name]) == 0 {
+					continue
+				}
+				if err := curve.Record(s, &exp, m.Name, r.Curves[name], m.HigherIsBetter()); err != nil {
+					return err
+				}
+			}
+
+			if seen && reflect.DeepEqual(old, exp) {
+				res.Skipped++
+				continue
+			}
+			if err := s.WriteExperiment(exp); err != nil {
+				return err
+			}
+			mapped[r.ID] = exp
+			byID[exp.ID] = exp
+			if seen {
+				res.Updated = append(res.Updated, Imported{RunID: r.ID, Experiment: exp.ID})
+			} else {
+				res.Imported = append(res.Imported, Imported{RunID: r.ID, Experiment: exp.ID})
+			}
+		}
+
+		if len(res.Imported) == 0 && len(res.Updated) == 0 {
+			return nil
+		}
+		if _, err := index.Rebuild(s); err != nil {
+			res.Warnings = append(res.Warnings, "index rebuild failed: "+err.Error())
+		}
+		var parts []string
+		if n := len(res.Imported); n > 0 {
+			part := fmt.Sprintf("imported %d %s runs as %s", n, tool, res.Imported[0].Experiment)
+			if n > 1 {
+				part += "–" + res.Imported[n-1].Experiment
+			}
+			parts = append(parts, part)
+		}
+		if n := len(res.Updated); n > 0 {
+			parts = append(parts, fmt.Sprintf("updated %d %s runs", n, tool))
+		}
+		if err := s.AppendChangelog(model.ChangelogEntry{Action: "exp_imported", Type: tool, Summary: strings.Join(parts, ", ")}); err != nil {
+			res.Warnings = append(res.Warnings, "changelog append failed: "+err.Error())
+		}
+		return nil
+	})
+	return res, err
+}
+
+// curveNote summarizes where a curve ended and peaked, e.g.
+// "val/acc: final 0.8412 at step 900, best 0.8530 at step 600".
+func curveNote(name string, points []model.CurvePoint, higher bool) string {
+	if len(points) == 0 {
+		return ""
+	}
+	st := curve.Stats(points, higher)
+	return fmt.Sprintf("%s: final %.4f at step %d, best %.4f at step %d", name, st.Final, st.FinalStep, st.Best, st.BestStep)
+}
+
+// ordered sorts runs by start time, then moves each run after its parent.
+func ordered(runs []Run) []Run {
+	sorted := append([]Run(nil), runs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Start.Equal(sorted[j].Start) {
+			return sorted[i].Start.Before(sorted[j].Start)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	byID := make(map[string]Run, len(sorted))
+	for _, r := range sorted {
+		byID[r.ID] = r
+	}
+	out := make([]Run, 0, len(sorted))
+	done := make(map[string]bool, len(sorted))
+	var visit func(r Run)
+	visit = func(r Run) {
+		if done[r.ID] {
+			return
+		}
+		done[r.ID] = true // set first so a parent cycle cannot recurse forever
+		if p, ok := byID[r.Parent]; ok {
+			visit(p)
+		}
+		out = append(out, r)
+	}
+	for _, r := range sorted {
+		visit(r)
+	}
+	return out
+}
+
+// compare classifies value against the parent's, treating differences
+// within floating-point noise as neutral.
+func compare(value, parent float64, higher bool) string {
+	if math.Abs(value-parent) <= 1e-12*math.Max(1, math.Abs(parent)) {
+		return "neutral"
+	}
+	if (value > parent) == higher {
+		return "improved"
+	}
+	return "degraded"
+}
+
+func mergeTags(lists ...[]string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, t := range list {
+			if t != "" && !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+func runLabel(r Run) string {
+	if r.Name != "" && r.Name != r.ID {
+		return fmt.Sprintf("%s (%s)", r.ID, r.Name)
+	}
+	return r.ID
+}
@@ -0,0 +1,254 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// MLflow run statuses as the file store writes them.
+const (
+	mlflowFailed = 4
+	mlflowKilled = 5
+)
+
+type mlflowExperimentMeta struct {
+	ExperimentID   string `yaml:"experiment_id"`
+	Name           string `yaml:"name"`
+	LifecycleStage string `yaml:"lifecycle_stage"`
+}
+
+type mlflowRunMeta struct {
+	RunID          string `yaml:"run_id"`
+	RunUUID        string `yaml:"run_uuid"` // older stores
+	RunName        string `yaml:"run_name"`
+	Status         any    `yaml:"status"` // an int, or a name in some versions
+	StartTime      int64  `yaml:"start_time"`
+	LifecycleStage string `yaml:"lifecycle_stage"`
+}
+
+// ReadMLflow reads runs from an MLflow file store: the mlruns directory, or
+// a directory containing one. experiment limits the import to one MLflow
+// experiment by name or ID. Deleted experiments and runs are left out. Each
+// metric takes its latest value (highest step, then latest timestamp).
+func ReadMLflow(path, experiment string) ([]Run, []string, error) {
+	root := path
+	if info, err := os.Stat(filepath.Join(path, "mlruns")); err == nil && info.IsDir() {
+		root = filepath.Join(path, "mlruns")
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading MLflow store: %w", err)
+	}
+
+	var runs []Run
+	var warnings []string
+	found := false
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		expDir := filepath.Join(root, entry.Name())
+		var meta mlflowExperimentMeta
+		if err := format.ReadYAML(filepath.Join(expDir, "meta.yaml"), &meta); err != nil || meta.ExperimentID == "" {
+			continue // not an experiment (e.g. the model registry)
+		}
+		if meta.LifecycleStage == "deleted" {
+			continue
+		}
+		if experiment != "" && experiment != meta.Name && experiment != meta.ExperimentID {
+			continue
+		}
+		found = true
+
+		runDirs, err := os.ReadDir(expDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading MLflow experiment %s: %w", meta.Name, err)
+		}
+		for _, rd := range runDirs {
+			if !rd.IsDir() {
+				continue
+			}
+			run, ok, err := readMLflowRun(filepath.Join(expDir, rd.Name()), meta.Name)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+				continue
+			}
+			if ok {
+				runs = append(runs, run)
+			}
+		}
+	}
+	if experiment != "" && !found {
+		return nil, nil, fmt.Errorf("MLflow experiment %q not found in %s", experiment, root)
+	}
+	return runs, warnings, nil
+}
+
+// readMLflowRun reads one run directory. ok is false for directories that
+// are not runs and for deleted runs.
+func readMLflowRun(dir, group string) (Run, bool, error) {
+	var meta mlflowRunMeta
+	if err := format.ReadYAML(filepath.Join(dir, "meta.yaml"), &meta); err != nil {
+		if os.IsNotExist(err) {
+			return Run{}, false, nil
+		}
+		return Run{}, false, fmt.Errorf("run %s: reading meta.yaml: %w", filepath.Base(dir), err)
+	}
+	if meta.LifecycleStage == "deleted" {
+		return Run{}, false, nil
+	}
+
+	r := Run{ID: meta.RunID, Name: meta.RunName, Group: group, Failed: mlflowRunFailed(meta.Status)}
+	if r.ID == "" {
+		r.ID = meta.RunUUID
+	}
+	if r.ID == "" {
+		r.ID = filepath.Base(dir)
+	}
+	if meta.StartTime > 0 {
+		r.Start = time.UnixMilli(meta.StartTime).UTC()
+	}
+
+	metrics, err := readMLflowFiles(filepath.Join(dir, "metrics"))
+	if err != nil {
+		return Run{}, false, fmt.Errorf("run %s: %w", r.ID, err)
+	}
+	for name, raw := range metrics {
+		v, ok := latestMetric(raw)
+		if !ok {
+			continue
+		}
+		if r.Metrics == nil {
+			r.Metrics = make(map[string]float64)
+		}
+		r.Metrics[name] = v
+	}
+
+	params, err := readMLflowFiles(filepath.Join(dir, "params"))
+	if err != nil {
+		return Run{}, false, fmt.Errorf("run %s: %w", r.ID, err)
+	}
+	for name, raw := range params {
+		if r.Params == nil {
+			r.Params = make(map[string]any)
+		}
+		r.Params[name] = util.ParseScalar(strings.TrimSpace(raw))
+	}
+
+	tags, err := readMLflowFiles(filepath.Join(dir, "tags"))
+	if err != nil {
+		return Run{}, false, fmt.Errorf("run %s: %w", r.ID, err)
+	}
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.TrimSpace(tags[name])
+		switch {
+		case name == "mlflow.parentRunId":
+			r.Parent = value
+		case name == "mlflow.note.content":
+			r.Notes = value
+		case name == "mlflow.runName":
+			if r.Name == "" {
+				r.Name = value
+			}
+		case strings.HasPrefix(name, "mlflow."):
+			// other system tags: user, source, git commit, ...
+		case value == "" || value == "true":
+			r.Tags = append(r.Tags, name)
+		default:
+			r.Tags = append(r.Tags, name+"="+value)
+		}
+	}
+	return r, true, nil
+}
+
+func mlflowRunFailed(status any) bool {
+	switch s := status.(type) {
+	case int:
+		return s == mlflowFailed || s == mlflowKilled
+	case string:
+		return s == "FAILED" || s == "KILLED" || s == strconv.Itoa(mlflowFailed) || s == strconv.Itoa(mlflowKilled)
+	}
+	return false
+}
+
+// readMLflowFiles reads every file under dir, keyed by its path relative to
+// dir with slashes, since MLflow stores "a/b" names in subdirectories. A
+// missing dir has no entries.
+func readMLflowFiles(dir string) (map[string]string, error) {
+	out := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		out[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// latestMetric picks the value with the highest step, then the latest
+// timestamp, from an MLflow metric file of "timestamp value [step]" lines.
+func latestMetric(raw string) (float64, bool) {
+	var (
+		best             float64
+		bestStep, bestTS int64
+		found            bool
+	)
+	sc := bufio.NewScanner(strings.NewReader(raw))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		ts, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		var step int64
+		if len(fields) > 2 {
+			if step, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+				continue
+			}
+		}
+		if !found || step > bestStep || (step == bestStep && ts >= bestTS) {
+			best, bestStep, bestTS, found = v, step, ts, true
+		}
+	}
+	return best, found
+}
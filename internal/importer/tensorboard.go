@@ -0,0 +1,374 @@
+package importer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// ReadTensorBoard reads scalar summaries from the TensorBoard event files
+// under logdir. Each directory holding events.out.tfevents.* files is a run,
+// identified by its path relative to logdir (or logdir's name when the event
+// files sit in logdir itself). Every scalar tag becomes a curve sorted by
+// step, and its value at the last step a metric. Both simple values
+// (PyTorch, TF1) and scalar tensors (TF2) are read; other summaries are
+// ignored.
+func ReadTensorBoard(logdir string) ([]Run, []string, error) {
+	abs, err := filepath.Abs(logdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make(map[string][]string) // run directory → event files
+	err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.Contains(d.Name(), ".tfevents.") {
+			dir := filepath.Dir(path)
+			files[dir] = append(files[dir], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading TensorBoard logdir: %w", err)
+	}
+
+	dirs := make([]string, 0, len(files))
+	for dir := range files {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var runs []Run
+	var warnings []string
+	for _, dir := range dirs {
+		id := filepath.Base(abs)
+		if rel, err := filepath.Rel(abs, dir); err == nil && rel != "." {
+			id = filepath.ToSlash(rel)
+		}
+		r := Run{ID: id}
+
+		sort.Strings(files[dir]) // file names start with the creation time
+		series := make(map[string][]scalarEvent)
+		var first float64
+		for _, path := range files[dir] {
+			events, start, err := readTFEvents(path)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("run %s: %s: %v", id, filepath.Base(path), err))
+			}
+			for _, ev := range events {
+				series[ev.tag] = append(series[ev.tag], ev)
+			}
+			if start > 0 && (first == 0 || start < first) {
+				first = start
+			}
+		}
+		if len(series) == 0 {
+			continue
+		}
+		if first > 0 {
+			sec, frac := math.Modf(first)
+			r.Start = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+		}
+
+		r.Metrics = make(map[string]float64, len(series))
+		r.Curves = make(map[string][]model.CurvePoint, len(series))
+		for tag, events := range series {
+			curve := toCurve(events)
+			r.Curves[tag] = curve
+			r.Metrics[tag] = curve[len(curve)-1].Value
+		}
+		runs = append(runs, r)
+	}
+	return runs, warnings, nil
+}
+
+type scalarEvent struct {
+	tag   string
+	step  int64
+	wall  float64
+	value float64
+}
+
+// toCurve sorts events by step. When a step was logged more than once, as
+// after resuming from a checkpoint, the latest write wins.
+func toCurve(events []scalarEvent) []model.CurvePoint {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].step != events[j].step {
+			return events[i].step < events[j].step
+		}
+		return events[i].wall < events[j].wall
+	})
+	curve := make([]model.CurvePoint, 0, len(events))
+	for _, ev := range events {
+		if n := len(curve); n > 0 && curve[n-1].Step == ev.step {
+			curve[n-1].Value = ev.value
+			continue
+		}
+		curve = append(curve, model.CurvePoint{Step: ev.step, Value: ev.value})
+	}
+	return curve
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// readTFEvents reads the scalar events of one event file, along with the
+// wall time of its first event. A record cut short at the end of the file
+// (the writer is still running) ends the read quietly; a corrupt record
+// ends it with an error, returning what was read before it.
+//
+// Each record is a little-endian uint64 length, a masked CRC-32C of the
+// length, the serialized Event, and a masked CRC-32C of the Event.
+func readTFEvents(path string) ([]scalarEvent, float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var events []scalarEvent
+	var start float64
+	var offset int64
+	header := make([]byte, 12)
+	for {
+		if _, err := io.ReadFull(f, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return events, start, nil
+			}
+			return events, start, err
+		}
+		length := binary.LittleEndian.Uint64(header[:8])
+		if maskedCRC(header[:8]) != binary.LittleEndian.Uint32(header[8:]) {
+			return events, start, fmt.Errorf("corrupt record at offset %d", offset)
+		}
+		if length > 1<<30 {
+			return events, start, fmt.Errorf("record at offset %d is too large (%d bytes)", offset, length)
+		}
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(f, data); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return events, start, nil
+			}
+			return events, start, err
+		}
+		body := data[:length]
+		if maskedCRC(body) != binary.LittleEndian.Uint32(data[length:]) {
+			return events, start, fmt.Errorf("corrupt record at offset %d", offset)
+		}
+		wall, scalars, err := decodeEvent(body)
+		if err != nil {
+			return events, start, fmt.Errorf("record at offset %d: %w", offset, err)
+		}
+		offset += int64(len(header) + len(data))
+		if start == 0 && wall > 0 {
+			start = wall
+		}
+		events = append(events, scalars...)
+	}
+}
+
+func maskedCRC(b []byte) uint32 {
+	c := crc32.Checksum(b, castagnoli)
+	return (c>>15 | c<<17) + 0xa282ead8
+}
+
+// Field numbers from tensorflow/core/util/event.proto and
+// tensorflow/core/framework/{summary,tensor}.proto.
+const (
+	eventWallTime = 1
+	eventStep     = 2
+	eventSummary  = 5
+
+	summaryValue = 1
+
+	valueTag         = 1
+	valueSimpleValue = 2
+	valueTensor      = 8
+
+	tensorDtype   = 1
+	tensorContent = 4
+	tensorFloat   = 5
+	tensorDouble  = 6
+
+	dtFloat  = 1
+	dtDouble = 2
+)
+
+// decodeEvent returns an Event's wall time and the scalars in its summary.
+func decodeEvent(b []byte) (float64, []scalarEvent, error) {
+	var wall float64
+	var step int64
+	var summary []byte
+	err := walkProto(b, func(f protoField) error {
+		switch {
+		case f.num == eventWallTime && f.wire == wireFixed64:
+			wall = math.Float64frombits(f.fixed)
+		case f.num == eventStep && f.wire == wireVarint:
+			step = int64(f.fixed)
+		case f.num == eventSummary && f.wire == wireBytes:
+			summary = f.bytes
+		}
+		return nil
+	})
+	if err != nil || summary == nil {
+		return wall, nil, err
+	}
+
+	var scalars []scalarEvent
+	err = walkProto(summary, func(f protoField) error {
+		if f.num != summaryValue || f.wire != wireBytes {
+			return nil
+		}
+		tag, value, ok, err := decodeValue(f.bytes)
+		if err != nil {
+			return err
+		}
+		if ok {
+			scalars = append(scalars, scalarEvent{tag: tag, step: step, wall: wall, value: value})
+		}
+		return nil
+	})
+	return wall, scalars, err
+}
+
+// decodeValue reads a Summary.Value. ok is false unless it holds a single
+// float or double.
+func decodeValue(b []byte) (tag string, value float64, ok bool, err error) {
+	var tensor []byte
+	err = walkProto(b, func(f protoField) error {
+		switch {
+		case f.num == valueTag && f.wire == wireBytes:
+			tag = string(f.bytes)
+		case f.num == valueSimpleValue && f.wire == wireFixed32:
+			value, ok = float32Value(uint32(f.fixed)), true
+		case f.num == valueTensor && f.wire == wireBytes:
+			tensor = f.bytes
+		}
+		return nil
+	})
+	if err != nil || ok || tensor == nil {
+		return tag, value, ok, err
+	}
+
+	var dtype uint64
+	var values []float64
+	var content []byte
+	err = walkProto(tensor, func(f protoField) error {
+		switch {
+		case f.num == tensorDtype && f.wire == wireVarint:
+			dtype = f.fixed
+		case f.num == tensorContent && f.wire == wireBytes:
+			content = f.bytes
+		case f.num == tensorFloat && f.wire == wireFixed32:
+			values = append(values, float32Value(uint32(f.fixed)))
+		case f.num == tensorFloat && f.wire == wireBytes: // packed
+			for p := f.bytes; len(p) >= 4; p = p[4:] {
+				values = append(values, float32Value(binary.LittleEndian.Uint32(p)))
+			}
+		case f.num == tensorDouble && f.wire == wireFixed64:
+			values = append(values, math.Float64frombits(f.fixed))
+		case f.num == tensorDouble && f.wire == wireBytes: // packed
+			for p := f.bytes; len(p) >= 8; p = p[8:] {
+				values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(p)))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return tag, 0, false, err
+	}
+	switch {
+	case dtype == dtFloat && len(content) == 4:
+		values = []float64{float32Value(binary.LittleEndian.Uint32(content))}
+	case dtype == dtDouble && len(content) == 8:
+		values = []float64{math.Float64frombits(binary.LittleEndian.Uint64(content))}
+	case dtype != dtFloat && dtype != dtDouble:
+		return tag, 0, false, nil
+	}
+	if len(values) != 1 {
+		return tag, 0, false, nil
+	}
+	return tag, values[0], true, nil
+}
+
+// float32Value widens a float32 by its shortest decimal form, so 0.85 is
+// stored as 0.85 rather than 0.8500000238418579.
+func float32Value(bits uint32) float64 {
+	f := math.Float32frombits(bits)
+	v, err := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	if err != nil {
+		return float64(f)
+	}
+	return v
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoField is one decoded field. fixed holds varint and fixed-width
+// values; bytes holds length-delimited ones.
+type protoField struct {
+	num   int
+	wire  int
+	fixed uint64
+	bytes []byte
+}
+
+// walkProto calls fn for each field of a serialized protobuf message.
+func walkProto(b []byte, fn func(protoField) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("malformed protobuf")
+		}
+		b = b[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return errors.New("malformed protobuf varint")
+			}
+			f.fixed, b = v, b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errors.New("truncated protobuf field")
+			}
+			f.fixed, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errors.New("truncated protobuf field")
+			}
+			f.bytes, b = b[n:n+int(l)], b[n+int(l):]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errors.New("truncated protobuf field")
+			}
+			f.fixed, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", f.wire)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
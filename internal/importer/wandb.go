@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// WandbParentKey is the config key naming a run's parent: a W&B run ID or
+// an experiment ID. W&B has no parent links of its own.
+const WandbParentKey = "marrow_parent"
+
+type wandbMetadata struct {
+	Python    string `json:"python"`
+	StartedAt string `json:"startedAt"`
+	GPU       string `json:"gpu"`
+	GPUCount  int    `json:"gpu_count"`
+	GPUNvidia []struct {
+		Name string `json:"name"`
+	} `json:"gpu_nvidia"`
+	Git struct {
+		Commit string `json:"commit"`
+	} `json:"git"`
+}
+
+// ReadWandb reads W&B run directories: a single run directory, a wandb
+// directory holding offline-run-* and run-* directories, or a directory
+// containing one. Each run's files/ directory supplies params from
+// config.yaml, final metrics from wandb-summary.json, and the python version,
+// GPU and git commit from wandb-metadata.json.
+func ReadWandb(path string) ([]Run, []string, error) {
+	if isWandbRun(path) {
+		r, err := readWandbRun(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []Run{r}, nil, nil
+	}
+
+	root := path
+	if info, err := os.Stat(filepath.Join(path, "wandb")); err == nil && info.IsDir() {
+		root = filepath.Join(path, "wandb")
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading W&B directory: %w", err)
+	}
+
+	var runs []Run
+	var warnings []string
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if !entry.IsDir() || !isWandbRun(dir) {
+			continue // latest-run is a symlink to one of the others
+		}
+		r, err := readWandbRun(dir)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs, warnings, nil
+}
+
+func isWandbRun(dir string) bool {
+	name := filepath.Base(dir)
+	if !strings.HasPrefix(name, "offline-run-") && !strings.HasPrefix(name, "run-") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, "files"))
+	return err == nil && info.IsDir()
+}
+
+// readWandbRun reads one run directory, named [offline-]run-<start>-<id>.
+func readWandbRun(dir string) (Run, error) {
+	name := strings.TrimPrefix(strings.TrimPrefix(filepath.Base(dir), "offline-"), "run-")
+	stamp, id, ok := strings.Cut(name, "-")
+	if !ok || id == "" {
+		id = name
+	}
+	r := Run{ID: id}
+	if t, err := time.ParseInLocation("20060102_150405", stamp, time.Local); err == nil {
+		r.Start = t
+	}
+	files := filepath.Join(dir, "files")
+
+	config, err := readWandbConfig(filepath.Join(files, "config.yaml"))
+	if err != nil {
+		return Run{}, fmt.Errorf("run %s: %w", id, err)
+	}
+	if parent, ok := config[WandbParentKey]; ok {
+		r.Parent = fmt.Sprint(parent)
+		delete(config, WandbParentKey)
+	}
+	if len(config) > 0 {
+		if r.Params, err = util.FlattenParams(config); err != nil {
+			return Run{}, fmt.Errorf("run %s: config.yaml: %w", id, err)
+		}
+	}
+
+	var summary map[string]any
+	if err := readJSON(filepath.Join(files, "wandb-summary.json"), &summary); err != nil {
+		return Run{}, fmt.Errorf("run %s: %w", id, err)
+	}
+	for key, v := range summary {
+		if f, ok := v.(float64); ok && !strings.HasPrefix(key, "_") {
+			if r.Metrics == nil {
+				r.Metrics = make(map[string]float64)
+			}
+			r.Metrics[key] = f
+		}
+	}
+
+	var meta wandbMetadata
+	if err := readJSON(filepath.Join(files, "wandb-metadata.json"), &meta); err != nil {
+		return Run{}, fmt.Errorf("run %s: %w", id, err)
+	}
+	if t, ok := parseWandbTime(meta.StartedAt); ok {
+		r.Start = t
+	}
+	r.Env = wandbEnvironment(meta)
+	return r, nil
+}
+
+// readWandbConfig reads config.yaml, dropping W&B's own keys and unwrapping
+// the {desc, value} mapping older clients write around each entry. A
+// missing file has no entries.
+func readWandbConfig(path string) (map[string]any, error) {
+	var raw map[string]any
+	if err := format.ReadYAML(path, &raw); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("config.yaml: %w", err)
+	}
+	config := make(map[string]any, len(raw))
+	for key, v := range raw {
+		if key == "wandb_version" || key == "_wandb" {
+			continue
+		}
+		if m, ok := v.(map[string]any); ok && isWandbValue(m) {
+			v = m["value"]
+		}
+		config[key] = v
+	}
+	return config, nil
+}
+
+func isWandbValue(m map[string]any) bool {
+	for key := range m {
+		if key != "value" && key != "desc" {
+			return false
+		}
+	}
+	_, ok := m["value"]
+	return ok
+}
+
+func wandbEnvironment(meta wandbMetadata) *model.Environment {
+	gpu, count := meta.GPU, meta.GPUCount
+	if gpu == "" && len(meta.GPUNvidia) > 0 {
+		gpu, count = meta.GPUNvidia[0].Name, len(meta.GPUNvidia)
+	}
+	if gpu != "" && count > 1 {
+		gpu = fmt.Sprintf("%dx %s", count, gpu)
+	}
+	python := strings.TrimPrefix(meta.Python, "CPython ")
+	commit := strings.ToLower(meta.Git.Commit)
+	if !util.ValidGitCommit(commit) {
+		commit = "" // not a hash; never hand it to git
+	}
+	if python == "" && gpu == "" && commit == "" {
+		return nil
+	}
+	return &model.Environment{Python: python, GPU: gpu, GitCommit: commit}
+}
+
+// parseWandbTime parses startedAt, which newer clients write in RFC 3339 and
+// older ones without a zone, in UTC.
+func parseWandbTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// readJSON decodes path into v. A missing file leaves v unchanged.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// DataGroups groups experiments by the data they ran on (data version, data
+// hash and split seed), in order of first appearance. Like DataDifferences,
+// it ignores fields only one side recorded: an experiment joins the first
+// group it has no differences with, and fills in the fields the group was
+// missing. Experiments that record none of these share their own group, as
+// there is nothing to place them by.
+func DataGroups(exps []model.Experiment) []model.DataGroup {
+	var groups []model.DataGroup
+	for _, e := range exps {
+		key := dataKey(e)
+		found := false
+		for i := range groups {
+			if sameData(groups[i], key) {
+				mergeData(&groups[i], key)
+				groups[i].Experiments = append(groups[i].Experiments, e.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			key.Experiments = []string{e.ID}
+			groups = append(groups, key)
+		}
+	}
+	return groups
+}
+
+// DataDifferences lists how the data behind a and b differs, e.g.
+// "data version 1 vs 2". Fields only one of them recorded are not compared.
+// An empty result means their scores can be compared.
+func DataDifferences(a, b model.Experiment) []string {
+	return dataDifferences(dataKey(a), dataKey(b))
+}
+
+func dataDifferences(ka, kb model.DataGroup) []string {
+	var diffs []string
+	if ka.DataVersion != 0 && kb.DataVersion != 0 && ka.DataVersion != kb.DataVersion {
+		diffs = append(diffs, fmt.Sprintf("data version %d vs %d", ka.DataVersion, kb.DataVersion))
+	}
+	if ka.DataHash != "" && kb.DataHash != "" && ka.DataHash != kb.DataHash {
+		diffs = append(diffs, fmt.Sprintf("data hash %s vs %s", ka.DataHash, kb.DataHash))
+	}
+	if ka.SplitSeed != nil && kb.SplitSeed != nil && *ka.SplitSeed != *kb.SplitSeed {
+		diffs = append(diffs, fmt.Sprintf("split seed %d vs %d", *ka.SplitSeed, *kb.SplitSeed))
+	}
+	return diffs
+}
+
+// ChainDrift describes each step of chain where an experiment ran on
+// different data from its predecessor. Unknown IDs are skipped.
+func ChainDrift(chain []string, exps map[string]model.Experiment) []string {
+	var warnings []string
+	for i := 1; i < len(chain); i++ {
+		prev, ok1 := exps[chain[i-1]]
+		cur, ok2 := exps[chain[i]]
+		if !ok1 || !ok2 {
+			continue
+		}
+		if diffs := DataDifferences(prev, cur); len(diffs) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s → %s ran on different data (%s); the delta is not a like-for-like comparison",
+				prev.ID, cur.ID, strings.Join(diffs, "; ")))
+		}
+	}
+	return warnings
+}
+
+func dataKey(e model.Experiment) model.DataGroup {
+	key := model.DataGroup{DataVersion: e.DataVersion}
+	if e.Environment != nil {
+		key.DataHash = e.Environment.DataHash
+		key.SplitSeed = e.Environment.SplitSeed
+	}
+	return key
+}
+
+func hasDataInfo(e model.Experiment) bool {
+	return recorded(dataKey(e))
+}
+
+func recorded(k model.DataGroup) bool {
+	return k.DataVersion != 0 || k.DataHash != "" || k.SplitSeed != nil
+}
+
+// sameData reports whether b can join group a: both recorded nothing, or
+// both recorded something and no field recorded by both differs.
+func sameData(a, b model.DataGroup) bool {
+	if recorded(a) != recorded(b) {
+		return false
+	}
+	return len(dataDifferences(a, b)) == 0
+}
+
+// mergeData fills in the fields of g that k recorded and g did not.
+func mergeData(g *model.DataGroup, k model.DataGroup) {
+	if g.DataVersion == 0 {
+		g.DataVersion = k.DataVersion
+	}
+	if g.DataHash == "" {
+		g.DataHash = k.DataHash
+	}
+	if g.SplitSeed == nil {
+		g.SplitSeed = k.SplitSeed
+	}
+}
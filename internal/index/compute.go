@@ -2,7 +2,6 @@ package index
 
 import (
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/rzzdr/marrow/internal/model"
@@ -12,7 +11,7 @@ func Compute(
 	exps []model.Experiment,
 	learnings model.LearningsFile,
 	graveyard model.GraveyardFile,
-	metric model.MetricDef,
+	proj model.Project,
 ) model.ComputedIndex {
 	ci := model.ComputedIndex{
 		LastUpdated:      time.Now().UTC(),
@@ -39,54 +38,133 @@ func Compute(
 	}
 	sort.Strings(ci.AllTags)
 
-	best := findBest(exps, metric)
+	metric := proj.PrimaryMetric()
+	best := findBest(currentData(exps, proj), metric)
 	if best != nil {
 		ci.BestExperiment = best.ID
-		ci.BestMetric = &best.Metric
+		br := primaryResult(*best, metric)
+		ci.BestMetric = &br
 	}
 
 	if best != nil {
 		ci.ExperimentChain = computeChain(exps, *best, metric)
 	}
 
+	ci.BestByMetric = computeBestByMetric(exps, proj.AllMetrics())
+	ci.ParetoFront = ParetoFront(exps, proj.AllMetrics())
+	ci.DataGroups = driftGroups(exps)
+
 	return ci
 }
 
+// currentData keeps the experiments that may compete for best under the
+// project's index settings.
+func currentData(exps []model.Experiment, proj model.Project) []model.Experiment {
+	if proj.Index == nil || !proj.Index.CurrentDataOnly {
+		return exps
+	}
+	var out []model.Experiment
+	for _, e := range exps {
+		if proj.OnCurrentData(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// driftGroups returns the data groups only when there is more than one, so
+// the index stays small for projects whose data never changed.
+func driftGroups(exps []model.Experiment) []model.DataGroup {
+	groups := DataGroups(exps)
+	if len(groups) < 2 {
+		return nil
+	}
+	return groups
+}
+
 func findBest(exps []model.Experiment, metric model.MetricDef) *model.Experiment {
+	return findBestBy(exps, metric, func(e model.Experiment) (float64, bool) {
+		return e.PrimaryValue(metric), true
+	})
+}
+
+// findBestBy returns the non-failed experiment with the best value according
+// to metric's direction, skipping experiments for which value reports false.
+func findBestBy(exps []model.Experiment, metric model.MetricDef, value func(model.Experiment) (float64, bool)) *model.Experiment {
 	if len(exps) == 0 {
 		return nil
 	}
 
-	higher := strings.EqualFold(metric.Direction, "higher_is_better")
+	higher := metric.HigherIsBetter()
 	var best *model.Experiment
+	var bestVal float64
 	for i := range exps {
 		if exps[i].Status == "failed" {
 			continue
 		}
-		if best == nil {
-			best = &exps[i]
+		v, ok := value(exps[i])
+		if !ok {
 			continue
 		}
-		if higher {
-			if exps[i].Metric.Value > best.Metric.Value {
-				best = &exps[i]
-			}
-		} else {
-			if exps[i].Metric.Value < best.Metric.Value {
-				best = &exps[i]
-			}
+		if best == nil || isBetter(v, bestVal, higher) {
+			best = &exps[i]
+			bestVal = v
 		}
 	}
 	return best
 }
 
+// computeBestByMetric maps each declared metric to the experiment that leads
+// on it. Only populated for multi-metric projects.
+func computeBestByMetric(exps []model.Experiment, metrics []model.MetricDef) map[string]string {
+	if len(metrics) < 2 {
+		return nil
+	}
+
+	result := make(map[string]string, len(metrics))
+	for i, m := range metrics {
+		var best *model.Experiment
+		if i == 0 {
+			best = findBest(exps, m)
+		} else {
+			best = findBestBy(exps, m, func(e model.Experiment) (float64, bool) {
+				return e.MetricValue(m.Name)
+			})
+		}
+		if best != nil {
+			result[m.Name] = best.ID
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func primaryResult(e model.Experiment, metric model.MetricDef) model.MetricResult {
+	if e.Metric.Name == metric.Name {
+		return e.Metric
+	}
+	if v, ok := e.Metrics[metric.Name]; ok {
+		return model.MetricResult{Name: metric.Name, Value: v}
+	}
+	return e.Metric
+}
+
+func isBetter(v, than float64, higher bool) bool {
+	if higher {
+		return v > than
+	}
+	return v < than
+}
+
 func computeChain(exps []model.Experiment, best model.Experiment, metric model.MetricDef) []string {
 	expMap := make(map[string]model.Experiment, len(exps))
 	for _, e := range exps {
 		expMap[e.ID] = e
 	}
 
-	higher := strings.EqualFold(metric.Direction, "higher_is_better")
+	higher := metric.HigherIsBetter()
 
 	var chain []string
 	current := best
@@ -106,11 +184,7 @@ func computeChain(exps []model.Experiment, best model.Experiment, metric model.M
 				continue
 			}
 			if p, ok := expMap[pid]; ok {
-				if bestParent == nil {
-					bestParent = &p
-				} else if higher && p.Metric.Value > bestParent.Metric.Value {
-					bestParent = &p
-				} else if !higher && p.Metric.Value < bestParent.Metric.Value {
+				if bestParent == nil || isBetter(p.PrimaryValue(metric), bestParent.PrimaryValue(metric), higher) {
 					bestParent = &p
 				}
 			}
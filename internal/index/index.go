@@ -3,14 +3,24 @@ package index
 import (
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/rzzdr/marrow/internal/model"
 	"github.com/rzzdr/marrow/internal/store"
 )
 
+// Rebuild recomputes the computed index from scratch, keeping pinned entries.
 func Rebuild(s *store.Store) (model.Index, error) {
+	var idx model.Index
+	err := s.WithLock(func(s *store.Store) error {
+		var err error
+		idx, err = rebuild(s)
+		return err
+	})
+	return idx, err
+}
+
+func rebuild(s *store.Store) (model.Index, error) {
 	idx, err := s.ReadIndex()
 	if err != nil && !os.IsNotExist(err) {
 		return idx, fmt.Errorf("reading existing index (pinned data at risk): %w", err)
@@ -20,7 +30,7 @@ func Rebuild(s *store.Store) (model.Index, error) {
 	if err != nil {
 		return idx, err
 	}
-	if err := proj.Metric.Validate(); err != nil {
+	if err := proj.ValidateMetrics(); err != nil {
 		return idx, err
 	}
 
@@ -39,7 +49,7 @@ func Rebuild(s *store.Store) (model.Index, error) {
 		return idx, err
 	}
 
-	idx.Computed = Compute(exps, learnings, graveyard, proj.Metric)
+	idx.Computed = Compute(exps, learnings, graveyard, proj)
 
 	if err := s.WriteIndex(idx); err != nil {
 		return idx, err
@@ -48,19 +58,40 @@ func Rebuild(s *store.Store) (model.Index, error) {
 	return idx, nil
 }
 
+// UpdateIncremental folds a newly written experiment into the index without
+// rescanning everything unless the best experiment changes.
 func UpdateIncremental(s *store.Store, newExp model.Experiment) (model.Index, error) {
+	var idx model.Index
+	err := s.WithLock(func(s *store.Store) error {
+		var err error
+		idx, err = updateIncremental(s, newExp)
+		return err
+	})
+	return idx, err
+}
+
+func updateIncremental(s *store.Store, newExp model.Experiment) (model.Index, error) {
 	idx, err := s.ReadIndex()
 	if err != nil {
-		return Rebuild(s)
+		return rebuild(s)
 	}
 
 	proj, err := s.ReadProject()
 	if err != nil {
 		return idx, err
 	}
-	if err := proj.Metric.Validate(); err != nil {
+	if err := proj.ValidateMetrics(); err != nil {
 		return idx, err
 	}
+	metric := proj.PrimaryMetric()
+
+	// The stored best may predate a data version bump; only a full rebuild
+	// can pick the best among current-data experiments.
+	if proj.Index != nil && proj.Index.CurrentDataOnly && idx.Computed.BestExperiment != "" {
+		if cur, err := s.ReadExperiment(idx.Computed.BestExperiment); err != nil || !proj.OnCurrentData(cur) {
+			return rebuild(s)
+		}
+	}
 
 	c := &idx.Computed
 	c.LastUpdated = time.Now().UTC()
@@ -81,30 +112,33 @@ func UpdateIncremental(s *store.Store, newExp model.Experiment) (model.Index, er
 		}
 	}
 
-	isBetter := false
-	if newExp.Status != "failed" {
+	better := false
+	if newExp.Status != "failed" && proj.OnCurrentData(newExp) {
 		if c.BestMetric == nil {
-			isBetter = true
+			better = true
 		} else {
-			higher := strings.EqualFold(proj.Metric.Direction, "higher_is_better")
-			if higher {
-				isBetter = newExp.Metric.Value > c.BestMetric.Value
-			} else {
-				isBetter = newExp.Metric.Value < c.BestMetric.Value
-			}
+			better = isBetter(newExp.PrimaryValue(metric), c.BestMetric.Value, metric.HigherIsBetter())
 		}
 	}
 
-	if isBetter {
-		c.BestExperiment = newExp.ID
-		c.BestMetric = &newExp.Metric
-
+	if better || len(proj.SecondaryMetrics()) > 0 || hasDataInfo(newExp) || len(c.DataGroups) > 0 {
 		exps, err := s.ListExperiments()
 		if err == nil {
-			c.ExperimentChain = computeChain(exps, newExp, proj.Metric)
+			c.DataGroups = driftGroups(exps)
+			if better {
+				c.ExperimentChain = computeChain(exps, newExp, metric)
+			}
+			c.BestByMetric = computeBestByMetric(exps, proj.AllMetrics())
+			c.ParetoFront = ParetoFront(exps, proj.AllMetrics())
 		}
 	}
 
+	if better {
+		c.BestExperiment = newExp.ID
+		br := primaryResult(newExp, metric)
+		c.BestMetric = &br
+	}
+
 	if err := s.WriteIndex(idx); err != nil {
 		return idx, err
 	}
@@ -113,6 +147,10 @@ func UpdateIncremental(s *store.Store, newExp model.Experiment) (model.Index, er
 
 // UpdateLearningCounts refreshes only the learning/graveyard counts in the index.
 func UpdateLearningCounts(s *store.Store) error {
+	return s.WithLock(updateLearningCounts)
+}
+
+func updateLearningCounts(s *store.Store) error {
 	idx, err := s.ReadIndex()
 	if err != nil {
 		return err
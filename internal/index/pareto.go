@@ -0,0 +1,73 @@
+package index
+
+import (
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// ParetoFront returns the IDs of the non-dominated experiments across all
+// metrics. Failed experiments and experiments missing any metric are left out,
+// as are projects with fewer than two metrics where "best" is already enough.
+func ParetoFront(exps []model.Experiment, metrics []model.MetricDef) []string {
+	if len(metrics) < 2 {
+		return nil
+	}
+
+	type candidate struct {
+		id     string
+		values []float64
+	}
+
+	var candidates []candidate
+	for _, e := range exps {
+		if e.Status == "failed" {
+			continue
+		}
+		values := make([]float64, len(metrics))
+		complete := true
+		for i, m := range metrics {
+			if i == 0 {
+				values[i] = e.PrimaryValue(m)
+				continue
+			}
+			v, ok := e.MetricValue(m.Name)
+			if !ok {
+				complete = false
+				break
+			}
+			values[i] = v
+		}
+		if complete {
+			candidates = append(candidates, candidate{id: e.ID, values: values})
+		}
+	}
+
+	var front []string
+	for i, c := range candidates {
+		dominated := false
+		for j, other := range candidates {
+			if i != j && dominates(other.values, c.values, metrics) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, c.id)
+		}
+	}
+	return front
+}
+
+// dominates reports whether a is at least as good as b on every metric and
+// strictly better on at least one.
+func dominates(a, b []float64, metrics []model.MetricDef) bool {
+	strictly := false
+	for i, m := range metrics {
+		if isBetter(b[i], a[i], m.HigherIsBetter()) {
+			return false
+		}
+		if isBetter(a[i], b[i], m.HigherIsBetter()) {
+			strictly = true
+		}
+	}
+	return strictly
+}
@@ -3,21 +3,30 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rzzdr/marrow/internal/analysis"
+	"github.com/rzzdr/marrow/internal/capture"
+	"github.com/rzzdr/marrow/internal/curve"
 	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/graph"
 	idx "github.com/rzzdr/marrow/internal/index"
 	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/query"
 	"github.com/rzzdr/marrow/internal/store"
 	"github.com/rzzdr/marrow/internal/util"
 )
 
 type handlers struct {
-	store *store.Store
-	mu    sync.Mutex
+	store     *store.Store
+	mu        sync.Mutex
+	resources resourceSet
+	subs      subscriptions
 }
 
 // formatWarnings formats a slice of warnings into a user-friendly string
@@ -28,15 +37,38 @@ func formatWarnings(warnings []string) string {
 	return "\n\n⚠ Warnings:\n  - " + strings.Join(warnings, "\n  - ")
 }
 
+// withStoreLock runs fn while holding the cross-process .marrow/ lock and
+// reports a lock timeout as a tool error.
+func (h *handlers) withStoreLock(fn func(s *store.Store) *mcp.CallToolResult) *mcp.CallToolResult {
+	var result *mcp.CallToolResult
+	if err := h.store.WithLock(func(s *store.Store) error {
+		result = fn(s)
+		return nil
+	}); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to lock store: %v", err))
+	}
+	return result
+}
+
 func (h *handlers) getProjectSummary(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text, err := h.projectSummaryText()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return toolResultWithMeta(text, format.EstimateTokens(text), "summary"), nil
+}
+
+// projectSummaryText renders the project config, index overview and pinned
+// guardrails. It backs get_project_summary and the prompts.
+func (h *handlers) projectSummaryText() (string, error) {
 	proj, err := h.store.ReadProject()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to read project: %v", err)), nil
+		return "", fmt.Errorf("failed to read project: %w", err)
 	}
 
 	index, err := h.store.ReadIndex()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to read index: %v", err)), nil
+		return "", fmt.Errorf("failed to read index: %w", err)
 	}
 
 	var b strings.Builder
@@ -44,7 +76,15 @@ func (h *handlers) getProjectSummary(_ context.Context, req mcp.CallToolRequest)
 	if proj.Description != "" {
 		fmt.Fprintf(&b, "Description: %s\n", proj.Description)
 	}
-	fmt.Fprintf(&b, "Task: %s\nMetric: %s (%s)\n", proj.TaskType, proj.Metric.Name, proj.Metric.Direction)
+	metric := proj.PrimaryMetric()
+	fmt.Fprintf(&b, "Task: %s\nMetric: %s (%s)\n", proj.TaskType, metric.Name, metric.Direction)
+	if secondary := proj.SecondaryMetrics(); len(secondary) > 0 {
+		names := make([]string, 0, len(secondary))
+		for _, m := range secondary {
+			names = append(names, fmt.Sprintf("%s (%s)", m.Name, m.Direction))
+		}
+		fmt.Fprintf(&b, "Other metrics: %s\n", strings.Join(names, ", "))
+	}
 	fmt.Fprintf(&b, "\n--- Index ---\n")
 	c := index.Computed
 	fmt.Fprintf(&b, "Experiments: %d\n", c.TotalExperiments)
@@ -54,6 +94,11 @@ func (h *handlers) getProjectSummary(_ context.Context, req mcp.CallToolRequest)
 	if len(c.ExperimentChain) > 0 {
 		fmt.Fprintf(&b, "Chain: %s\n", strings.Join(c.ExperimentChain, " → "))
 	}
+	for _, m := range proj.SecondaryMetrics() {
+		if id, ok := c.BestByMetric[m.Name]; ok {
+			fmt.Fprintf(&b, "Best %s: %s\n", m.Name, id)
+		}
+	}
 	fmt.Fprintf(&b, "Proven: %d | Assumptions: %d | Graveyard: %d\n", c.ProvenCount, c.AssumptionCount, c.GraveyardCount)
 
 	p := index.Pinned
@@ -79,8 +124,7 @@ func (h *handlers) getProjectSummary(_ context.Context, req mcp.CallToolRequest)
 		fmt.Fprintf(&b, "\nNotes: %s\n", p.Notes)
 	}
 
-	text := b.String()
-	return toolResultWithMeta(text, format.EstimateTokens(text), "summary"), nil
+	return b.String(), nil
 }
 
 func (h *handlers) getBestExperiment(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -117,6 +161,29 @@ func (h *handlers) getExperiment(_ context.Context, req mcp.CallToolRequest) (*m
 	return experimentResult(exp, depth)
 }
 
+func (h *handlers) getExperimentCurve(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := req.RequireString("id")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: id"), nil
+	}
+	proj, err := h.store.ReadProject()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read project: %v", err)), nil
+	}
+	exp, err := h.store.ReadExperiment(id)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("experiment not found: %v", err)), nil
+	}
+
+	name := req.GetString("metric", proj.PrimaryMetric().Name)
+	points, st, err := curve.Load(h.store, proj, exp, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text := format.CurveText(exp.ID, name, st, curve.Downsample(points, int(req.GetFloat("points", 50)), st.BestStep))
+	return toolResultWithMeta(text, format.EstimateTokens(text), "full"), nil
+}
+
 func (h *handlers) getLearnings(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	lf, err := h.store.ReadLearnings()
 	if err != nil {
@@ -265,11 +332,13 @@ func (h *handlers) getExperimentChain(_ context.Context, req mcp.CallToolRequest
 	depth := model.ParseDepth(req.GetString("depth", "summary"))
 
 	var b strings.Builder
+	chain := make(map[string]model.Experiment, len(index.Computed.ExperimentChain))
 	for _, id := range index.Computed.ExperimentChain {
 		exp, err := h.store.ReadExperiment(id)
 		if err != nil {
 			continue
 		}
+		chain[id] = exp
 		if depth == model.DepthSummary {
 			fmt.Fprintf(&b, "%s\n", format.ExperimentOneLiner(exp))
 		} else {
@@ -283,10 +352,74 @@ func (h *handlers) getExperimentChain(_ context.Context, req mcp.CallToolRequest
 		}
 	}
 
-	text := b.String()
+	text := b.String() + formatWarnings(idx.ChainDrift(index.Computed.ExperimentChain, chain))
 	return toolResultWithMeta(text, format.EstimateTokens(text), string(depth)), nil
 }
 
+func (h *handlers) getExperimentGraph(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	proj, err := h.store.ReadProject()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read project: %v", err)), nil
+	}
+	exps, err := h.store.ListExperiments()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list experiments: %v", err)), nil
+	}
+	index, err := h.store.ReadIndex()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read index: %v", err)), nil
+	}
+
+	g, err := graph.Build(exps, index.Computed.ExperimentChain, proj, graph.Filter{
+		Tags:      util.SplitTags(req.GetString("tags", "")),
+		Root:      req.GetString("root", ""),
+		Ancestors: req.GetString("ancestors", ""),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(g.Nodes) == 0 {
+		return mcp.NewToolResultText("No experiments match."), nil
+	}
+
+	text, err := format.RenderGraph(g, req.GetString("format", "mermaid"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return toolResultWithMeta(text, format.EstimateTokens(text), "full"), nil
+}
+
+func (h *handlers) getParetoFront(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	proj, err := h.store.ReadProject()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read project: %v", err)), nil
+	}
+	if len(proj.SecondaryMetrics()) == 0 {
+		return mcp.NewToolResultText("Only one metric is declared; use get_best_experiment instead."), nil
+	}
+
+	index, err := h.store.ReadIndex()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read index: %v", err)), nil
+	}
+
+	if len(index.Computed.ParetoFront) == 0 {
+		return mcp.NewToolResultText("Pareto front is empty."), nil
+	}
+
+	var exps []model.Experiment
+	for _, id := range index.Computed.ParetoFront {
+		exp, err := h.store.ReadExperiment(id)
+		if err != nil {
+			continue
+		}
+		exps = append(exps, exp)
+	}
+
+	depth := model.ParseDepth(req.GetString("depth", "summary"))
+	return experimentsResult(exps, depth)
+}
+
 func (h *handlers) getExperimentsByTag(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	tagsStr, err := req.RequireString("tags")
 	if err != nil {
@@ -308,6 +441,46 @@ func (h *handlers) getExperimentsByTag(_ context.Context, req mcp.CallToolReques
 	return experimentsResult(exps, depth)
 }
 
+func (h *handlers) queryExperiments(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	expr, err := req.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError("missing required parameter: query"), nil
+	}
+
+	q, err := query.Parse(expr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	proj, err := h.store.ReadProject()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read project: %v", err)), nil
+	}
+
+	exps, err := h.store.ListExperiments()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list experiments: %v", err)), nil
+	}
+
+	if err := q.Validate(proj, exps); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sortKey := req.GetString("sort", "")
+	exps = q.Filter(exps, proj)
+	if err := query.Sort(exps, sortKey, proj); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if len(exps) == 0 {
+		return mcp.NewToolResultText("No experiments match that query."), nil
+	}
+
+	exps = query.Limit(exps, int(req.GetFloat("limit", 0)), sortKey != "")
+
+	depth := model.ParseDepth(req.GetString("depth", "summary"))
+	return experimentsResult(exps, depth)
+}
+
 func (h *handlers) compareExperiments(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	id1, err := req.RequireString("id1")
 	if err != nil {
@@ -327,31 +500,45 @@ func (h *handlers) compareExperiments(_ context.Context, req mcp.CallToolRequest
 		return mcp.NewToolResultError(fmt.Sprintf("experiment %s not found", id2)), nil
 	}
 
-	var b strings.Builder
-	fmt.Fprintf(&b, "Comparison: %s vs %s\n\n", id1, id2)
-	fmt.Fprintf(&b, "%s:\n  %s = %.4f | status: %s | model: %s\n",
-		id1, exp1.Metric.Name, exp1.Metric.Value, exp1.Status, exp1.BaseModel)
-	fmt.Fprintf(&b, "%s:\n  %s = %.4f | status: %s | model: %s\n",
-		id2, exp2.Metric.Name, exp2.Metric.Value, exp2.Status, exp2.BaseModel)
-
-	delta := exp2.Metric.Value - exp1.Metric.Value
-	metricDirection := "higher_is_better" // default when project is unreadable
+	primary := model.MetricDef{Name: exp1.Metric.Name, Direction: "higher_is_better"} // default when project is unreadable
+	var secondary []model.MetricDef
 	var warnings []string
 	proj, err := h.store.ReadProject()
 	if err == nil {
-		metricDirection = proj.Metric.Direction
+		primary = proj.PrimaryMetric()
+		secondary = proj.SecondaryMetrics()
 	} else {
 		warnings = append(warnings, fmt.Sprintf("project unreadable, assuming higher_is_better: %v", err))
+		secondary = undeclaredMetrics(exp1, exp2)
 	}
-	direction := "improvement"
-	if (strings.EqualFold(metricDirection, "higher_is_better") && delta < 0) ||
-		(strings.EqualFold(metricDirection, "lower_is_better") && delta > 0) {
-		direction = "regression"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Comparison: %s vs %s\n\n", id1, id2)
+	for _, e := range []model.Experiment{exp1, exp2} {
+		fmt.Fprintf(&b, "%s:\n  %s = %.4f | status: %s | model: %s\n",
+			e.ID, primary.Name, e.PrimaryValue(primary), e.Status, e.BaseModel)
+		if len(e.Metrics) > 0 {
+			fmt.Fprintf(&b, "  %s\n", format.MetricValues(e.Metrics))
+		}
+	}
+
+	if diffs := idx.DataDifferences(exp1, exp2); len(diffs) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s and %s ran on different data (%s); the delta is not a like-for-like comparison",
+			id1, id2, strings.Join(diffs, "; ")))
 	}
-	if delta == 0 {
-		direction = "no change"
+
+	delta := exp2.PrimaryValue(primary) - exp1.PrimaryValue(primary)
+	fmt.Fprintf(&b, "\nDelta: %+.4f (%s)\n", delta, deltaDirection(delta, primary))
+	for _, m := range secondary {
+		v1, ok1 := exp1.MetricValue(m.Name)
+		v2, ok2 := exp2.MetricValue(m.Name)
+		if !ok1 || !ok2 {
+			continue
+		}
+		d := v2 - v1
+		fmt.Fprintf(&b, "  %s: %+.4f (%s)\n", m.Name, d, deltaDirection(d, m))
 	}
-	fmt.Fprintf(&b, "\nDelta: %+.4f (%s)\n", delta, direction)
+	b.WriteString(commitRange(filepath.Dir(h.store.Root()), exp1, exp2))
 
 	if exp2.Notes != "" {
 		fmt.Fprintf(&b, "\n%s notes: %s\n", id2, exp2.Notes)
@@ -361,6 +548,182 @@ func (h *handlers) compareExperiments(_ context.Context, req mcp.CallToolRequest
 	return toolResultWithMeta(text, format.EstimateTokens(text), "standard"), nil
 }
 
+// maxRangeCommits caps the commits compare_experiments lists between two
+// experiments.
+const maxRangeCommits = 10
+
+// commitRange describes the code change between the commits two experiments
+// were logged at, listing the commits in between when the repository at
+// projectDir has both. It is empty unless both recorded a commit.
+func commitRange(projectDir string, exp1, exp2 model.Experiment) string {
+	if exp1.Environment == nil || exp2.Environment == nil || exp1.Environment.GitCommit == "" || exp2.Environment.GitCommit == "" {
+		return ""
+	}
+	from, to := exp1.Environment.GitCommit, exp2.Environment.GitCommit
+
+	var b strings.Builder
+	if from == to {
+		fmt.Fprintf(&b, "\nCode: same commit %s\n", shortCommit(from))
+	} else if commits, err := capture.CommitRange(projectDir, from, to); err != nil {
+		fmt.Fprintf(&b, "\nCode: %s..%s (not in this repository)\n", shortCommit(from), shortCommit(to))
+	} else {
+		fmt.Fprintf(&b, "\nCode: %s..%s, %s\n", shortCommit(from), shortCommit(to), countCommits(len(commits)))
+		if len(commits) == 0 {
+			if back, err := capture.CommitRange(projectDir, to, from); err == nil && len(back) > 0 {
+				fmt.Fprintf(&b, "  %s ran on code %s older than %s\n", exp2.ID, countCommits(len(back)), exp1.ID)
+			}
+		}
+		for i, c := range commits {
+			if i == maxRangeCommits {
+				fmt.Fprintf(&b, "  ... and %d more\n", len(commits)-maxRangeCommits)
+				break
+			}
+			fmt.Fprintf(&b, "  %s\n", c)
+		}
+	}
+	for _, e := range []model.Experiment{exp1, exp2} {
+		if !e.Environment.GitDirty {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s had uncommitted changes", e.ID)
+		for _, a := range e.Artifacts {
+			if a.Kind == "diff" {
+				fmt.Fprintf(&b, " (saved in %s)", a.Path)
+				break
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func shortCommit(c string) string {
+	return c[:min(7, len(c))]
+}
+
+func countCommits(n int) string {
+	if n == 1 {
+		return "1 commit"
+	}
+	return fmt.Sprintf("%d commits", n)
+}
+
+func (h *handlers) checkComparability(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	exps, err := h.store.ListExperiments()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list experiments: %v", err)), nil
+	}
+	if ids := util.SplitTags(req.GetString("ids", "")); len(ids) > 0 {
+		byID := make(map[string]model.Experiment, len(exps))
+		for _, e := range exps {
+			byID[e.ID] = e
+		}
+		picked := make([]model.Experiment, 0, len(ids))
+		for _, id := range ids {
+			e, ok := byID[id]
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("experiment %s not found", id)), nil
+			}
+			picked = append(picked, e)
+		}
+		exps = picked
+	}
+	if len(exps) == 0 {
+		return mcp.NewToolResultText("No experiments yet."), nil
+	}
+
+	groups := idx.DataGroups(exps)
+	var b strings.Builder
+	if len(groups) == 1 {
+		fmt.Fprintf(&b, "All %d experiments ran on the same data (%s); their scores are comparable.\n", len(exps), groups[0].Label())
+	} else {
+		fmt.Fprintf(&b, "%d experiments ran on %d different data setups. Compare scores only within a group.\n\n", len(exps), len(groups))
+		for _, g := range groups {
+			fmt.Fprintf(&b, "%s (%d): %s\n", g.Label(), len(g.Experiments), strings.Join(g.Experiments, ", "))
+		}
+	}
+
+	if proj, err := h.store.ReadProject(); err == nil && proj.DataVersion != 0 {
+		var stale []string
+		for _, e := range exps {
+			if e.DataVersion != 0 && e.DataVersion != proj.DataVersion {
+				stale = append(stale, e.ID)
+			}
+		}
+		if len(stale) > 0 {
+			fmt.Fprintf(&b, "\nThe project is on data v%d; %d experiment(s) used another version: %s\n",
+				proj.DataVersion, len(stale), strings.Join(stale, ", "))
+		}
+	}
+
+	text := b.String()
+	return toolResultWithMeta(text, format.EstimateTokens(text), "summary"), nil
+}
+
+func (h *handlers) analyzeParams(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	outFormat := req.GetString("format", "text")
+	if outFormat != "text" && outFormat != "yaml" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format: %s. Use: text|yaml", outFormat)), nil
+	}
+
+	proj, err := h.store.ReadProject()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read project: %v", err)), nil
+	}
+	exps, err := h.store.ListExperiments()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list experiments: %v", err)), nil
+	}
+
+	report, err := analysis.Params(exps, proj, req.GetString("metric", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	depth := model.ParseDepth(req.GetString("depth", "summary"))
+	text := format.ParamReportText(report, depth)
+	if outFormat == "yaml" {
+		text, err = format.MarshalYAMLString(format.FilterParamReport(report, depth))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal report: %v", err)), nil
+		}
+	}
+	return toolResultWithMeta(text, format.EstimateTokens(text), string(depth)), nil
+}
+
+// undeclaredMetrics lists the secondary metrics recorded on either
+// experiment, assuming higher_is_better since no project config is available.
+func undeclaredMetrics(exps ...model.Experiment) []model.MetricDef {
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range exps {
+		for name := range e.Metrics {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	defs := make([]model.MetricDef, 0, len(names))
+	for _, name := range names {
+		defs = append(defs, model.MetricDef{Name: name, Direction: "higher_is_better"})
+	}
+	return defs
+}
+
+func deltaDirection(delta float64, metric model.MetricDef) string {
+	switch {
+	case delta == 0:
+		return "no change"
+	case (delta > 0) == metric.HigherIsBetter():
+		return "improvement"
+	default:
+		return "regression"
+	}
+}
+
 func (h *handlers) getAllExperiments(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	exps, err := h.store.ListExperiments()
 	if err != nil {
@@ -398,51 +761,137 @@ func (h *handlers) logExperiment(_ context.Context, req mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError(fmt.Sprintf("invalid status: %s. Use: improved|degraded|neutral|failed", status)), nil
 	}
 
-	id, err := h.store.NextExperimentID()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to generate ID: %v", err)), nil
-	}
-
-	metricVal := req.GetFloat("metric_value", 0)
+	metric := proj.PrimaryMetric()
 
 	exp := model.Experiment{
-		ID:        id,
 		Timestamp: time.Now().UTC(),
 		BaseModel: req.GetString("base_model", ""),
 		Status:    status,
 		Metric: model.MetricResult{
-			Name:  proj.Metric.Name,
-			Value: metricVal,
+			Name:  metric.Name,
+			Value: req.GetFloat("metric_value", 0),
 		},
 		Notes: req.GetString("notes", ""),
 	}
 
+	if raw := req.GetString("metrics", ""); raw != "" {
+		extra, err := util.ParseMetrics(raw)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := proj.ValidateMetricValues(extra); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		exp.Metrics = extra
+	}
+
 	parents := req.GetString("parents", "")
 	if parents != "" {
 		exp.Parents = util.SplitTags(parents)
-		for _, pid := range exp.Parents {
-			if _, err := h.store.ReadExperiment(pid); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("parent experiment %s not found", pid)), nil
-			}
-		}
 	}
 	tags := req.GetString("tags", "")
 	if tags != "" {
 		exp.Tags = util.SplitTags(tags)
 	}
 
+	if exp.ChangesFrom, err = util.ParseChanges(req.GetStringSlice("changes", nil), exp.Parents); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	exp.Reasoning, err = util.ParseReasoning(req.GetString("reasoning", ""), req.GetString("reasoning_type", ""), req.GetStringSlice("evidence", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if exp.Environment, err = util.ParseEnvironment(req.GetString("environment", "")); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if raw, ok := req.GetArguments()["params"]; ok {
+		if exp.Params, err = util.FlattenParams(raw); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
 	var warnings []string
+	if req.GetBool("capture_env", false) {
+		var cfg model.EnvConfig
+		if proj.Environment != nil {
+			cfg = *proj.Environment
+		}
+		var captured *model.Environment
+		captured, warnings = capture.Environment(filepath.Dir(h.store.Root()), cfg)
+		exp.Environment = capture.Merge(captured, exp.Environment)
+	}
+	var patch []byte
+	if req.GetBool("git", true) {
+		exp.Environment = capture.Merge(capture.Git(filepath.Dir(h.store.Root())), exp.Environment)
+		if req.GetBool("git_diff", false) {
+			if patch, err = capture.GitPatch(filepath.Dir(h.store.Root()), exp.Environment); err != nil {
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+
+	args := req.GetArguments()
+	if _, ok := args["local_cv"]; ok {
+		v := req.GetFloat("local_cv", 0)
+		exp.LocalCV = &v
+	}
+	if _, ok := args["public_lb"]; ok {
+		v := req.GetFloat("public_lb", 0)
+		exp.PublicLB = &v
+	}
+	exp.DataVersion = req.GetInt("data_version", 0)
+	if exp.DataVersion < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid data_version %d: must not be negative", exp.DataVersion)), nil
+	}
+
+	return h.withStoreLock(func(s *store.Store) *mcp.CallToolResult {
+		return writeNewExperiment(s, exp, metric, patch, warnings)
+	}), nil
+}
+
+// writeNewExperiment assigns the next ID to exp, fills in its baseline and
+// records it in the index and changelog. The caller holds the store lock so
+// the ID cannot be taken by another writer in between. A non-empty patch is
+// saved as the experiment's git diff. warnings collected before the write
+// are reported with the result.
+func writeNewExperiment(s *store.Store, exp model.Experiment, metric model.MetricDef, patch []byte, warnings []string) *mcp.CallToolResult {
+	for _, pid := range exp.Parents {
+		parent, err := s.ReadExperiment(pid)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("parent experiment %s not found", pid))
+		}
+		exp.ChangesFrom = util.AddParamChanges(exp.ChangesFrom, pid, parent.Params, exp.Params)
+	}
+	for eid := range exp.Reasoning.Evidence {
+		if _, err := s.ReadExperiment(eid); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("evidence experiment %s not found", eid))
+		}
+	}
+
+	id, err := s.NextExperimentID()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to generate ID: %v", err))
+	}
+	exp.ID = id
+
+	if len(patch) > 0 {
+		a, err := s.WriteGitDiff(id, patch)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save git diff: %v", err))
+		}
+		exp.Artifacts = append(exp.Artifacts, a)
+	}
 
 	// Compute delta relative to best parent or current best
 	if len(exp.Parents) > 0 {
-		if parent, err := h.store.ReadExperiment(exp.Parents[0]); err == nil {
-			exp.Metric.Baseline = parent.Metric.Value
-			exp.Metric.Delta = exp.Metric.Value - parent.Metric.Value
+		if parent, err := s.ReadExperiment(exp.Parents[0]); err == nil {
+			exp.Metric.Baseline = parent.PrimaryValue(metric)
+			exp.Metric.Delta = exp.Metric.Value - exp.Metric.Baseline
 		} else {
 			warnings = append(warnings, fmt.Sprintf("could not compute baseline from parent: %v", err))
 		}
 	} else {
-		curIdx, err := h.store.ReadIndex()
+		curIdx, err := s.ReadIndex()
 		if err != nil {
 			warnings = append(warnings, fmt.Sprintf("could not compute baseline from index: %v", err))
 		} else {
@@ -453,15 +902,15 @@ func (h *handlers) logExperiment(_ context.Context, req mcp.CallToolRequest) (*m
 		}
 	}
 
-	if err := h.store.WriteExperiment(exp); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to write experiment: %v", err)), nil
+	if err := s.WriteExperiment(exp); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write experiment: %v", err))
 	}
 
-	if _, err := idx.UpdateIncremental(h.store, exp); err != nil {
+	if _, err := idx.UpdateIncremental(s, exp); err != nil {
 		warnings = append(warnings, fmt.Sprintf("index update failed: %v", err))
 	}
 
-	if err := h.store.AppendChangelog(model.ChangelogEntry{
+	if err := s.AppendChangelog(model.ChangelogEntry{
 		Action:  "exp_logged",
 		ID:      id,
 		Summary: format.ExperimentOneLiner(exp),
@@ -469,9 +918,9 @@ func (h *handlers) logExperiment(_ context.Context, req mcp.CallToolRequest) (*m
 		warnings = append(warnings, fmt.Sprintf("changelog append failed: %v", err))
 	}
 
-	result := fmt.Sprintf("Logged experiment %s (%s = %.4f, %s)", id, proj.Metric.Name, metricVal, status)
+	result := fmt.Sprintf("Logged experiment %s (%s = %.4f, %s)", id, metric.Name, exp.Metric.Value, exp.Status)
 	result += formatWarnings(warnings)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultText(result)
 }
 
 func (h *handlers) addLearning(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -596,9 +1045,17 @@ func (h *handlers) updatePinned(_ context.Context, req mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultError("missing value"), nil
 	}
 
-	index, err := h.store.ReadIndex()
+	return h.withStoreLock(func(s *store.Store) *mcp.CallToolResult {
+		return applyPinnedUpdate(s, field, action, value)
+	}), nil
+}
+
+// applyPinnedUpdate performs the read-modify-write of the pinned index. The
+// caller holds the store lock.
+func applyPinnedUpdate(s *store.Store, field, action, value string) *mcp.CallToolResult {
+	index, err := s.ReadIndex()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to read index: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read index: %v", err))
 	}
 
 	p := &index.Pinned
@@ -619,20 +1076,20 @@ func (h *handlers) updatePinned(_ context.Context, req mcp.CallToolRequest) (*mc
 		} else {
 			p.Notes += "\n" + value
 		}
-		if err := h.store.WriteIndex(index); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to write index: %v", err)), nil
+		if err := s.WriteIndex(index); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to write index: %v", err))
 		}
 		var warnings []string
-		if err := h.store.AppendChangelog(model.ChangelogEntry{
+		if err := s.AppendChangelog(model.ChangelogEntry{
 			Action:  "pinned_updated",
 			Summary: "notes updated",
 		}); err != nil {
 			warnings = append(warnings, fmt.Sprintf("changelog append failed: %v", err))
 		}
 		result := "Updated notes." + formatWarnings(warnings)
-		return mcp.NewToolResultText(result), nil
+		return mcp.NewToolResultText(result)
 	default:
-		return mcp.NewToolResultError(fmt.Sprintf("unknown field: %s. Use: do_not_try, deferred, data_warnings, critical_features, notes", field)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("unknown field: %s. Use: do_not_try, deferred, data_warnings, critical_features, notes", field))
 	}
 
 	switch action {
@@ -658,15 +1115,15 @@ func (h *handlers) updatePinned(_ context.Context, req mcp.CallToolRequest) (*mc
 	case "set":
 		*target = []string{value}
 	default:
-		return mcp.NewToolResultError(fmt.Sprintf("unknown action: %s. Use: add, remove, set", action)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("unknown action: %s. Use: add, remove, set", action))
 	}
 
-	if err := h.store.WriteIndex(index); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to write index: %v", err)), nil
+	if err := s.WriteIndex(index); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write index: %v", err))
 	}
 
 	var warnings []string
-	if err := h.store.AppendChangelog(model.ChangelogEntry{
+	if err := s.AppendChangelog(model.ChangelogEntry{
 		Action:  "pinned_updated",
 		Summary: fmt.Sprintf("%s %s: %s", action, field, value),
 	}); err != nil {
@@ -675,7 +1132,7 @@ func (h *handlers) updatePinned(_ context.Context, req mcp.CallToolRequest) (*mc
 
 	result := fmt.Sprintf("Updated pinned.%s (%s: %s)", field, action, value)
 	result += formatWarnings(warnings)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultText(result)
 }
 
 func (h *handlers) getPrelude(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -695,7 +1152,8 @@ func (h *handlers) getPrelude(_ context.Context, req mcp.CallToolRequest) (*mcp.
 	// best-effort: index may not exist yet, fields default to zero values
 	index, _ := h.store.ReadIndex()
 
-	fmt.Fprintf(&b, "Project: %s | Task: %s | Metric: %s (%s)\n", proj.Name, proj.TaskType, proj.Metric.Name, proj.Metric.Direction)
+	metric := proj.PrimaryMetric()
+	fmt.Fprintf(&b, "Project: %s | Task: %s | Metric: %s (%s)\n", proj.Name, proj.TaskType, metric.Name, metric.Direction)
 	c := index.Computed
 	if c.BestExperiment != "" && c.BestMetric != nil {
 		fmt.Fprintf(&b, "Best: %s (%s = %.4f)\n", c.BestExperiment, c.BestMetric.Name, c.BestMetric.Value)
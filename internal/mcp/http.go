@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+const (
+	// HTTPPath serves the streamable HTTP transport.
+	HTTPPath = "/mcp"
+	// SSEPath and SSEMessagePath serve the legacy SSE transport.
+	SSEPath        = "/sse"
+	SSEMessagePath = "/message"
+)
+
+// ServeHTTP serves streamable HTTP and SSE clients on addr until ctx is
+// cancelled. All clients share one server, so writes are serialized by a
+// single lock.
+func ServeHTTP(ctx context.Context, s *store.Store, opts ServeOptions, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	handler, err := NewHTTPHandler(ctx, s, opts)
+	if err != nil {
+		return err
+	}
+
+	httpSrv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- httpSrv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NewHTTPHandler returns the HTTP handler behind ServeHTTP. The filesystem
+// watcher runs until ctx is cancelled.
+func NewHTTPHandler(ctx context.Context, s *store.Store, opts ServeOptions) (http.Handler, error) {
+	srv, h := newServer(s, opts)
+	if err := checkToolPatterns(srv, opts.Tools); err != nil {
+		return nil, err
+	}
+	h.startWatcher(ctx, srv, opts.WatchInterval)
+
+	streamable := server.NewStreamableHTTPServer(srv, server.WithEndpointPath(HTTPPath))
+	sse := server.NewSSEServer(srv,
+		server.WithSSEEndpoint(SSEPath),
+		server.WithMessageEndpoint(SSEMessagePath),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(HTTPPath, h.subscriptionHandler(streamable,
+		func(r *http.Request) string { return r.Header.Get(server.HeaderKeySessionID) },
+		func(w http.ResponseWriter, _ string, resp mcp.JSONRPCMessage) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		},
+	))
+	mux.Handle(SSEPath, sse.SSEHandler())
+	mux.Handle(SSEMessagePath, h.subscriptionHandler(sse.MessageHandler(),
+		func(r *http.Request) string { return r.URL.Query().Get("sessionId") },
+		func(w http.ResponseWriter, sessionID string, resp mcp.JSONRPCMessage) {
+			// SSE replies travel on the event stream, not the POST response.
+			if err := sse.SendEventToSession(sessionID, resp); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		},
+	))
+
+	return bearerAuth(opts.Token, mux), nil
+}
+
+// subscriptionHandler answers resources/subscribe and resources/unsubscribe
+// POSTs itself and passes every other request through to next.
+func (h *handlers) subscriptionHandler(
+	next http.Handler,
+	sessionID func(*http.Request) string,
+	reply func(w http.ResponseWriter, sessionID string, resp mcp.JSONRPCMessage),
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "reading request body failed", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		id := sessionID(r)
+		if id != "" {
+			if resp, ok := h.handleSubscription(id, body); ok {
+				reply(w, id, resp)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerAuth rejects requests without "Authorization: Bearer <token>". An
+// empty token disables the check.
+func bearerAuth(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(strings.TrimSpace(r.Header.Get("Authorization")))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="marrow"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
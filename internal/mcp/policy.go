@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// writeTools are the tools that modify .marrow/.
+var writeTools = map[string]bool{
+	"log_experiment":      true,
+	"add_learning":        true,
+	"add_graveyard_entry": true,
+	"update_pinned":       true,
+	"create_snapshot":     true,
+	"restore_snapshot":    true,
+	"delete_snapshot":     true,
+}
+
+// toolPolicy decides which tools a server exposes. Disallowed tools stay
+// registered but are hidden from tools/list, so a call from a client with a
+// stale tool list gets a clear error rather than "tool not found".
+type toolPolicy struct {
+	readOnly bool
+	allow    []string // tool names or path.Match globs; empty allows all
+}
+
+// blocked returns why a tool is disabled, or "" if it may be called.
+func (p toolPolicy) blocked(name string) string {
+	if p.readOnly && writeTools[name] {
+		return "server is read-only"
+	}
+	if len(p.allow) == 0 {
+		return ""
+	}
+	for _, pattern := range p.allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return ""
+		}
+	}
+	return "not in the tool allowlist"
+}
+
+func (p toolPolicy) filter(_ context.Context, tools []mcp.Tool) []mcp.Tool {
+	var out []mcp.Tool
+	for _, t := range tools {
+		if p.blocked(t.Name) == "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// enforce rejects calls to blocked tools. Blocked write attempts are recorded
+// in the changelog so a maintainer can see which agent tried what.
+func (h *handlers) enforce(p toolPolicy) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := req.Params.Name
+			reason := p.blocked(name)
+			if reason == "" {
+				return next(ctx, req)
+			}
+
+			msg := fmt.Sprintf("tool %s is disabled: %s", name, reason)
+			if writeTools[name] {
+				if err := h.store.AppendChangelog(model.ChangelogEntry{
+					Action:  "tool_blocked",
+					ID:      name,
+					Summary: reason,
+				}); err != nil {
+					msg += formatWarnings([]string{fmt.Sprintf("changelog append failed: %v", err)})
+				}
+			}
+			return mcp.NewToolResultError(msg), nil
+		}
+	}
+}
+
+// checkToolPatterns rejects allowlist entries that are malformed or match no
+// registered tool, which usually means a typo.
+func checkToolPatterns(srv *server.MCPServer, patterns []string) error {
+	var names []string
+	for name := range srv.ListTools() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+		matched := false
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("tool pattern %q matches no tools. Available: %s", pattern, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
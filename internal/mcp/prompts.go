@@ -0,0 +1,287 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func registerPrompts(srv *server.MCPServer, h *handlers) {
+	srv.AddPrompt(
+		mcp.NewPrompt("start_session",
+			mcp.WithPromptDescription("Catch up on the project: summary, best chain, recent changes and what not to try."),
+			mcp.WithArgument("goal", mcp.ArgumentDescription("What you want to get done this session")),
+		),
+		h.startSessionPrompt,
+	)
+
+	srv.AddPrompt(
+		mcp.NewPrompt("plan_next_experiment",
+			mcp.WithPromptDescription("Propose the next experiment from the best chain, open assumptions and the graveyard."),
+			mcp.WithArgument("focus", mcp.ArgumentDescription("Area to focus on (e.g. feature engineering, tuning)")),
+		),
+		h.planNextExperimentPrompt,
+	)
+
+	srv.AddPrompt(
+		mcp.NewPrompt("postmortem_failed_run",
+			mcp.WithPromptDescription("Analyze a failed or degraded experiment and record what it taught us."),
+			mcp.WithArgument("experiment_id", mcp.ArgumentDescription("Experiment to analyze. Defaults to the most recent failed or degraded one.")),
+		),
+		h.postmortemPrompt,
+	)
+
+	srv.AddPrompt(
+		mcp.NewPrompt("write_weekly_report",
+			mcp.WithPromptDescription("Draft a progress report from recent experiments, learnings and changes."),
+			mcp.WithArgument("since", mcp.ArgumentDescription("Start date, YYYY-MM-DD. Defaults to 7 days ago.")),
+		),
+		h.weeklyReportPrompt,
+	)
+}
+
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
+
+// writeChain appends the best experiment chain as one-liners.
+func (h *handlers) writeChain(b *strings.Builder, index model.Index) {
+	if len(index.Computed.ExperimentChain) == 0 {
+		return
+	}
+	b.WriteString("\n--- Best Chain ---\n")
+	for _, id := range index.Computed.ExperimentChain {
+		if exp, err := h.store.ReadExperiment(id); err == nil {
+			fmt.Fprintf(b, "  %s\n", format.ExperimentOneLiner(exp))
+		}
+	}
+}
+
+// writeGraveyard appends failed approaches, optionally only those related to
+// the given experiment or sharing one of its tags.
+func (h *handlers) writeGraveyard(b *strings.Builder, related *model.Experiment) {
+	gf, _ := h.store.ReadGraveyard()
+	var lines []string
+	for _, g := range gf.Entries {
+		if related != nil && g.ExperimentID != related.ID && !sharesTag(g.Tags, related.Tags) {
+			continue
+		}
+		lines = append(lines, format.GraveyardOneLiner(g))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	b.WriteString("\n--- Graveyard ---\n")
+	for _, l := range lines {
+		fmt.Fprintf(b, "  %s\n", l)
+	}
+}
+
+func (h *handlers) writeLearnings(b *strings.Builder, proven, assumptions bool) {
+	lf, _ := h.store.ReadLearnings()
+	if proven && len(lf.Proven) > 0 {
+		b.WriteString("\n--- Proven Learnings ---\n")
+		for _, l := range lf.Proven {
+			fmt.Fprintf(b, "  %s\n", format.LearningOneLiner(l))
+		}
+	}
+	if assumptions && len(lf.Assumptions) > 0 {
+		b.WriteString("\n--- Open Assumptions ---\n")
+		for _, l := range lf.Assumptions {
+			fmt.Fprintf(b, "  %s (%s)\n", format.LearningOneLiner(l), l.ID)
+		}
+	}
+}
+
+func sharesTag(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *handlers) startSessionPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	summary, err := h.projectSummaryText()
+	if err != nil {
+		return nil, err
+	}
+	index, _ := h.store.ReadIndex()
+
+	var b strings.Builder
+	b.WriteString("You are resuming work on a research project tracked in marrow. Here is where things stand.\n\n")
+	b.WriteString(summary)
+	h.writeChain(&b, index)
+	h.writeLearnings(&b, true, false)
+
+	cl, _ := h.store.ReadChangelog()
+	if n := len(cl.Entries); n > 0 {
+		b.WriteString("\n--- Recent Changes ---\n")
+		for _, e := range cl.Entries[max(0, n-10):] {
+			fmt.Fprintf(&b, "  %s\n", format.ChangelogOneLiner(e))
+		}
+	}
+
+	b.WriteString("\nRead the above before doing anything. Do not repeat anything under Do Not Try. ")
+	b.WriteString("Log every experiment you run with log_experiment, and record findings with add_learning or add_graveyard_entry.\n")
+	if goal := req.Params.Arguments["goal"]; goal != "" {
+		fmt.Fprintf(&b, "\nGoal for this session: %s\n", goal)
+	}
+	return promptResult("Project briefing for a new session", b.String()), nil
+}
+
+func (h *handlers) planNextExperimentPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	summary, err := h.projectSummaryText()
+	if err != nil {
+		return nil, err
+	}
+	index, _ := h.store.ReadIndex()
+
+	var b strings.Builder
+	b.WriteString(summary)
+	h.writeChain(&b, index)
+	h.writeLearnings(&b, true, true)
+	h.writeGraveyard(&b, nil)
+
+	b.WriteString("\nPropose the next experiment. Give:\n")
+	b.WriteString("  1. The parent experiment it builds on (usually the end of the best chain)\n")
+	b.WriteString("  2. The single change you would make, and the hypothesis behind it\n")
+	b.WriteString("  3. Which open assumption it tests, if any\n")
+	b.WriteString("  4. The metric change you expect, and what result would make you abandon the idea\n")
+	b.WriteString("Do not propose anything listed under Do Not Try or already in the graveyard.\n")
+	if focus := req.Params.Arguments["focus"]; focus != "" {
+		fmt.Fprintf(&b, "Focus on: %s\n", focus)
+	}
+	return promptResult("Plan the next experiment", b.String()), nil
+}
+
+func (h *handlers) postmortemPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id := req.Params.Arguments["experiment_id"]
+	if id == "" {
+		exps, err := h.store.ListExperiments()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list experiments: %w", err)
+		}
+		for i := len(exps) - 1; i >= 0; i-- {
+			if exps[i].Status == "failed" || exps[i].Status == "degraded" {
+				id = exps[i].ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, fmt.Errorf("no failed or degraded experiments; pass experiment_id")
+		}
+	}
+
+	exp, err := h.store.ReadExperiment(id)
+	if err != nil {
+		return nil, fmt.Errorf("experiment %s not found", id)
+	}
+	y, err := format.MarshalYAMLString(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Run a postmortem on experiment %s.\n\n--- Experiment ---\n%s", id, y)
+	if len(exp.Parents) > 0 {
+		b.WriteString("\n--- Parents ---\n")
+		for _, pid := range exp.Parents {
+			if p, err := h.store.ReadExperiment(pid); err == nil {
+				fmt.Fprintf(&b, "  %s\n", format.ExperimentOneLiner(p))
+			}
+		}
+	}
+	h.writeGraveyard(&b, &exp)
+	h.writeLearnings(&b, true, true)
+
+	index, _ := h.store.ReadIndex()
+	if len(index.Pinned.DoNotTry) > 0 {
+		b.WriteString("\n--- Do Not Try ---\n")
+		for _, d := range index.Pinned.DoNotTry {
+			fmt.Fprintf(&b, "  - %s\n", d)
+		}
+	}
+
+	b.WriteString("\nExplain the most likely reason this run underperformed its parents, citing the evidence above. Then:\n")
+	b.WriteString("  - If the approach is a dead end, record it with add_graveyard_entry (experiment_id " + id + ")\n")
+	b.WriteString("  - If it confirms or refutes an open assumption, say which, and record it with add_learning\n")
+	b.WriteString("  - If it should never be retried, suggest an update_pinned do_not_try entry\n")
+	return promptResult("Postmortem for "+id, b.String()), nil
+}
+
+func (h *handlers) weeklyReportPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	since := time.Now().UTC().AddDate(0, 0, -7).Truncate(24 * time.Hour)
+	if s := req.Params.Arguments["since"]; s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since date, use YYYY-MM-DD")
+		}
+		since = t
+	}
+
+	summary, err := h.projectSummaryText()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(summary)
+
+	exps, _ := h.store.ListExperiments()
+	fmt.Fprintf(&b, "\n--- Experiments since %s ---\n", since.Format("2006-01-02"))
+	n := 0
+	for _, e := range exps {
+		if !e.Timestamp.Before(since) {
+			fmt.Fprintf(&b, "  %s\n", format.ExperimentOneLiner(e))
+			n++
+		}
+	}
+	if n == 0 {
+		b.WriteString("  (none)\n")
+	}
+
+	lf, _ := h.store.ReadLearnings()
+	var learned []model.Learning
+	for _, l := range append(lf.Proven, lf.Assumptions...) {
+		if !l.Timestamp.Before(since) {
+			learned = append(learned, l)
+		}
+	}
+	if len(learned) > 0 {
+		b.WriteString("\n--- New Learnings ---\n")
+		for _, l := range learned {
+			fmt.Fprintf(&b, "  %s\n", format.LearningOneLiner(l))
+		}
+	}
+
+	gf, _ := h.store.ReadGraveyard()
+	var buried []model.GraveyardEntry
+	for _, g := range gf.Entries {
+		if !g.Timestamp.Before(since) {
+			buried = append(buried, g)
+		}
+	}
+	if len(buried) > 0 {
+		b.WriteString("\n--- New Graveyard Entries ---\n")
+		for _, g := range buried {
+			fmt.Fprintf(&b, "  %s\n", format.GraveyardOneLiner(g))
+		}
+	}
+
+	b.WriteString("\nWrite a weekly report for stakeholders in Markdown with these sections: ")
+	b.WriteString("Headline result, What we tried, What we learned, What failed, Next steps. ")
+	b.WriteString("Quote metric values exactly as given above and do not invent results.\n")
+	return promptResult("Weekly report since "+since.Format("2006-01-02"), b.String()), nil
+}
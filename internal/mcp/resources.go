@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/format"
+)
+
+const (
+	resourceScheme    = "marrow://"
+	experimentsPrefix = resourceScheme + "experiments/"
+	contextPrefix     = resourceScheme + "context/"
+	yamlMIME          = "application/yaml"
+)
+
+// resourceSet tracks which per-file resources (experiments and context files)
+// are currently registered, so syncResources only touches what changed.
+type resourceSet struct {
+	mu   sync.Mutex
+	desc map[string]string // uri → description
+}
+
+func registerResources(srv *server.MCPServer, h *handlers) {
+	srv.AddResource(
+		mcp.NewResource(resourceScheme+"learnings", "learnings",
+			mcp.WithResourceDescription("Proven findings and open assumptions"),
+			mcp.WithMIMEType(yamlMIME),
+		),
+		h.readLearningsResource,
+	)
+	srv.AddResource(
+		mcp.NewResource(resourceScheme+"graveyard", "graveyard",
+			mcp.WithResourceDescription("Failed approaches and why they failed"),
+			mcp.WithMIMEType(yamlMIME),
+		),
+		h.readGraveyardResource,
+	)
+	srv.AddResource(
+		mcp.NewResource(resourceScheme+"index", "index",
+			mcp.WithResourceDescription("Computed index (best experiment, chain) and pinned guardrails"),
+			mcp.WithMIMEType(yamlMIME),
+		),
+		h.readIndexResource,
+	)
+
+	srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(experimentsPrefix+"{id}", "experiment",
+			mcp.WithTemplateDescription("A single experiment record (e.g. marrow://experiments/exp_001)"),
+			mcp.WithTemplateMIMEType(yamlMIME),
+		),
+		h.readExperimentResource,
+	)
+	srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(contextPrefix+"{name}", "context",
+			mcp.WithTemplateDescription("A named context file (e.g. marrow://context/eda)"),
+			mcp.WithTemplateMIMEType(yamlMIME),
+		),
+		h.readContextResource,
+	)
+
+	h.syncResources(srv)
+}
+
+// syncResources registers one concrete resource per experiment and context
+// file in .marrow/ and drops the ones whose files are gone.
+func (h *handlers) syncResources(srv *server.MCPServer) {
+	want := make(map[string]server.ServerResource)
+
+	if exps, err := h.store.ListExperiments(); err == nil {
+		for _, e := range exps {
+			uri := experimentsPrefix + e.ID
+			want[uri] = server.ServerResource{
+				Resource: mcp.NewResource(uri, e.ID,
+					mcp.WithResourceDescription(format.ExperimentOneLiner(e)),
+					mcp.WithMIMEType(yamlMIME),
+				),
+				Handler: h.readExperimentResource,
+			}
+		}
+	}
+	if names, err := h.store.ListContextFiles(); err == nil {
+		for _, name := range names {
+			uri := contextPrefix + name
+			want[uri] = server.ServerResource{
+				Resource: mcp.NewResource(uri, "context/"+name,
+					mcp.WithResourceDescription(fmt.Sprintf("Context file %s.yaml", name)),
+					mcp.WithMIMEType(yamlMIME),
+				),
+				Handler: h.readContextResource,
+			}
+		}
+	}
+
+	h.resources.mu.Lock()
+	defer h.resources.mu.Unlock()
+
+	var stale []string
+	for uri := range h.resources.desc {
+		if _, ok := want[uri]; !ok {
+			stale = append(stale, uri)
+		}
+	}
+	var added []server.ServerResource
+	for uri, r := range want {
+		if desc, ok := h.resources.desc[uri]; !ok || desc != r.Resource.Description {
+			added = append(added, r)
+		}
+	}
+
+	if len(stale) > 0 {
+		srv.DeleteResources(stale...)
+	}
+	if len(added) > 0 {
+		srv.AddResources(added...)
+	}
+
+	h.resources.desc = make(map[string]string, len(want))
+	for uri, r := range want {
+		h.resources.desc[uri] = r.Resource.Description
+	}
+}
+
+func yamlContents(uri, text string) []mcp.ResourceContents {
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: yamlMIME, Text: text},
+	}
+}
+
+func (h *handlers) readExperimentResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id := strings.TrimPrefix(req.Params.URI, experimentsPrefix)
+	exp, err := h.store.ReadExperiment(id)
+	if err != nil {
+		return nil, fmt.Errorf("experiment %s not found: %w", id, err)
+	}
+	text, err := format.MarshalYAMLString(exp)
+	if err != nil {
+		return nil, err
+	}
+	return yamlContents(req.Params.URI, text), nil
+}
+
+func (h *handlers) readContextResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	name := strings.TrimPrefix(req.Params.URI, contextPrefix)
+	raw, err := h.store.ReadContextRaw(name)
+	if err != nil {
+		return nil, err
+	}
+	return yamlContents(req.Params.URI, raw), nil
+}
+
+func (h *handlers) readLearningsResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	lf, err := h.store.ReadLearnings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read learnings: %w", err)
+	}
+	text, err := format.MarshalYAMLString(lf)
+	if err != nil {
+		return nil, err
+	}
+	return yamlContents(req.Params.URI, text), nil
+}
+
+func (h *handlers) readGraveyardResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	gf, err := h.store.ReadGraveyard()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graveyard: %w", err)
+	}
+	text, err := format.MarshalYAMLString(gf)
+	if err != nil {
+		return nil, err
+	}
+	return yamlContents(req.Params.URI, text), nil
+}
+
+func (h *handlers) readIndexResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	idx, err := h.store.ReadIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	text, err := format.MarshalYAMLString(idx)
+	if err != nil {
+		return nil, err
+	}
+	return yamlContents(req.Params.URI, text), nil
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+// ServeOptions configures a running MCP server.
+type ServeOptions struct {
+	// WatchInterval is how often .marrow/ is polled for outside changes.
+	// Zero means DefaultWatchInterval; a negative value disables watching.
+	WatchInterval time.Duration
+
+	// ReadOnly disables the tools that modify .marrow/.
+	ReadOnly bool
+
+	// Tools, if non-empty, is an allowlist of tool names or globs such as
+	// "get_*". Tools outside it are hidden and refuse calls.
+	Tools []string
+
+	// Token, if set, is the bearer token HTTP clients must present.
+	Token string
+}
+
+// Serve runs the server over stdin/stdout until the input closes or the
+// process is interrupted.
+func Serve(s *store.Store, opts ServeOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return ServeIO(ctx, s, opts, os.Stdin, os.Stdout)
+}
+
+// ServeIO runs a single stdio-style session over in and out until in is
+// exhausted or ctx is cancelled.
+func ServeIO(ctx context.Context, s *store.Store, opts ServeOptions, in io.Reader, out io.Writer) error {
+	srv, h := newServer(s, opts)
+	if err := checkToolPatterns(srv, opts.Tools); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	h.startWatcher(ctx, srv, opts.WatchInterval)
+
+	w := &lockedWriter{w: out}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(h.filterSubscriptions("stdio", in, pw, w))
+	}()
+
+	stdio := server.NewStdioServer(srv)
+	stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+	return stdio.Listen(ctx, pr, w)
+}
+
+func (h *handlers) startWatcher(ctx context.Context, srv *server.MCPServer, interval time.Duration) {
+	if interval < 0 {
+		return
+	}
+	if interval == 0 {
+		interval = DefaultWatchInterval
+	}
+	// Take the baseline scan now so changes made right after startup are seen.
+	go h.watch(ctx, srv, newWatcher(h.store.Root()), interval)
+}
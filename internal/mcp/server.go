@@ -1,23 +1,49 @@
 package mcp
 
 import (
+	"context"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/rzzdr/marrow/internal/store"
 )
 
 func NewServer(s *store.Store) *server.MCPServer {
-	srv := server.NewMCPServer(
+	srv, _ := newServer(s, ServeOptions{})
+	return srv
+}
+
+func newServer(s *store.Store, opts ServeOptions) (*server.MCPServer, *handlers) {
+	h := &handlers{store: s}
+	policy := toolPolicy{readOnly: opts.ReadOnly, allow: opts.Tools}
+
+	// Refresh the per-file resources before each listing so resources/list
+	// always reflects what is in .marrow/.
+	var srv *server.MCPServer
+	hooks := &server.Hooks{}
+	hooks.AddBeforeListResources(func(context.Context, any, *mcp.ListResourcesRequest) {
+		h.syncResources(srv)
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		h.subs.drop(session.SessionID())
+	})
+
+	srv = server.NewMCPServer(
 		"marrow",
 		"0.1.0",
 		server.WithToolCapabilities(false),
-		server.WithResourceCapabilities(false, false),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(hooks),
+		server.WithToolFilter(policy.filter),
+		server.WithToolHandlerMiddleware(h.enforce(policy)),
 		server.WithInstructions(`Marrow is a structured knowledge base for AI research experiments.
 Use get_project_summary for a quick overview. Escalate to deeper tools only when needed.
 Prefer summary depth for listings, full depth only for specific experiments.`),
 	)
 
-	h := &handlers{store: s}
+	registerResources(srv, h)
+	registerPrompts(srv, h)
 
 	srv.AddTool(
 		mcp.NewTool("get_project_summary",
@@ -43,6 +69,16 @@ Prefer summary depth for listings, full depth only for specific experiments.`),
 		h.getExperiment,
 	)
 
+	srv.AddTool(
+		mcp.NewTool("get_experiment_curve",
+			mcp.WithDescription("Get an experiment's per-step curve for a metric: best and final step, the gap between them, whether it plateaued or is declining, and a downsampled step,value series."),
+			mcp.WithString("id", mcp.Required(), mcp.Description("Experiment ID (e.g. exp_001)")),
+			mcp.WithString("metric", mcp.Description("Metric the curve tracks (default: the primary metric)")),
+			mcp.WithNumber("points", mcp.Description("Return at most this many points, spread over the curve; first, last and best are always kept"), mcp.DefaultNumber(50)),
+		),
+		h.getExperimentCurve,
+	)
+
 	srv.AddTool(
 		mcp.NewTool("get_learnings",
 			mcp.WithDescription("Get proven findings and/or assumptions."),
@@ -84,6 +120,25 @@ Prefer summary depth for listings, full depth only for specific experiments.`),
 		h.getExperimentChain,
 	)
 
+	srv.AddTool(
+		mcp.NewTool("get_experiment_graph",
+			mcp.WithDescription("Get the experiment DAG with every branch, not just the best chain. Nodes carry the primary metric and status; chain nodes and edges are marked."),
+			mcp.WithString("format", mcp.Description("mermaid|dot|json"), mcp.DefaultString("mermaid")),
+			mcp.WithString("tags", mcp.Description("Comma-separated tags; keep experiments with any of them")),
+			mcp.WithString("root", mcp.Description("Keep only this experiment and its descendants")),
+			mcp.WithString("ancestors", mcp.Description("Keep only this experiment and its ancestors")),
+		),
+		h.getExperimentGraph,
+	)
+
+	srv.AddTool(
+		mcp.NewTool("get_pareto_front",
+			mcp.WithDescription("Get the non-dominated experiments across all declared metrics (multi-objective projects)."),
+			mcp.WithString("depth", mcp.Description("summary|standard|full"), mcp.DefaultString("summary")),
+		),
+		h.getParetoFront,
+	)
+
 	srv.AddTool(
 		mcp.NewTool("get_experiments_by_tag",
 			mcp.WithDescription("Get experiments matching specific tags."),
@@ -93,15 +148,44 @@ Prefer summary depth for listings, full depth only for specific experiments.`),
 		h.getExperimentsByTag,
 	)
 
+	srv.AddTool(
+		mcp.NewTool("query_experiments",
+			mcp.WithDescription("Find experiments with a query expression, e.g. 'model=xgboost and metric>0.85 and tag:feature_eng and since:2026-09-01'. Supports = != < <= > >= ~ (substring), and/or/not, parentheses, and bare words or quoted strings for text search over notes and reasoning."),
+			mcp.WithString("query", mcp.Required(), mcp.Description("Query expression. Fields: id, model, status, notes, reasoning, reasoning_type, tag, parent, metric, delta, local_cv, public_lb, data_version, timestamp, python, gpu, data_hash, split_seed, git_commit, git_branch, pkg.<name>, secondary metric names. Shorthands: tag:x since:DATE until:DATE text:x")),
+			mcp.WithString("sort", mcp.Description("Field to sort by; prefix with - for descending, or 'best' for best primary metric first")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of experiments to return (first N when sorted, else most recent). 0 = all.")),
+			mcp.WithString("depth", mcp.Description("summary|standard|full"), mcp.DefaultString("summary")),
+		),
+		h.queryExperiments,
+	)
+
 	srv.AddTool(
 		mcp.NewTool("compare_experiments",
-			mcp.WithDescription("Compare two experiments side by side."),
+			mcp.WithDescription("Compare two experiments side by side, with a delta per declared metric."),
 			mcp.WithString("id1", mcp.Required(), mcp.Description("First experiment ID")),
 			mcp.WithString("id2", mcp.Required(), mcp.Description("Second experiment ID")),
 		),
 		h.compareExperiments,
 	)
 
+	srv.AddTool(
+		mcp.NewTool("check_comparability",
+			mcp.WithDescription("Group experiments by the data they ran on (data version, data hash, split seed). Scores are only comparable within a group."),
+			mcp.WithString("ids", mcp.Description("Comma-separated experiment IDs to check. Empty = all experiments.")),
+		),
+		h.checkComparability,
+	)
+
+	srv.AddTool(
+		mcp.NewTool("analyze_params",
+			mcp.WithDescription("Rank hyperparameters by how much they move the metric: per-value metric distribution, mean delta when changed between parent and child, and an importance score (0-1)."),
+			mcp.WithString("metric", mcp.Description("Metric to analyze. Empty = primary metric.")),
+			mcp.WithString("depth", mcp.Description("summary (scores only) | standard (+ per-value stats) | full (+ every change)"), mcp.DefaultString("summary")),
+			mcp.WithString("format", mcp.Description("text|yaml"), mcp.DefaultString("text")),
+		),
+		h.analyzeParams,
+	)
+
 	srv.AddTool(
 		mcp.NewTool("get_all_experiments",
 			mcp.WithDescription("Get all experiments. Can be expensive. Use depth=summary to minimize tokens."),
@@ -117,9 +201,22 @@ Prefer summary depth for listings, full depth only for specific experiments.`),
 			mcp.WithString("base_model", mcp.Description("Model family (e.g. xgboost, resnet)")),
 			mcp.WithString("parents", mcp.Description("Comma-separated parent experiment IDs")),
 			mcp.WithNumber("metric_value", mcp.Required(), mcp.Description("Primary metric value")),
+			mcp.WithString("metrics", mcp.Description("Secondary metric values declared in marrow.yaml (e.g. f1=0.71,latency_ms=12)")),
 			mcp.WithString("status", mcp.Required(), mcp.Description("improved|degraded|neutral|failed")),
 			mcp.WithString("tags", mcp.Description("Comma-separated tags")),
 			mcp.WithString("notes", mcp.Description("Freeform notes about this experiment")),
+			mcp.WithObject("params", mcp.Description("Hyperparameters/config as a JSON object; nested keys are flattened with dots. Changes from each parent are computed from its params.")),
+			mcp.WithArray("changes", mcp.WithStringItems(), mcp.Description("Changes from the parent, e.g. param:lr:0.1->0.01, added:target_encoding, removed:dropout, changed:optimizer:adam->sgd. Prefix with the parent ID (exp_002:...) when there are several parents.")),
+			mcp.WithString("reasoning", mcp.Description("Why this experiment was run")),
+			mcp.WithString("reasoning_type", mcp.Description("proven|assumption|unknown (default unknown when reasoning is given)")),
+			mcp.WithArray("evidence", mcp.WithStringItems(), mcp.Description("Supporting observations from earlier experiments, e.g. exp_003=loss diverged at lr 0.1")),
+			mcp.WithNumber("local_cv", mcp.Description("Local cross-validation score")),
+			mcp.WithNumber("public_lb", mcp.Description("Public leaderboard score")),
+			mcp.WithNumber("data_version", mcp.Description("Version of the dataset used")),
+			mcp.WithString("environment", mcp.Description("Comma-separated key=value pairs: python, gpu, data_hash, split_seed, preprocessing_hash, git_commit, git_branch; other keys are package versions (e.g. python=3.11,gpu=A100,torch=2.3)")),
+			mcp.WithBoolean("capture_env", mcp.Description("Record python, package and GPU versions from the server's environment and hash the data paths in marrow.yaml. Values in environment win.")),
+			mcp.WithBoolean("git", mcp.Description("Record the git commit, branch and dirty state of the project's work tree (default true)")),
+			mcp.WithBoolean("git_diff", mcp.Description("Also save uncommitted changes to tracked files as a diff artifact")),
 		),
 		h.logExperiment,
 	)
@@ -155,6 +252,45 @@ Prefer summary depth for listings, full depth only for specific experiments.`),
 		h.updatePinned,
 	)
 
+	srv.AddTool(
+		mcp.NewTool("create_snapshot",
+			mcp.WithDescription("Checkpoint the whole .marrow/ state. Do this before a risky batch of edits."),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Snapshot name (timestamp is prefixed automatically)")),
+		),
+		h.createSnapshot,
+	)
+
+	srv.AddTool(
+		mcp.NewTool("list_snapshots",
+			mcp.WithDescription("List available snapshots, oldest first."),
+		),
+		h.listSnapshots,
+	)
+
+	srv.AddTool(
+		mcp.NewTool("diff_snapshot",
+			mcp.WithDescription("Show experiments, learnings, graveyard and pinned entries added, removed or changed since a snapshot."),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Snapshot name (full or as given at creation)")),
+		),
+		h.diffSnapshot,
+	)
+
+	srv.AddTool(
+		mcp.NewTool("restore_snapshot",
+			mcp.WithDescription("Restore .marrow/ to a snapshot. The current state is snapshotted first so the restore can be undone."),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Snapshot name (full or as given at creation)")),
+		),
+		h.restoreSnapshot,
+	)
+
+	srv.AddTool(
+		mcp.NewTool("delete_snapshot",
+			mcp.WithDescription("Delete a snapshot."),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Snapshot name (full or as given at creation)")),
+		),
+		h.deleteSnapshot,
+	)
+
 	srv.AddTool(
 		mcp.NewTool("get_prelude",
 			mcp.WithDescription("Get an optimized context blob for a given intent. Returns project summary + relevant context based on what you're trying to do."),
@@ -163,10 +299,5 @@ Prefer summary depth for listings, full depth only for specific experiments.`),
 		h.getPrelude,
 	)
 
-	return srv
-}
-
-func Serve(s *store.Store) error {
-	srv := NewServer(s)
-	return server.ServeStdio(srv)
+	return srv, h
 }
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func (h *handlers) createSnapshot(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError("missing name"), nil
+	}
+
+	fullName, err := h.store.CreateSnapshot(name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create snapshot: %v", err)), nil
+	}
+
+	var warnings []string
+	if err := h.store.AppendChangelog(model.ChangelogEntry{
+		Action:  "snapshot_created",
+		Summary: fullName,
+	}); err != nil {
+		warnings = append(warnings, fmt.Sprintf("changelog append failed: %v", err))
+	}
+
+	return mcp.NewToolResultText("Snapshot created: " + fullName + formatWarnings(warnings)), nil
+}
+
+func (h *handlers) listSnapshots(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	names, err := h.store.ListSnapshots()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list snapshots: %v", err)), nil
+	}
+	if len(names) == 0 {
+		return mcp.NewToolResultText("No snapshots."), nil
+	}
+
+	text := strings.Join(names, "\n") + "\n"
+	return toolResultWithMeta(text, format.EstimateTokens(text), "summary"), nil
+}
+
+func (h *handlers) restoreSnapshot(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError("missing name"), nil
+	}
+
+	fullName, err := h.store.ResolveSnapshot(name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	backup, err := h.store.RestoreSnapshot(fullName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to restore snapshot: %v", err)), nil
+	}
+
+	var warnings []string
+	if err := h.store.LogRestore(fullName, backup); err != nil {
+		warnings = append(warnings, fmt.Sprintf("changelog append failed: %v", err))
+	}
+
+	result := fmt.Sprintf("Restored snapshot %s (previous state saved as %s)", fullName, backup)
+	return mcp.NewToolResultText(result + formatWarnings(warnings)), nil
+}
+
+func (h *handlers) diffSnapshot(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError("missing name"), nil
+	}
+
+	diff, err := h.store.DiffSnapshot(name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to diff snapshot: %v", err)), nil
+	}
+
+	text := format.SnapshotDiffText(diff)
+	return toolResultWithMeta(text, format.EstimateTokens(text), "summary"), nil
+}
+
+func (h *handlers) deleteSnapshot(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError("missing name"), nil
+	}
+
+	fullName, err := h.store.DeleteSnapshot(name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete snapshot: %v", err)), nil
+	}
+
+	var warnings []string
+	if err := h.store.AppendChangelog(model.ChangelogEntry{
+		Action:  "snapshot_deleted",
+		Summary: fullName,
+	}); err != nil {
+		warnings = append(warnings, fmt.Sprintf("changelog append failed: %v", err))
+	}
+
+	return mcp.NewToolResultText("Deleted snapshot " + fullName + formatWarnings(warnings)), nil
+}
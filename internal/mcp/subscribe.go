@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+	methodResourcesUpdated     = "notifications/resources/updated"
+)
+
+// subscriptions records which resource URIs each client session has
+// subscribed to.
+type subscriptions struct {
+	mu        sync.Mutex
+	bySession map[string]map[string]bool
+}
+
+func (s *subscriptions) set(sessionID, uri string, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bySession == nil {
+		s.bySession = make(map[string]map[string]bool)
+	}
+	uris := s.bySession[sessionID]
+	if on {
+		if uris == nil {
+			uris = make(map[string]bool)
+			s.bySession[sessionID] = uris
+		}
+		uris[uri] = true
+		return
+	}
+	delete(uris, uri)
+	if len(uris) == 0 {
+		delete(s.bySession, sessionID)
+	}
+}
+
+func (s *subscriptions) drop(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.bySession, sessionID)
+}
+
+// notify sends resources/updated to every session subscribed to uri.
+func (s *subscriptions) notify(srv *server.MCPServer, uri string) {
+	s.mu.Lock()
+	var sessions []string
+	for id, uris := range s.bySession {
+		if uris[uri] {
+			sessions = append(sessions, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range sessions {
+		_ = srv.SendNotificationToSpecificClient(id, methodResourcesUpdated, map[string]any{"uri": uri})
+	}
+}
+
+// handleSubscription answers resources/subscribe and resources/unsubscribe,
+// which mcp-go does not route itself. It reports whether msg was one of them.
+func (h *handlers) handleSubscription(sessionID string, msg []byte) (mcp.JSONRPCMessage, bool) {
+	var req struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(msg, &req); err != nil || req.ID == nil {
+		return nil, false
+	}
+
+	id := mcp.NewRequestId(req.ID)
+	switch req.Method {
+	case methodResourcesSubscribe, methodResourcesUnsubscribe:
+	default:
+		return nil, false
+	}
+	if req.Params.URI == "" {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, "missing uri", nil), true
+	}
+	h.subs.set(sessionID, req.Params.URI, req.Method == methodResourcesSubscribe)
+	return mcp.NewJSONRPCResultResponse(id, mcp.EmptyResult{}), true
+}
+
+// lockedWriter serializes writes from the stdio server and from the
+// subscription filter so JSON-RPC lines never interleave.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// filterSubscriptions copies JSON-RPC lines from in to next, answering
+// subscription requests directly on out instead of forwarding them.
+func (h *handlers) filterSubscriptions(sessionID string, in io.Reader, next io.Writer, out io.Writer) error {
+	r := bufio.NewReader(in)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if resp, ok := h.handleSubscription(sessionID, line); ok {
+				data, merr := json.Marshal(resp)
+				if merr != nil {
+					return merr
+				}
+				if _, werr := out.Write(append(data, '\n')); werr != nil {
+					return werr
+				}
+			} else if _, werr := next.Write(line); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
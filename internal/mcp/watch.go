@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultWatchInterval is how often a running server polls .marrow/ for
+// changes made by other processes, such as a CLI "marrow exp new".
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// watchedDirs are the parts of .marrow/ that back MCP resources, relative to
+// the store root. Snapshots, the changelog and the lock file are not watched.
+var watchedDirs = []string{".", "experiments", "learnings", "context"}
+
+type fileState struct {
+	mod  time.Time
+	size int64
+}
+
+// watcher detects changes by polling file modification times. Polling keeps
+// marrow free of platform-specific notification APIs and also works when
+// .marrow/ sits on a network or synced filesystem.
+type watcher struct {
+	root  string
+	files map[string]fileState
+}
+
+func newWatcher(root string) *watcher {
+	return &watcher{root: root, files: scanFiles(root)}
+}
+
+func scanFiles(root string) map[string]fileState {
+	files := make(map[string]fileState)
+	for _, dir := range watchedDirs {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			rel := filepath.ToSlash(filepath.Join(dir, e.Name()))
+			files[rel] = fileState{mod: info.ModTime(), size: info.Size()}
+		}
+	}
+	return files
+}
+
+// poll rescans the tree and returns the paths that were modified, added or
+// removed since the last call. listChanged is set when files were added or
+// removed.
+func (w *watcher) poll() (changed []string, listChanged bool) {
+	current := scanFiles(w.root)
+	for path, st := range current {
+		prev, ok := w.files[path]
+		if !ok {
+			listChanged = true
+		}
+		if !ok || prev != st {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+			listChanged = true
+		}
+	}
+	w.files = current
+	return changed, listChanged
+}
+
+// resourceURI maps a watched file to the resource it backs, or "" if it backs
+// none.
+func resourceURI(rel string) string {
+	dir, file := filepath.Split(filepath.FromSlash(rel))
+	name := strings.TrimSuffix(file, ".yaml")
+	switch filepath.Clean(dir) {
+	case ".":
+		if name == "index" {
+			return resourceScheme + "index"
+		}
+	case "experiments":
+		return experimentsPrefix + name
+	case "learnings":
+		if name == "learnings" || name == "graveyard" {
+			return resourceScheme + name
+		}
+	case "context":
+		return contextPrefix + name
+	}
+	return ""
+}
+
+// watch polls .marrow/ until ctx is done, re-syncing the resource list when
+// files come and go and notifying subscribers of resources whose files
+// changed.
+func (h *handlers) watch(ctx context.Context, srv *server.MCPServer, w *watcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, listChanged := w.poll()
+		if listChanged {
+			h.syncResources(srv)
+		}
+		for _, path := range changed {
+			if uri := resourceURI(path); uri != "" {
+				h.subs.notify(srv, uri)
+			}
+		}
+	}
+}
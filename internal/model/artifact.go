@@ -0,0 +1,13 @@
+package model
+
+// Artifact is a file or directory linked to an experiment: a checkpoint,
+// predictions, a plot, a submission, a stored curve or a git diff. Path is relative to
+// the project directory (the one holding .marrow/) when the file is inside
+// it, and absolute otherwise; it uses forward slashes.
+type Artifact struct {
+	Path   string `yaml:"path"`
+	Kind   string `yaml:"kind"` // checkpoint | predictions | plot | submission | curve | diff | file
+	Size   int64  `yaml:"size"`
+	SHA256 string `yaml:"sha256"`
+	Stored bool   `yaml:"stored,omitempty"` // a copy is kept in .marrow/artifacts/
+}
@@ -0,0 +1,28 @@
+package model
+
+// CurvePoint is one step of a per-step metric series.
+type CurvePoint struct {
+	Step  int64
+	Value float64
+}
+
+// CurveStats summarizes a per-step metric series stored with an experiment,
+// so readers can tell a run that was still improving from one that had
+// plateaued or overfit without loading the series.
+type CurveStats struct {
+	Direction    string  `yaml:"direction"` // higher_is_better | lower_is_better
+	Points       int     `yaml:"points"`
+	Best         float64 `yaml:"best"`
+	BestStep     int64   `yaml:"best_step"`
+	Final        float64 `yaml:"final"`
+	FinalStep    int64   `yaml:"final_step"`
+	Gap          float64 `yaml:"gap,omitempty"`           // how far final is behind best, always ≥ 0
+	Plateau      bool    `yaml:"plateau,omitempty"`       // held within reach of its best for the last quarter of the steps or more
+	PlateauSince int64   `yaml:"plateau_since,omitempty"` // step from which every point stayed within reach of the best
+	Declining    bool    `yaml:"declining,omitempty"`     // final is well behind the best, e.g. overfitting
+}
+
+// HigherIsBetter reports the direction the stats were computed with.
+func (c CurveStats) HigherIsBetter() bool {
+	return c.Direction != "lower_is_better"
+}
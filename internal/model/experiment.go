@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Experiment struct {
 	ID        string    `yaml:"id"`
@@ -9,10 +12,13 @@ type Experiment struct {
 
 	Parents     []string            `yaml:"parents,omitempty"`
 	ChangesFrom map[string][]Change `yaml:"changes_from,omitempty"` // parent_id → list of changes
+	Params      map[string]any      `yaml:"params,omitempty"`       // flat hyperparameters/config, nested keys joined with dots
 
-	Metric    MetricResult `yaml:"metric"`
-	Status    string       `yaml:"status"` // improved | degraded | neutral | failed
-	Reasoning Reasoning    `yaml:"reasoning,omitempty"`
+	Metric    MetricResult          `yaml:"metric"`            // primary metric
+	Metrics   map[string]float64    `yaml:"metrics,omitempty"` // secondary metric name → value
+	Curves    map[string]CurveStats `yaml:"curves,omitempty"`  // metric name → stats of its per-step curve
+	Status    string                `yaml:"status"`            // improved | degraded | neutral | failed
+	Reasoning Reasoning             `yaml:"reasoning,omitempty"`
 
 	Environment *Environment `yaml:"environment,omitempty"`
 
@@ -23,6 +29,39 @@ type Experiment struct {
 
 	Tags  []string `yaml:"tags,omitempty"`
 	Notes string   `yaml:"notes,omitempty"`
+
+	Artifacts []Artifact `yaml:"artifacts,omitempty"`
+
+	Source *Source `yaml:"source,omitempty"` // set on experiments imported from another tracker
+}
+
+// Source identifies the run an imported experiment came from, so importing
+// the same runs again skips them.
+type Source struct {
+	Tool  string `yaml:"tool"`            // mlflow | wandb | tensorboard
+	RunID string `yaml:"run_id"`          // the tool's run ID
+	Name  string `yaml:"name,omitempty"`  // run name
+	Group string `yaml:"group,omitempty"` // MLflow experiment, W&B project, etc.
+}
+
+// MetricValue returns the recorded value for the named metric, checking the
+// primary result before the secondary metrics.
+func (e Experiment) MetricValue(name string) (float64, bool) {
+	if e.Metric.Name == name {
+		return e.Metric.Value, true
+	}
+	v, ok := e.Metrics[name]
+	return v, ok
+}
+
+// PrimaryValue returns the value for the project's primary metric. Experiments
+// that never recorded it by name (older records, or a renamed metric) fall
+// back to their own primary result.
+func (e Experiment) PrimaryValue(primary MetricDef) float64 {
+	if v, ok := e.MetricValue(primary.Name); ok {
+		return v
+	}
+	return e.Metric.Value
 }
 
 type Change struct {
@@ -53,4 +92,40 @@ type Environment struct {
 	DataHash          string            `yaml:"data_hash,omitempty"`
 	SplitSeed         *int              `yaml:"split_seed,omitempty"`
 	PreprocessingHash string            `yaml:"preprocessing_hash,omitempty"`
+	GitCommit         string            `yaml:"git_commit,omitempty"`
+	GitBranch         string            `yaml:"git_branch,omitempty"`
+	GitDirty          bool              `yaml:"git_dirty,omitempty"` // uncommitted changes outside .marrow/
+}
+
+var changeTypes = map[string]bool{"param": true, "added": true, "removed": true, "changed": true}
+
+// Validate checks that the change has a known type and names what changed.
+func (c Change) Validate() error {
+	if !changeTypes[c.Type] {
+		return fmt.Errorf("unknown change type %q: must be param|added|removed|changed", c.Type)
+	}
+	if c.Type == "param" {
+		if c.Param == "" {
+			return fmt.Errorf("param change needs a parameter name")
+		}
+		if c.To == "" {
+			return fmt.Errorf("param change %q needs a new value", c.Param)
+		}
+	} else if c.What == "" {
+		return fmt.Errorf("%s change needs a description", c.Type)
+	}
+	return nil
+}
+
+var reasoningTypes = map[string]bool{"proven": true, "assumption": true, "unknown": true}
+
+// Validate checks the reasoning type. An empty reasoning is valid.
+func (r Reasoning) Validate() error {
+	if r.Type == "" && r.Text == "" && len(r.Evidence) == 0 {
+		return nil
+	}
+	if !reasoningTypes[r.Type] {
+		return fmt.Errorf("invalid reasoning type %q: must be proven|assumption|unknown", r.Type)
+	}
+	return nil
 }
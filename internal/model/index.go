@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Index struct {
 	Computed ComputedIndex `yaml:"computed"`
@@ -8,16 +12,47 @@ type Index struct {
 }
 
 type ComputedIndex struct {
-	LastUpdated      time.Time      `yaml:"last_updated"`
-	TotalExperiments int            `yaml:"total_experiments"`
-	BestExperiment   string         `yaml:"best_experiment,omitempty"`
-	BestMetric       *MetricResult  `yaml:"best_metric,omitempty"`
-	ExperimentChain  []string       `yaml:"experiment_chain,omitempty"` // best path through the DAG
-	AllTags          []string       `yaml:"all_tags,omitempty"`
-	StatusCounts     map[string]int `yaml:"status_counts,omitempty"`
-	ProvenCount      int            `yaml:"proven_count"`
-	AssumptionCount  int            `yaml:"assumption_count"`
-	GraveyardCount   int            `yaml:"graveyard_count"`
+	LastUpdated      time.Time         `yaml:"last_updated"`
+	TotalExperiments int               `yaml:"total_experiments"`
+	BestExperiment   string            `yaml:"best_experiment,omitempty"`
+	BestMetric       *MetricResult     `yaml:"best_metric,omitempty"`
+	ExperimentChain  []string          `yaml:"experiment_chain,omitempty"` // best path through the DAG
+	BestByMetric     map[string]string `yaml:"best_by_metric,omitempty"`   // metric name → leading experiment (multi-metric projects)
+	ParetoFront      []string          `yaml:"pareto_front,omitempty"`     // non-dominated experiments across all metrics
+	DataGroups       []DataGroup       `yaml:"data_groups,omitempty"`      // set when experiments ran on different data
+	AllTags          []string          `yaml:"all_tags,omitempty"`
+	StatusCounts     map[string]int    `yaml:"status_counts,omitempty"`
+	ProvenCount      int               `yaml:"proven_count"`
+	AssumptionCount  int               `yaml:"assumption_count"`
+	GraveyardCount   int               `yaml:"graveyard_count"`
+}
+
+// DataGroup lists experiments that ran on the same data: no two of them
+// recorded a different data version, data hash or split seed. Empty fields
+// were recorded by none of them.
+type DataGroup struct {
+	DataVersion int      `yaml:"data_version,omitempty"`
+	DataHash    string   `yaml:"data_hash,omitempty"`
+	SplitSeed   *int     `yaml:"split_seed,omitempty"`
+	Experiments []string `yaml:"experiments"`
+}
+
+// Label describes the group's data, e.g. "data v2, hash sha256:ab12, split seed 42".
+func (g DataGroup) Label() string {
+	var parts []string
+	if g.DataVersion != 0 {
+		parts = append(parts, fmt.Sprintf("data v%d", g.DataVersion))
+	}
+	if g.DataHash != "" {
+		parts = append(parts, "hash "+g.DataHash)
+	}
+	if g.SplitSeed != nil {
+		parts = append(parts, fmt.Sprintf("split seed %d", *g.SplitSeed))
+	}
+	if len(parts) == 0 {
+		return "data not recorded"
+	}
+	return strings.Join(parts, ", ")
 }
 
 type PinnedIndex struct {
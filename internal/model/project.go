@@ -1,6 +1,9 @@
 package model
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Project struct {
 	Name        string            `yaml:"name"`
@@ -8,15 +11,50 @@ type Project struct {
 	Template    string            `yaml:"template,omitempty"`
 	TaskType    string            `yaml:"task_type,omitempty"`
 	Metric      MetricDef         `yaml:"metric"`
+	Metrics     []MetricDef       `yaml:"metrics,omitempty"` // additional named metrics; one may be marked primary
 	DataVersion int               `yaml:"data_version,omitempty"`
 	Tags        []string          `yaml:"tags,omitempty"`
 	Extra       map[string]string `yaml:"extra,omitempty"`
+	MCP         *MCPConfig        `yaml:"mcp,omitempty"`
+	Environment *EnvConfig        `yaml:"environment,omitempty"`
+	Index       *IndexConfig      `yaml:"index,omitempty"`
+}
+
+// IndexConfig tunes how the computed index picks the best experiment.
+type IndexConfig struct {
+	CurrentDataOnly bool `yaml:"current_data_only,omitempty"` // only experiments run on DataVersion compete for best
+}
+
+// OnCurrentData reports whether e may compete for best: always, unless the
+// project restricts the index to its current data version.
+func (p Project) OnCurrentData(e Experiment) bool {
+	if p.Index == nil || !p.Index.CurrentDataOnly || p.DataVersion == 0 {
+		return true
+	}
+	return e.DataVersion == p.DataVersion
+}
+
+// EnvConfig controls what "marrow exp new --capture-env" records. Paths are
+// relative to the project directory.
+type EnvConfig struct {
+	Python        string   `yaml:"python,omitempty"`        // interpreter to query (default python, then python3)
+	Packages      []string `yaml:"packages,omitempty"`      // pip packages to record; empty uses a default ML list
+	Data          []string `yaml:"data,omitempty"`          // files or directories hashed into data_hash
+	Preprocessing []string `yaml:"preprocessing,omitempty"` // files or directories hashed into preprocessing_hash
+}
+
+// MCPConfig holds per-project defaults for "marrow mcp". Command-line flags
+// override them.
+type MCPConfig struct {
+	ReadOnly bool     `yaml:"read_only,omitempty"`
+	Tools    []string `yaml:"tools,omitempty"` // allowlist of tool names or globs (e.g. get_*)
 }
 
 type MetricDef struct {
 	Name      string  `yaml:"name"`
 	Direction string  `yaml:"direction"`
 	Baseline  float64 `yaml:"baseline,omitempty"`
+	Primary   bool    `yaml:"primary,omitempty"`
 }
 
 func (m MetricDef) Validate() error {
@@ -27,3 +65,96 @@ func (m MetricDef) Validate() error {
 		return fmt.Errorf("invalid metric direction %q: must be higher_is_better or lower_is_better", m.Direction)
 	}
 }
+
+func (m MetricDef) HigherIsBetter() bool {
+	return strings.EqualFold(m.Direction, "higher_is_better")
+}
+
+// PrimaryMetric returns the metric that drives the best experiment and chain.
+// An entry in Metrics marked primary wins; otherwise Metric is used, falling
+// back to the first entry in Metrics when Metric is left empty.
+func (p Project) PrimaryMetric() MetricDef {
+	for _, m := range p.Metrics {
+		if m.Primary {
+			return m
+		}
+	}
+	if p.Metric.Name != "" || len(p.Metrics) == 0 {
+		return p.Metric
+	}
+	return p.Metrics[0]
+}
+
+// AllMetrics returns every declared metric, primary first.
+func (p Project) AllMetrics() []MetricDef {
+	primary := p.PrimaryMetric()
+	all := []MetricDef{primary}
+	if p.Metric.Name != "" && p.Metric.Name != primary.Name {
+		all = append(all, p.Metric)
+	}
+	for _, m := range p.Metrics {
+		if m.Name != primary.Name {
+			all = append(all, m)
+		}
+	}
+	return all
+}
+
+// SecondaryMetrics returns every declared metric except the primary one.
+func (p Project) SecondaryMetrics() []MetricDef {
+	return p.AllMetrics()[1:]
+}
+
+// FindMetric looks up a declared metric by name.
+func (p Project) FindMetric(name string) (MetricDef, bool) {
+	for _, m := range p.AllMetrics() {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return MetricDef{}, false
+}
+
+// ValidateMetrics checks every declared metric and that names are unique with
+// at most one primary.
+func (p Project) ValidateMetrics() error {
+	primaries := 0
+	for _, m := range p.Metrics {
+		if m.Primary {
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		return fmt.Errorf("only one metric may be marked primary, found %d", primaries)
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range p.AllMetrics() {
+		if m.Name == "" && len(p.Metrics) > 0 {
+			return fmt.Errorf("every entry in metrics needs a name")
+		}
+		if seen[m.Name] {
+			return fmt.Errorf("metric %q declared more than once", m.Name)
+		}
+		seen[m.Name] = true
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("metric %q: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateMetricValues checks that every value names a declared secondary
+// metric. The primary metric is recorded separately in Experiment.Metric.
+func (p Project) ValidateMetricValues(values map[string]float64) error {
+	primary := p.PrimaryMetric()
+	for name := range values {
+		if name == primary.Name {
+			return fmt.Errorf("metric %q is the primary metric; pass it as the metric value instead", name)
+		}
+		if _, ok := p.FindMetric(name); !ok {
+			return fmt.Errorf("unknown metric %q: declare it under metrics in marrow.yaml", name)
+		}
+	}
+	return nil
+}
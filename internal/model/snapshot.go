@@ -0,0 +1,26 @@
+package model
+
+// SnapshotDiff describes how the live .marrow/ state differs from a snapshot.
+// Added entries exist now but not in the snapshot; removed entries are the
+// reverse.
+type SnapshotDiff struct {
+	Snapshot    string  `yaml:"snapshot"`
+	Experiments DiffSet `yaml:"experiments,omitempty"`
+	Learnings   DiffSet `yaml:"learnings,omitempty"`
+	Graveyard   DiffSet `yaml:"graveyard,omitempty"`
+	Pinned      DiffSet `yaml:"pinned,omitempty"`
+}
+
+type DiffSet struct {
+	Added   []string `yaml:"added,omitempty"`
+	Removed []string `yaml:"removed,omitempty"`
+	Changed []string `yaml:"changed,omitempty"`
+}
+
+func (d DiffSet) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d SnapshotDiff) Empty() bool {
+	return d.Experiments.Empty() && d.Learnings.Empty() && d.Graveyard.Empty() && d.Pinned.Empty()
+}
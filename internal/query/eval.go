@@ -0,0 +1,110 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+type node interface {
+	match(e model.Experiment, env env) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(e model.Experiment, env env) bool {
+	return n.left.match(e, env) && n.right.match(e, env)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(e model.Experiment, env env) bool {
+	return n.left.match(e, env) || n.right.match(e, env)
+}
+
+type notNode struct{ inner node }
+
+func (n notNode) match(e model.Experiment, env env) bool {
+	return !n.inner.match(e, env)
+}
+
+// textNode is a case-insensitive substring search over notes, reasoning and
+// evidence observations.
+type textNode struct{ needle string }
+
+func (n textNode) match(e model.Experiment, _ env) bool {
+	if strings.Contains(strings.ToLower(e.Notes), n.needle) ||
+		strings.Contains(strings.ToLower(e.Reasoning.Text), n.needle) {
+		return true
+	}
+	for _, obs := range e.Reasoning.Evidence {
+		if strings.Contains(strings.ToLower(obs), n.needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmpNode compares a field against a literal. Experiments that do not record
+// the field never match, so "local_cv<0.5" skips runs with no CV score.
+type cmpNode struct {
+	name  string
+	field field
+	op    string
+	val   value
+}
+
+func (n cmpNode) match(e model.Experiment, env env) bool {
+	got, ok := n.field.get(e, env)
+	if !ok {
+		return n.op == "!=" && n.field.kind != kindNumber && n.field.kind != kindTime
+	}
+	switch n.field.kind {
+	case kindNumber:
+		return compareOrdered(got.num, n.val.num, n.op)
+	case kindTime:
+		c := got.time.Compare(n.val.time)
+		return compareOrdered(float64(c), 0, n.op)
+	case kindList:
+		found := false
+		for _, item := range got.list {
+			item = strings.ToLower(item)
+			if item == n.val.str || (n.op == "~" && strings.Contains(item, n.val.str)) {
+				found = true
+				break
+			}
+		}
+		if n.op == "!=" {
+			return !found
+		}
+		return found
+	default:
+		s := strings.ToLower(got.str)
+		switch n.op {
+		case "=":
+			return s == n.val.str
+		case "!=":
+			return s != n.val.str
+		default:
+			return strings.Contains(s, n.val.str)
+		}
+	}
+}
+
+func compareOrdered(a, b float64, op string) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindNumber
+	kindTime
+	kindList
+)
+
+func (k fieldKind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindTime:
+		return "date"
+	case kindList:
+		return "list"
+	default:
+		return "text"
+	}
+}
+
+// value holds whichever representation matches the field's kind.
+type value struct {
+	str  string
+	num  float64
+	time time.Time
+	list []string
+}
+
+// env carries project context needed to resolve fields such as the primary
+// metric.
+type env struct {
+	primary model.MetricDef
+}
+
+type getter func(e model.Experiment, env env) (value, bool)
+
+type field struct {
+	kind fieldKind
+	get  getter
+}
+
+func str(f func(e model.Experiment) string) field {
+	return field{kindString, func(e model.Experiment, _ env) (value, bool) {
+		s := f(e)
+		return value{str: s}, s != ""
+	}}
+}
+
+func num(f func(e model.Experiment) *float64) field {
+	return field{kindNumber, func(e model.Experiment, _ env) (value, bool) {
+		v := f(e)
+		if v == nil {
+			return value{}, false
+		}
+		return value{num: *v}, true
+	}}
+}
+
+func envStr(f func(env *model.Environment) string) field {
+	return str(func(e model.Experiment) string {
+		if e.Environment == nil {
+			return ""
+		}
+		return f(e.Environment)
+	})
+}
+
+func ptr(v float64) *float64 { return &v }
+
+var fields = map[string]field{
+	"id":             str(func(e model.Experiment) string { return e.ID }),
+	"model":          str(func(e model.Experiment) string { return e.BaseModel }),
+	"status":         str(func(e model.Experiment) string { return e.Status }),
+	"notes":          str(func(e model.Experiment) string { return e.Notes }),
+	"reasoning":      str(func(e model.Experiment) string { return e.Reasoning.Text }),
+	"reasoning_type": str(func(e model.Experiment) string { return e.Reasoning.Type }),
+	"metric": {kindNumber, func(e model.Experiment, env env) (value, bool) {
+		return value{num: e.PrimaryValue(env.primary)}, true
+	}},
+	"delta":     num(func(e model.Experiment) *float64 { return ptr(e.Metric.Delta) }),
+	"baseline":  num(func(e model.Experiment) *float64 { return ptr(e.Metric.Baseline) }),
+	"local_cv":  num(func(e model.Experiment) *float64 { return e.LocalCV }),
+	"public_lb": num(func(e model.Experiment) *float64 { return e.PublicLB }),
+	"data_version": num(func(e model.Experiment) *float64 {
+		if e.DataVersion == 0 {
+			return nil
+		}
+		return ptr(float64(e.DataVersion))
+	}),
+	"timestamp": {kindTime, func(e model.Experiment, _ env) (value, bool) {
+		return value{time: e.Timestamp}, !e.Timestamp.IsZero()
+	}},
+	"tag": {kindList, func(e model.Experiment, _ env) (value, bool) {
+		return value{list: e.Tags}, true
+	}},
+	"parent": {kindList, func(e model.Experiment, _ env) (value, bool) {
+		return value{list: e.Parents}, true
+	}},
+	"python":             envStr(func(env *model.Environment) string { return env.Python }),
+	"gpu":                envStr(func(env *model.Environment) string { return env.GPU }),
+	"data_hash":          envStr(func(env *model.Environment) string { return env.DataHash }),
+	"preprocessing_hash": envStr(func(env *model.Environment) string { return env.PreprocessingHash }),
+	"git_commit":         envStr(func(env *model.Environment) string { return env.GitCommit }),
+	"git_branch":         envStr(func(env *model.Environment) string { return env.GitBranch }),
+	"split_seed": num(func(e model.Experiment) *float64 {
+		if e.Environment == nil || e.Environment.SplitSeed == nil {
+			return nil
+		}
+		return ptr(float64(*e.Environment.SplitSeed))
+	}),
+}
+
+var aliases = map[string]string{
+	"base_model": "model",
+	"tags":       "tag",
+	"parents":    "parent",
+	"date":       "timestamp",
+	"lb":         "public_lb",
+	"cv":         "local_cv",
+	"seed":       "split_seed",
+}
+
+// resolveField maps a query field name to a getter. Besides the fixed fields,
+// "pkg.<name>" reads Environment.KeyPackages and any other name is looked up
+// as a metric, primary or secondary (optionally written "metrics.<name>").
+// checkField rejects names that are none of these.
+func resolveField(name string) field {
+	name = strings.ToLower(name)
+	if a, ok := aliases[name]; ok {
+		name = a
+	}
+	if f, ok := fields[name]; ok {
+		return f
+	}
+	if pkg, ok := strings.CutPrefix(name, "pkg."); ok {
+		return envStr(func(env *model.Environment) string { return env.KeyPackages[pkg] })
+	}
+	metric := strings.TrimPrefix(name, "metrics.")
+	return field{kindNumber, func(e model.Experiment, env env) (value, bool) {
+		if strings.EqualFold(env.primary.Name, metric) {
+			return value{num: e.PrimaryValue(env.primary)}, true
+		}
+		for k, v := range e.Metrics {
+			if strings.EqualFold(k, metric) {
+				return value{num: v}, true
+			}
+		}
+		return value{}, false
+	}}
+}
+
+// checkField reports an error for a field name resolveField would only
+// look up as a metric when no declared metric, nor any metric recorded on
+// exps, has that name. Without it a misspelled field silently matches
+// nothing.
+func checkField(name string, proj model.Project, exps []model.Experiment) error {
+	lower := strings.ToLower(name)
+	if a, ok := aliases[lower]; ok {
+		lower = a
+	}
+	if _, ok := fields[lower]; ok || strings.HasPrefix(lower, "pkg.") {
+		return nil
+	}
+	metric := strings.TrimPrefix(lower, "metrics.")
+
+	seen := make(map[string]bool)
+	var metrics []string
+	add := func(m string) {
+		if !seen[strings.ToLower(m)] {
+			seen[strings.ToLower(m)] = true
+			metrics = append(metrics, m)
+		}
+	}
+	for _, m := range proj.AllMetrics() {
+		add(m.Name)
+	}
+	for _, e := range exps {
+		for m := range e.Metrics {
+			add(m)
+		}
+	}
+	if seen[metric] {
+		return nil
+	}
+
+	names := make([]string, 0, len(fields)+1)
+	for f := range fields {
+		names = append(names, f)
+	}
+	names = append(names, "pkg.<name>")
+	sort.Strings(names)
+	msg := fmt.Sprintf("unknown field %q; valid fields are %s", name, strings.Join(names, ", "))
+	if len(metrics) > 0 {
+		sort.Strings(metrics)
+		msg += " and the metrics " + strings.Join(metrics, ", ")
+	}
+	return errors.New(msg)
+}
+
+// parseLiteral converts the right-hand side of a comparison to the field's kind.
+func parseLiteral(kind fieldKind, lit string) (value, error) {
+	switch kind {
+	case kindNumber:
+		v, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return value{}, fmt.Errorf("expected a number, got %q", lit)
+		}
+		return value{num: v}, nil
+	case kindTime:
+		t, err := parseDate(lit)
+		if err != nil {
+			return value{}, err
+		}
+		return value{time: t}, nil
+	default:
+		return value{str: lit}, nil
+	}
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC 3339", s)
+}
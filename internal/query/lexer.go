@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokString
+	tokOp
+	tokColon
+	tokLParen
+	tokRParen
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+const opChars = "=!<>~"
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == ':':
+			tokens = append(tokens, token{tokColon, ":", i})
+			i++
+			if n := len(tokens); n >= 2 && isDateKey(tokens[n-2]) && i < len(input) && input[i] != '"' && input[i] != '\'' {
+				// A date value runs to the next space or ')', so the colons
+				// of an RFC 3339 time need no quoting.
+				start := i
+				for i < len(input) && !unicode.IsSpace(rune(input[i])) && input[i] != ')' {
+					i++
+				}
+				if i > start {
+					tokens = append(tokens, token{tokWord, input[start:i], start})
+				}
+			}
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(input[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string starting at position %d", i)
+			}
+			tokens = append(tokens, token{tokString, input[i+1 : i+1+end], i})
+			i += end + 2
+		case strings.IndexByte(opChars, c) >= 0:
+			start := i
+			for i < len(input) && strings.IndexByte(opChars, input[i]) >= 0 {
+				i++
+			}
+			op := input[start:i]
+			switch op {
+			case "=", "==", "!=", "<", "<=", ">", ">=", "~":
+			default:
+				return nil, fmt.Errorf("unknown operator %q at position %d", op, start)
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+		default:
+			start := i
+			for i < len(input) {
+				c := input[i]
+				if unicode.IsSpace(rune(c)) || c == '(' || c == ')' || c == ':' || c == '"' || c == '\'' ||
+					strings.IndexByte(opChars, c) >= 0 {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, token{tokWord, input[start:i], start})
+		}
+	}
+	tokens = append(tokens, token{tokEOF, "", len(input)})
+	return tokens, nil
+}
+
+// isDateKey reports whether t is the key of a since: or until: shorthand.
+func isDateKey(t token) bool {
+	return t.kind == tokWord && (strings.EqualFold(t.text, "since") || strings.EqualFold(t.text, "until"))
+}
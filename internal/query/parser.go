@@ -0,0 +1,184 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grammar:
+//
+//	expr   = and { "or" and }
+//	and    = unary { ["and"] unary }
+//	unary  = "not" unary | "(" expr ")" | term
+//	term   = WORD OP value | WORD ":" value | value
+//	value  = WORD | STRING
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(t token, kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) parseExpr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind == tokEOF || t.kind == tokRParen || p.keyword(t, "or") {
+			return left, nil
+		}
+		if p.keyword(t, "and") {
+			p.next()
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	switch {
+	case p.keyword(t, "not"):
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	case t.kind == tokLParen:
+		p.next()
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", p.peek().pos)
+		}
+		p.next()
+		return inner, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return textNode{strings.ToLower(t.text)}, nil
+	case tokWord:
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of query")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+
+	switch p.peek().kind {
+	case tokOp:
+		op := p.next().text
+		lit, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return compare(t.text, op, lit)
+	case tokColon:
+		p.next()
+		lit, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return prefixTerm(t.text, lit)
+	}
+	return textNode{strings.ToLower(t.text)}, nil
+}
+
+func (p *parser) value() (string, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokString {
+		if t.kind == tokEOF {
+			return "", fmt.Errorf("expected a value at end of query")
+		}
+		return "", fmt.Errorf("expected a value at position %d, got %q", t.pos, t.text)
+	}
+	return t.text, nil
+}
+
+// prefixTerm handles the "key:value" shorthand. since/until bound the
+// timestamp, text searches notes and reasoning, and any other key is an
+// equality test on that field.
+func prefixTerm(key, lit string) (node, error) {
+	switch strings.ToLower(key) {
+	case "since":
+		return compare("timestamp", ">=", lit)
+	case "until":
+		t, err := parseDate(lit)
+		if err != nil {
+			return nil, err
+		}
+		if len(lit) == len("2006-01-02") {
+			// A bare date includes the whole day.
+			return compareValue("timestamp", "<", value{time: t.AddDate(0, 0, 1)})
+		}
+		return compareValue("timestamp", "<=", value{time: t})
+	case "text":
+		return textNode{strings.ToLower(lit)}, nil
+	}
+	return compare(key, "=", lit)
+}
+
+func compare(name, op, lit string) (node, error) {
+	f := resolveField(name)
+	v, err := parseLiteral(f.kind, lit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return compareValue(name, op, v)
+}
+
+func compareValue(name, op string, v value) (node, error) {
+	f := resolveField(name)
+	switch f.kind {
+	case kindString, kindList:
+		if op != "=" && op != "!=" && op != "~" {
+			return nil, fmt.Errorf("operator %s not supported on %s field %q (use =, != or ~)", op, f.kind, name)
+		}
+		v.str = strings.ToLower(v.str)
+	case kindNumber, kindTime:
+		if op == "~" {
+			return nil, fmt.Errorf("operator ~ not supported on %s field %q", f.kind, name)
+		}
+	}
+	return cmpNode{name: name, field: f, op: op, val: v}, nil
+}
@@ -0,0 +1,163 @@
+// Package query implements the filter language behind "marrow exp query" and
+// the query_experiments MCP tool.
+//
+//	model=xgboost and metric>0.85 and tag:feature_eng and since:2026-09-01
+//	(status=improved or status=neutral) and not "leak"
+//	public_lb>=0.8 and python~3.11 and pkg.torch=2.3.0
+//
+// Terms are field comparisons (=, !=, <, <=, >, >=, and ~ for substring),
+// key:value shorthands (tag:, since:, until:, text:) or bare words and quoted
+// strings, which search notes and reasoning. Adjacent terms are ANDed.
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// Query is a compiled filter expression.
+type Query struct {
+	root node
+}
+
+// Parse compiles a query string. An empty string matches every experiment.
+func Parse(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return &Query{}, nil
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("invalid query: unexpected %q at position %d", t.text, t.pos)
+	}
+	return &Query{root: root}, nil
+}
+
+// Validate checks that every field the query compares exists: a built-in
+// field or alias, pkg.<name>, a metric declared in the project, or a metric
+// recorded on one of exps.
+func (q *Query) Validate(proj model.Project, exps []model.Experiment) error {
+	var err error
+	walk(q.root, func(n cmpNode) {
+		if err == nil {
+			err = checkField(n.name, proj, exps)
+		}
+	})
+	return err
+}
+
+// walk calls fn for each comparison under n.
+func walk(n node, fn func(cmpNode)) {
+	switch n := n.(type) {
+	case andNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	case orNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	case notNode:
+		walk(n.inner, fn)
+	case cmpNode:
+		fn(n)
+	}
+}
+
+// Match reports whether a single experiment satisfies the query.
+func (q *Query) Match(e model.Experiment, proj model.Project) bool {
+	if q.root == nil {
+		return true
+	}
+	return q.root.match(e, env{primary: proj.PrimaryMetric()})
+}
+
+// Filter returns the experiments that satisfy the query, in input order.
+func (q *Query) Filter(exps []model.Experiment, proj model.Project) []model.Experiment {
+	var out []model.Experiment
+	for _, e := range exps {
+		if q.Match(e, proj) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Sort orders experiments in place by a field name. A leading "-" sorts
+// descending; "best" sorts by the primary metric, best first. Experiments
+// missing the field sort last either way.
+func Sort(exps []model.Experiment, key string, proj model.Project) error {
+	if key == "" {
+		return nil
+	}
+	desc := false
+	if k, ok := strings.CutPrefix(key, "-"); ok {
+		key, desc = k, true
+	}
+	if strings.EqualFold(key, "best") {
+		key, desc = "metric", proj.PrimaryMetric().HigherIsBetter() != desc
+	}
+	if len(exps) == 0 {
+		return nil
+	}
+	if err := checkField(key, proj, exps); err != nil {
+		return err
+	}
+	f := resolveField(key)
+	if f.kind == kindList {
+		return fmt.Errorf("cannot sort by list field %q", key)
+	}
+	env := env{primary: proj.PrimaryMetric()}
+
+	sort.SliceStable(exps, func(i, j int) bool {
+		a, aok := f.get(exps[i], env)
+		b, bok := f.get(exps[j], env)
+		if !aok || !bok {
+			return aok && !bok
+		}
+		var c int
+		switch f.kind {
+		case kindNumber:
+			c = cmpFloat(a.num, b.num)
+		case kindTime:
+			c = a.time.Compare(b.time)
+		default:
+			c = strings.Compare(strings.ToLower(a.str), strings.ToLower(b.str))
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return nil
+}
+
+// Limit keeps the first n experiments of a sorted result, or the most recent
+// n when results are still in chronological order. n <= 0 keeps everything.
+func Limit(exps []model.Experiment, n int, sorted bool) []model.Experiment {
+	if n <= 0 || len(exps) <= n {
+		return exps
+	}
+	if sorted {
+		return exps[:n]
+	}
+	return exps[len(exps)-n:]
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,208 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+const (
+	chartWidth   = 720
+	chartHeight  = 260
+	marginLeft   = 64
+	marginRight  = 16
+	marginTop    = 16
+	marginBottom = 40
+	maxTagBars   = 15
+)
+
+var statusColors = map[string]string{
+	"improved": "#2e7d32",
+	"degraded": "#c62828",
+	"neutral":  "#757575",
+	"failed":   "#bdbdbd",
+}
+
+func statusColor(status string) string {
+	if c, ok := statusColors[status]; ok {
+		return c
+	}
+	return "#757575"
+}
+
+func esc(s string) string { return template.HTMLEscapeString(s) }
+
+// scored returns the non-failed experiments with their primary metric value,
+// oldest first.
+func scored(exps []model.Experiment, metric model.MetricDef) ([]model.Experiment, []float64) {
+	var out []model.Experiment
+	for _, e := range exps {
+		if e.Status != "failed" {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Timestamp.Equal(out[j].Timestamp) {
+			return out[i].Timestamp.Before(out[j].Timestamp)
+		}
+		return out[i].ID < out[j].ID
+	})
+	values := make([]float64, len(out))
+	for i, e := range out {
+		values[i] = e.PrimaryValue(metric)
+	}
+	return out, values
+}
+
+// valueRange pads the range of values by 5% so points don't sit on the axes.
+func valueRange(values []float64) (lo, hi float64) {
+	lo, hi = values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		pad := math.Max(math.Abs(lo)*0.05, 0.5)
+		return lo - pad, hi + pad
+	}
+	pad := (hi - lo) * 0.05
+	return lo - pad, hi + pad
+}
+
+// timelineChart plots the metric of every non-failed experiment in the order
+// they were logged, with a step line tracking the best value so far.
+func timelineChart(exps []model.Experiment, metric model.MetricDef) template.HTML {
+	points, values := scored(exps, metric)
+	if len(points) == 0 {
+		return ""
+	}
+	lo, hi := valueRange(values)
+	plotW := float64(chartWidth - marginLeft - marginRight)
+	plotH := float64(chartHeight - marginTop - marginBottom)
+	x := func(i int) float64 {
+		if len(points) == 1 {
+			return marginLeft + plotW/2
+		}
+		return marginLeft + plotW*float64(i)/float64(len(points)-1)
+	}
+	y := func(v float64) float64 { return marginTop + plotH*(hi-v)/(hi-lo) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img" aria-label="%s over time">`, chartWidth, chartHeight, esc(metric.Name))
+	yAxis(&b, lo, hi, y)
+
+	// Best so far, as a step line.
+	higher := metric.HigherIsBetter()
+	best := values[0]
+	fmt.Fprintf(&b, `<path class="best" d="M%.1f %.1f`, x(0), y(best))
+	for i := 1; i < len(values); i++ {
+		if (higher && values[i] > best) || (!higher && values[i] < best) {
+			best = values[i]
+			fmt.Fprintf(&b, ` H%.1f V%.1f`, x(i), y(best))
+		}
+	}
+	fmt.Fprintf(&b, ` H%.1f"/>`, x(len(values)-1))
+
+	for i, e := range points {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="4" fill="%s"><title>%s: %.4f (%s)</title></circle>`,
+			x(i), y(values[i]), statusColor(e.Status), esc(e.ID), values[i], esc(e.Status))
+	}
+
+	base := chartHeight - marginBottom + 16
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, marginLeft, base, esc(axisLabel(points[0])))
+	if len(points) > 1 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth-marginRight, base, esc(axisLabel(points[len(points)-1])))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func axisLabel(e model.Experiment) string {
+	if e.Timestamp.IsZero() {
+		return e.ID
+	}
+	return e.ID + " · " + e.Timestamp.Format("2006-01-02")
+}
+
+// yAxis draws horizontal grid lines with value labels.
+func yAxis(b *strings.Builder, lo, hi float64, y func(float64) float64) {
+	const ticks = 4
+	for i := 0; i <= ticks; i++ {
+		v := lo + (hi-lo)*float64(i)/ticks
+		fmt.Fprintf(b, `<line class="grid" x1="%d" x2="%d" y1="%.1f" y2="%.1f"/>`, marginLeft, chartWidth-marginRight, y(v), y(v))
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" text-anchor="end">%.4g</text>`, marginLeft-6, y(v)+4, v)
+	}
+}
+
+type tagStat struct {
+	tag   string
+	count int
+	mean  float64
+	best  string
+}
+
+// tagChart draws one bar per tag with the mean metric of its non-failed
+// experiments, best tags first.
+func tagChart(exps []model.Experiment, metric model.MetricDef) template.HTML {
+	points, values := scored(exps, metric)
+	higher := metric.HigherIsBetter()
+
+	byTag := make(map[string]*tagStat)
+	bestValue := make(map[string]float64)
+	for i, e := range points {
+		for _, t := range e.Tags {
+			st, ok := byTag[t]
+			if !ok {
+				st = &tagStat{tag: t}
+				byTag[t] = st
+			}
+			st.count++
+			st.mean += values[i]
+			if bv, ok := bestValue[t]; !ok || (higher && values[i] > bv) || (!higher && values[i] < bv) {
+				bestValue[t] = values[i]
+				st.best = e.ID
+			}
+		}
+	}
+	if len(byTag) == 0 {
+		return ""
+	}
+
+	stats := make([]tagStat, 0, len(byTag))
+	for _, st := range byTag {
+		st.mean /= float64(st.count)
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].mean != stats[j].mean {
+			return (stats[i].mean > stats[j].mean) == higher
+		}
+		return stats[i].tag < stats[j].tag
+	})
+	if len(stats) > maxTagBars {
+		stats = stats[:maxTagBars]
+	}
+
+	// Bars start at the low end of the observed range so differences show.
+	lo, hi := valueRange(values)
+	const rowH, labelW, valueW = 24, 160, 150
+	height := marginTop + rowH*len(stats) + 8
+	barMax := float64(chartWidth - labelW - valueW)
+	width := func(v float64) float64 { return math.Max(barMax*(v-lo)/(hi-lo), 2) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img" aria-label="%s by tag">`, chartWidth, height, esc(metric.Name))
+	for i, st := range stats {
+		top := marginTop + i*rowH
+		w := width(st.mean)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, labelW-8, top+15, esc(st.tag))
+		fmt.Fprintf(&b, `<rect class="bar" x="%d" y="%d" width="%.1f" height="%d"><title>%s: mean %.4f over %d, best %s</title></rect>`,
+			labelW, top+3, w, rowH-8, esc(st.tag), st.mean, st.count, esc(st.best))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%.4f (n=%d)</text>`, float64(labelW)+w+6, top+15, st.mean, st.count)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/graph"
+)
+
+const (
+	nodeW   = 150
+	nodeH   = 42
+	colGap  = 56
+	rowGap  = 14
+	dagPad  = 10
+	maxRows = 400 // beyond this the SVG gets too large to be useful
+)
+
+// dagChart lays the graph out in columns: each experiment sits one column
+// right of its deepest parent. Within a column, nodes are ordered by the
+// mean row of their parents to keep edges short.
+func dagChart(g graph.Graph) template.HTML {
+	if len(g.Nodes) == 0 || len(g.Nodes) > maxRows {
+		return ""
+	}
+
+	parents := make(map[string][]string)
+	for _, e := range g.Edges {
+		parents[e.To] = append(parents[e.To], e.From)
+	}
+
+	depth := make(map[string]int, len(g.Nodes))
+	visiting := make(map[string]bool)
+	var depthOf func(id string) int
+	depthOf = func(id string) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		if visiting[id] { // parent cycle
+			return 0
+		}
+		visiting[id] = true
+		d := 0
+		for _, p := range parents[id] {
+			if pd := depthOf(p) + 1; pd > d {
+				d = pd
+			}
+		}
+		visiting[id] = false
+		depth[id] = d
+		return d
+	}
+
+	var columns [][]graph.Node
+	for _, n := range g.Nodes {
+		d := depthOf(n.ID)
+		for len(columns) <= d {
+			columns = append(columns, nil)
+		}
+		columns[d] = append(columns[d], n)
+	}
+
+	row := make(map[string]float64, len(g.Nodes))
+	for _, col := range columns {
+		key := make(map[string]float64, len(col))
+		for i, n := range col {
+			key[n.ID] = float64(i)
+			if ps := parents[n.ID]; len(ps) > 0 {
+				sum := 0.0
+				for _, p := range ps {
+					sum += row[p]
+				}
+				key[n.ID] = sum / float64(len(ps))
+			}
+		}
+		sort.SliceStable(col, func(i, j int) bool { return key[col[i].ID] < key[col[j].ID] })
+		for i, n := range col {
+			row[n.ID] = float64(i)
+		}
+	}
+
+	rows := 0
+	for _, col := range columns {
+		rows = max(rows, len(col))
+	}
+	width := dagPad*2 + len(columns)*nodeW + (len(columns)-1)*colGap
+	height := dagPad*2 + rows*nodeH + (rows-1)*rowGap
+
+	pos := func(id string) (float64, float64) {
+		return float64(dagPad + depth[id]*(nodeW+colGap)), float64(dagPad) + row[id]*(nodeH+rowGap)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="dag" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="experiment DAG">`, width, height, width, height)
+	for _, e := range g.Edges {
+		x1, y1 := pos(e.From)
+		x2, y2 := pos(e.To)
+		x1, y1, y2 = x1+nodeW, y1+nodeH/2, y2+nodeH/2
+		mid := (x1 + x2) / 2
+		class := "edge"
+		if e.OnChain {
+			class = "edge chain"
+		}
+		fmt.Fprintf(&b, `<path class="%s" d="M%.1f %.1f C%.1f %.1f %.1f %.1f %.1f %.1f"/>`, class, x1, y1, mid, y1, mid, y2, x2, y2)
+	}
+	for _, n := range g.Nodes {
+		x, y := pos(n.ID)
+		class := "node " + n.Status
+		if n.OnChain {
+			class += " chain"
+		}
+		if n.ID == g.Best {
+			class += " best"
+		}
+		fmt.Fprintf(&b, `<g class="%s"><title>%s</title>`, esc(class), esc(nodeTitle(n)))
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%d" height="%d" rx="6"/>`, x, y, nodeW, nodeH)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="id">%s</text>`, x+8, y+17, esc(n.ID))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" style="fill:%s">%.4f · %s</text></g>`, x+8, y+33, statusColor(n.Status), n.Value, esc(n.Status))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func nodeTitle(n graph.Node) string {
+	t := fmt.Sprintf("%s: %.4f (%s)", n.ID, n.Value, n.Status)
+	if len(n.Tags) > 0 {
+		t += " [" + strings.Join(n.Tags, ", ") + "]"
+	}
+	return t
+}
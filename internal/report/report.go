@@ -0,0 +1,174 @@
+// Package report renders the knowledge base as a self-contained HTML page:
+// no scripts, no external assets, charts drawn as inline SVG.
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/graph"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/version"
+)
+
+//go:embed report.html
+var pageSource string
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"oneLiner": format.ExperimentOneLiner,
+	"value":    func(v float64) string { return fmt.Sprintf("%.4f", v) },
+}).Parse(pageSource))
+
+// DefaultChangelog is how many recent changelog entries a report shows.
+const DefaultChangelog = 30
+
+// Options configures a report.
+type Options struct {
+	Changelog int // recent changelog entries to include; 0 means DefaultChangelog
+}
+
+// data is what the page template renders.
+type data struct {
+	Project   model.Project
+	Metric    model.MetricDef
+	Generated time.Time
+	Version   string
+
+	Index        model.Index
+	Experiments  int
+	Best         *model.Experiment
+	Chain        []model.Experiment
+	StatusCounts []statusCount
+
+	Timeline template.HTML
+	ByTag    template.HTML
+	DAG      template.HTML
+
+	Learnings model.LearningsFile
+	Graveyard model.GraveyardFile
+	Pinned    model.PinnedIndex
+	HasPinned bool
+	Changelog []model.ChangelogEntry
+}
+
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+// Write renders the report into dir/index.html, creating dir if needed, and
+// returns the file's path.
+func Write(s *store.Store, dir string, opts Options) (string, error) {
+	var buf bytes.Buffer
+	if err := Render(&buf, s, opts); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, "index.html")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("writing report: %w", err)
+	}
+	return path, nil
+}
+
+// Render writes the report page for the store to w.
+func Render(w io.Writer, s *store.Store, opts Options) error {
+	d, err := collect(s, opts)
+	if err != nil {
+		return err
+	}
+	return page.Execute(w, d)
+}
+
+func collect(s *store.Store, opts Options) (data, error) {
+	proj, err := s.ReadProject()
+	if err != nil {
+		return data{}, fmt.Errorf("reading project: %w", err)
+	}
+	exps, err := s.ListExperiments()
+	if err != nil {
+		return data{}, fmt.Errorf("listing experiments: %w", err)
+	}
+	idx, err := s.ReadIndex()
+	if err != nil {
+		return data{}, fmt.Errorf("reading index: %w", err)
+	}
+	learnings, err := s.ReadLearnings()
+	if err != nil {
+		return data{}, fmt.Errorf("reading learnings: %w", err)
+	}
+	graveyard, err := s.ReadGraveyard()
+	if err != nil {
+		return data{}, fmt.Errorf("reading graveyard: %w", err)
+	}
+	// A missing changelog just means nothing has been logged yet.
+	cl, _ := s.ReadChangelog()
+
+	v, _, _ := version.Info()
+	metric := proj.PrimaryMetric()
+	d := data{
+		Project:     proj,
+		Metric:      metric,
+		Generated:   time.Now().UTC(),
+		Version:     v,
+		Index:       idx,
+		Experiments: len(exps),
+		Learnings:   learnings,
+		Graveyard:   graveyard,
+		Pinned:      idx.Pinned,
+	}
+	p := idx.Pinned
+	d.HasPinned = len(p.DoNotTry)+len(p.Deferred)+len(p.DataWarnings)+len(p.CriticalFeatures) > 0 || p.Notes != ""
+
+	byID := make(map[string]model.Experiment, len(exps))
+	counts := make(map[string]int)
+	for _, e := range exps {
+		byID[e.ID] = e
+		counts[e.Status]++
+	}
+	for status, n := range counts {
+		d.StatusCounts = append(d.StatusCounts, statusCount{status, n})
+	}
+	sort.Slice(d.StatusCounts, func(i, j int) bool { return d.StatusCounts[i].Status < d.StatusCounts[j].Status })
+
+	if best, ok := byID[idx.Computed.BestExperiment]; ok {
+		d.Best = &best
+	}
+	for _, id := range idx.Computed.ExperimentChain {
+		if e, ok := byID[id]; ok {
+			d.Chain = append(d.Chain, e)
+		}
+	}
+
+	d.Timeline = timelineChart(exps, metric)
+	d.ByTag = tagChart(exps, metric)
+	g, err := graph.Build(exps, idx.Computed.ExperimentChain, proj, graph.Filter{})
+	if err != nil {
+		return data{}, err
+	}
+	d.DAG = dagChart(g)
+
+	n := opts.Changelog
+	if n <= 0 {
+		n = DefaultChangelog
+	}
+	entries := cl.Entries
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		d.Changelog = append(d.Changelog, entries[i])
+	}
+	return d, nil
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ArtifactCopyPath is where the content-addressed copy of a file with the
+// given sha256 lives: artifacts/<first two hex digits>/<sha256>.
+func (s *Store) ArtifactCopyPath(sum string) string {
+	if len(sum) < 2 {
+		return filepath.Join(s.root, "artifacts", sum)
+	}
+	return filepath.Join(s.root, "artifacts", sum[:2], sum)
+}
+
+// StoreArtifact copies src into the artifact store under its sha256. The
+// copy is checked against sum, so a file changed since it was hashed is not
+// stored under the wrong name. An existing copy is kept.
+//
+// It does not take the store lock: the copy is written to a temp file and
+// renamed into place, and two writers of the same name write the same bytes.
+func (s *Store) StoreArtifact(src, sum string) error {
+	dst := s.ArtifactCopyPath(sum)
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".marrow-tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		return fmt.Errorf("%s changed while it was being stored", src)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -18,26 +18,28 @@ func (s *Store) ReadChangelog() (model.ChangelogFile, error) {
 const maxChangelogEntries = 1000
 
 func (s *Store) AppendChangelog(entry model.ChangelogEntry) error {
-	cf, err := s.ReadChangelog()
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("reading changelog: %w", err)
+	return s.WithLock(func(s *Store) error {
+		cf, err := s.ReadChangelog()
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("reading changelog: %w", err)
+			}
+			cf = model.ChangelogFile{}
 		}
-		cf = model.ChangelogFile{}
-	}
 
-	if entry.Timestamp.IsZero() {
-		entry.Timestamp = time.Now().UTC()
-	}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = time.Now().UTC()
+		}
 
-	cf.Entries = append(cf.Entries, entry)
+		cf.Entries = append(cf.Entries, entry)
 
-	// Rotate: keep only the most recent entries
-	if len(cf.Entries) > maxChangelogEntries {
-		cf.Entries = cf.Entries[len(cf.Entries)-maxChangelogEntries:]
-	}
+		// Rotate: keep only the most recent entries
+		if len(cf.Entries) > maxChangelogEntries {
+			cf.Entries = cf.Entries[len(cf.Entries)-maxChangelogEntries:]
+		}
 
-	return format.WriteYAML(s.changelogPath(), cf)
+		return format.WriteYAML(s.changelogPath(), cf)
+	})
 }
 
 func (s *Store) ReadChangelogSince(since time.Time) ([]model.ChangelogEntry, error) {
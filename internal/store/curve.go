@@ -0,0 +1,128 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// WriteCurve writes a metric series to experiments/<id>/curves/<name>.csv,
+// one "step,value" row per point, and returns it as an artifact of kind
+// "curve". An existing curve of the same name is replaced.
+func (s *Store) WriteCurve(id, name string, points []model.CurvePoint) (model.Artifact, error) {
+	if err := ValidateExperimentID(id); err != nil {
+		return model.Artifact{}, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("step,value\n")
+	for _, p := range points {
+		fmt.Fprintf(&buf, "%d,%s\n", p.Step, strconv.FormatFloat(p.Value, 'g', -1, 64))
+	}
+	a, err := s.writeExperimentFile(s.curvePath(id, name), "curve", buf.Bytes())
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("writing curve %s: %w", name, err)
+	}
+	return a, nil
+}
+
+// WriteGitDiff writes a patch of uncommitted changes to
+// experiments/<id>/git.diff and returns it as an artifact of kind "diff".
+func (s *Store) WriteGitDiff(id string, patch []byte) (model.Artifact, error) {
+	if err := ValidateExperimentID(id); err != nil {
+		return model.Artifact{}, err
+	}
+	a, err := s.writeExperimentFile(filepath.Join(s.experimentDir(id), "git.diff"), "diff", patch)
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("writing git diff: %w", err)
+	}
+	return a, nil
+}
+
+// writeExperimentFile atomically writes data to path under an experiment's
+// directory and describes it as an artifact of the given kind.
+func (s *Store) writeExperimentFile(path, kind string, data []byte) (model.Artifact, error) {
+	err := s.locked(func() error {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return format.WriteFileAtomic(path, data)
+	})
+	if err != nil {
+		return model.Artifact{}, err
+	}
+	sum := sha256.Sum256(data)
+	return model.Artifact{
+		Path:   s.projectRelative(path),
+		Kind:   kind,
+		Size:   int64(len(data)),
+		SHA256: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// ReadCurve reads the named curve of an experiment. A curve that was never
+// written returns an error satisfying os.IsNotExist.
+func (s *Store) ReadCurve(id, name string) ([]model.CurvePoint, error) {
+	if err := ValidateExperimentID(id); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.curvePath(id, name))
+	if err != nil {
+		return nil, err
+	}
+	var points []model.CurvePoint
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || (i == 0 && line == "step,value") {
+			continue
+		}
+		stepStr, valueStr, _ := strings.Cut(line, ",")
+		step, err := strconv.ParseInt(stepStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("curve %s line %d: invalid step %q", name, i+1, stepStr)
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("curve %s line %d: invalid value %q", name, i+1, valueStr)
+		}
+		points = append(points, model.CurvePoint{Step: step, Value: value})
+	}
+	return points, nil
+}
+
+// curvePath maps a metric name to its CSV file. Bytes that are not safe in
+// file names are percent-encoded, so "eval/loss" becomes "eval%2Floss" and
+// never shares a file with "eval_loss". A leading "." is encoded too, so no
+// name makes a hidden file or climbs out of the directory.
+func (s *Store) curvePath(id, name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			b.WriteByte(c)
+		case c == '.' && i > 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return filepath.Join(s.experimentDir(id), "curves", b.String()+".csv")
+}
+
+// projectRelative returns path relative to the project directory, with
+// forward slashes, as artifacts record it.
+func (s *Store) projectRelative(path string) string {
+	rel, err := filepath.Rel(filepath.Dir(s.root), path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -52,7 +52,9 @@ func (s *Store) WriteExperiment(exp model.Experiment) error {
 	if err := ValidateExperimentID(exp.ID); err != nil {
 		return err
 	}
-	return format.WriteYAML(s.experimentPath(exp.ID), exp)
+	return s.locked(func() error {
+		return format.WriteYAML(s.experimentPath(exp.ID), exp)
+	})
 }
 
 func (s *Store) ReadExperiment(id string) (model.Experiment, error) {
@@ -119,10 +121,15 @@ func (s *Store) DeleteExperiment(id string) error {
 		return err
 	}
 	path := s.experimentPath(id)
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("experiment %s not found", id)
-	}
-	return os.Remove(path)
+	return s.locked(func() error {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("experiment %s not found", id)
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return os.RemoveAll(s.experimentDir(id))
+	})
 }
 
 func (s *Store) FindParentRefs(id string) ([]string, error) {
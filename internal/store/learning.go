@@ -17,10 +17,22 @@ func (s *Store) ReadLearnings() (model.LearningsFile, error) {
 }
 
 func (s *Store) WriteLearnings(lf model.LearningsFile) error {
-	return format.WriteYAML(s.learningsPath(), lf)
+	return s.locked(func() error {
+		return format.WriteYAML(s.learningsPath(), lf)
+	})
 }
 
 func (s *Store) AddLearning(l model.Learning) (string, error) {
+	var id string
+	err := s.WithLock(func(s *Store) error {
+		var err error
+		id, err = s.addLearning(l)
+		return err
+	})
+	return id, err
+}
+
+func (s *Store) addLearning(l model.Learning) (string, error) {
 	lf, err := s.ReadLearnings()
 	if err != nil {
 		return "", fmt.Errorf("reading learnings: %w", err)
@@ -64,10 +76,22 @@ func (s *Store) ReadGraveyard() (model.GraveyardFile, error) {
 }
 
 func (s *Store) WriteGraveyard(gf model.GraveyardFile) error {
-	return format.WriteYAML(s.graveyardPath(), gf)
+	return s.locked(func() error {
+		return format.WriteYAML(s.graveyardPath(), gf)
+	})
 }
 
 func (s *Store) AddGraveyardEntry(g model.GraveyardEntry) (string, error) {
+	var id string
+	err := s.WithLock(func(s *Store) error {
+		var err error
+		id, err = s.addGraveyardEntry(g)
+		return err
+	})
+	return id, err
+}
+
+func (s *Store) addGraveyardEntry(g model.GraveyardEntry) (string, error) {
 	gf, err := s.ReadGraveyard()
 	if err != nil {
 		return "", fmt.Errorf("reading graveyard: %w", err)
@@ -93,6 +117,12 @@ func (s *Store) AddGraveyardEntry(g model.GraveyardEntry) (string, error) {
 }
 
 func (s *Store) DeleteLearning(id string) error {
+	return s.WithLock(func(s *Store) error {
+		return s.deleteLearning(id)
+	})
+}
+
+func (s *Store) deleteLearning(id string) error {
 	lf, err := s.ReadLearnings()
 	if err != nil {
 		return err
@@ -127,6 +157,12 @@ func (s *Store) DeleteLearning(id string) error {
 }
 
 func (s *Store) DeleteGraveyardEntry(id string) error {
+	return s.WithLock(func(s *Store) error {
+		return s.deleteGraveyardEntry(id)
+	})
+}
+
+func (s *Store) deleteGraveyardEntry(id string) error {
 	gf, err := s.ReadGraveyard()
 	if err != nil {
 		return err
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultLockTimeout is how long a write waits for another process to release
+// the .marrow/ lock before giving up.
+const DefaultLockTimeout = 10 * time.Second
+
+const lockPollInterval = 20 * time.Millisecond
+
+var ErrLockTimeout = errors.New("timed out waiting for .marrow/ lock")
+
+// storeLock is shared between a Store and the locked views handed out by
+// WithLock. The mutex serializes goroutines in this process; the flock on the
+// lock file serializes separate marrow processes.
+type storeLock struct {
+	mu      sync.Mutex
+	timeout time.Duration
+}
+
+func (s *Store) lockPath() string {
+	return filepath.Join(s.root, ".lock")
+}
+
+// SetLockTimeout changes how long writes wait for the lock.
+func (s *Store) SetLockTimeout(d time.Duration) {
+	s.lock.timeout = d
+}
+
+// WithLock runs fn while holding the .marrow/ write lock. The store passed to
+// fn already holds the lock, so read-modify-write sequences made through it
+// (allocate an ID, write, update the index) are atomic against other writers.
+func (s *Store) WithLock(fn func(*Store) error) error {
+	if s.held {
+		return fn(s)
+	}
+
+	unlock, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	view := *s
+	view.held = true
+	return fn(&view)
+}
+
+// locked runs a single mutating operation under the lock.
+func (s *Store) locked(op func() error) error {
+	return s.WithLock(func(*Store) error { return op() })
+}
+
+func (s *Store) acquire() (func(), error) {
+	timeout := s.lock.timeout
+	deadline := time.Now().Add(timeout)
+
+	// Wait for other goroutines in this process first so they do not spin on
+	// the file lock against each other.
+	for !s.lock.mu.TryLock() {
+		if time.Now().After(deadline) {
+			return nil, s.timeoutError(timeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	f, err := os.OpenFile(s.lockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		s.lock.mu.Unlock()
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			s.lock.mu.Unlock()
+			return nil, fmt.Errorf("locking %s: %w", s.lockPath(), err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			s.lock.mu.Unlock()
+			return nil, s.timeoutError(timeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+		s.lock.mu.Unlock()
+	}, nil
+}
+
+func (s *Store) timeoutError(timeout time.Duration) error {
+	return fmt.Errorf("%w after %s: another marrow process is writing to %s (remove %s only if no marrow process is running)",
+		ErrLockTimeout, timeout, s.root, s.lockPath())
+}
@@ -12,7 +12,9 @@ func (s *Store) ReadProject() (model.Project, error) {
 }
 
 func (s *Store) WriteProject(p model.Project) error {
-	return format.WriteYAML(s.projectPath(), p)
+	return s.locked(func() error {
+		return format.WriteYAML(s.projectPath(), p)
+	})
 }
 
 func (s *Store) ReadIndex() (model.Index, error) {
@@ -22,5 +24,7 @@ func (s *Store) ReadIndex() (model.Index, error) {
 }
 
 func (s *Store) WriteIndex(idx model.Index) error {
-	return format.WriteYAML(s.indexPath(), idx)
+	return s.locked(func() error {
+		return format.WriteYAML(s.indexPath(), idx)
+	})
 }
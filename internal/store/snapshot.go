@@ -0,0 +1,358 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// CreateSnapshot copies the current .marrow/ state (minus snapshots/ and the
+// content-addressed artifacts/, which only ever grows) into a timestamped
+// directory and returns its full name.
+func (s *Store) CreateSnapshot(name string) (string, error) {
+	if err := util.SafeName(name); err != nil {
+		return "", fmt.Errorf("invalid snapshot name: %w", err)
+	}
+	var fullName string
+	err := s.WithLock(func(s *Store) error {
+		var err error
+		fullName, err = s.createSnapshot(name, false)
+		return err
+	})
+	return fullName, err
+}
+
+// createSnapshot copies the state under a name prefixed with the current
+// second. With unique, a name already taken in that second gets a -2, -3,
+// ... suffix instead of failing, so automatic snapshots never collide.
+func (s *Store) createSnapshot(name string, unique bool) (string, error) {
+	stamp := time.Now().UTC().Format("20060102T150405")
+	fullName := stamp + "_" + name
+	dst := filepath.Join(s.snapshotsDir(), fullName)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(dst); err != nil {
+			break
+		}
+		if !unique {
+			return "", fmt.Errorf("snapshot %q already exists", fullName)
+		}
+		fullName = fmt.Sprintf("%s_%s-%d", stamp, name, n)
+		dst = filepath.Join(s.snapshotsDir(), fullName)
+	}
+	if err := copyDir(s.root, dst); err != nil {
+		return "", fmt.Errorf("creating snapshot: %w", err)
+	}
+	return fullName, nil
+}
+
+// ListSnapshots returns snapshot names, oldest first.
+func (s *Store) ListSnapshots() ([]string, error) {
+	entries, err := os.ReadDir(s.snapshotsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ResolveSnapshot accepts either a full snapshot name or the name given at
+// creation time (without the timestamp prefix).
+func (s *Store) ResolveSnapshot(name string) (string, error) {
+	if err := util.SafeName(name); err != nil {
+		return "", fmt.Errorf("invalid snapshot name: %w", err)
+	}
+	names, err := s.ListSnapshots()
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, n := range names {
+		if n == name {
+			return n, nil
+		}
+		if strings.HasSuffix(n, "_"+name) {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("snapshot %q not found", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("snapshot name %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// RestoreSnapshot replaces the live state with the named snapshot. The current
+// state is saved first as an automatic snapshot, whose name is returned.
+func (s *Store) RestoreSnapshot(name string) (string, error) {
+	var backup string
+	err := s.WithLock(func(s *Store) error {
+		fullName, err := s.ResolveSnapshot(name)
+		if err != nil {
+			return err
+		}
+
+		backup, err = s.createSnapshot("before-restore", true)
+		if err != nil {
+			return fmt.Errorf("saving current state: %w", err)
+		}
+
+		entries, err := os.ReadDir(s.root)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Name() == "snapshots" || e.Name() == "artifacts" || e.Name() == ".lock" {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(s.root, e.Name())); err != nil {
+				return fmt.Errorf("clearing current state (saved as %s): %w", backup, err)
+			}
+		}
+
+		if err := copyDir(filepath.Join(s.snapshotsDir(), fullName), s.root); err != nil {
+			return fmt.Errorf("restoring %s (previous state saved as %s): %w", fullName, backup, err)
+		}
+		return nil
+	})
+	return backup, err
+}
+
+// LogRestore records a restore in the changelog. The restore replaced the
+// changelog with the snapshot's copy, so the automatic backup is recorded
+// there first, as snapshot_created, followed by the restore itself.
+func (s *Store) LogRestore(name, backup string) error {
+	return s.WithLock(func(s *Store) error {
+		if err := s.AppendChangelog(model.ChangelogEntry{
+			Action:  "snapshot_created",
+			Summary: backup,
+		}); err != nil {
+			return err
+		}
+		return s.AppendChangelog(model.ChangelogEntry{
+			Action:  "snapshot_restored",
+			Summary: fmt.Sprintf("%s (previous state saved as %s)", name, backup),
+		})
+	})
+}
+
+func (s *Store) DeleteSnapshot(name string) (string, error) {
+	var fullName string
+	err := s.WithLock(func(s *Store) error {
+		var err error
+		fullName, err = s.ResolveSnapshot(name)
+		if err != nil {
+			return err
+		}
+		return os.RemoveAll(filepath.Join(s.snapshotsDir(), fullName))
+	})
+	return fullName, err
+}
+
+// DiffSnapshot reports which experiments, learnings, graveyard entries and
+// pinned items were added, removed or changed since the named snapshot.
+func (s *Store) DiffSnapshot(name string) (model.SnapshotDiff, error) {
+	fullName, err := s.ResolveSnapshot(name)
+	if err != nil {
+		return model.SnapshotDiff{}, err
+	}
+	snap := &Store{root: filepath.Join(s.snapshotsDir(), fullName), lock: s.lock}
+
+	diff := model.SnapshotDiff{Snapshot: fullName}
+
+	curExps, err := s.ListExperiments()
+	if err != nil {
+		return diff, err
+	}
+	oldExps, err := snap.ListExperiments()
+	if err != nil {
+		return diff, fmt.Errorf("reading snapshot experiments: %w", err)
+	}
+	diff.Experiments = diffByID(toMap(curExps, func(e model.Experiment) string { return e.ID }),
+		toMap(oldExps, func(e model.Experiment) string { return e.ID }))
+
+	curLearnings, err := s.ReadLearnings()
+	if err != nil && !os.IsNotExist(err) {
+		return diff, err
+	}
+	oldLearnings, err := snap.ReadLearnings()
+	if err != nil && !os.IsNotExist(err) {
+		return diff, fmt.Errorf("reading snapshot learnings: %w", err)
+	}
+	learningID := func(l model.Learning) string { return l.ID }
+	diff.Learnings = diffByID(
+		toMap(append(curLearnings.Proven, curLearnings.Assumptions...), learningID),
+		toMap(append(oldLearnings.Proven, oldLearnings.Assumptions...), learningID))
+
+	curGrave, err := s.ReadGraveyard()
+	if err != nil && !os.IsNotExist(err) {
+		return diff, err
+	}
+	oldGrave, err := snap.ReadGraveyard()
+	if err != nil && !os.IsNotExist(err) {
+		return diff, fmt.Errorf("reading snapshot graveyard: %w", err)
+	}
+	graveID := func(g model.GraveyardEntry) string { return g.ID }
+	diff.Graveyard = diffByID(toMap(curGrave.Entries, graveID), toMap(oldGrave.Entries, graveID))
+
+	curIdx, err := s.ReadIndex()
+	if err != nil && !os.IsNotExist(err) {
+		return diff, err
+	}
+	oldIdx, err := snap.ReadIndex()
+	if err != nil && !os.IsNotExist(err) {
+		return diff, fmt.Errorf("reading snapshot index: %w", err)
+	}
+	diff.Pinned = diffPinned(curIdx.Pinned, oldIdx.Pinned)
+
+	return diff, nil
+}
+
+func toMap[T any](items []T, key func(T) string) map[string]T {
+	m := make(map[string]T, len(items))
+	for _, it := range items {
+		m[key(it)] = it
+	}
+	return m
+}
+
+func diffByID[T any](cur, old map[string]T) model.DiffSet {
+	var d model.DiffSet
+	for id, c := range cur {
+		o, ok := old[id]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, id)
+		case !reflect.DeepEqual(c, o):
+			d.Changed = append(d.Changed, id)
+		}
+	}
+	for id := range old {
+		if _, ok := cur[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
+
+func diffPinned(cur, old model.PinnedIndex) model.DiffSet {
+	var d model.DiffSet
+	lists := []struct {
+		field    string
+		cur, old []string
+	}{
+		{"do_not_try", cur.DoNotTry, old.DoNotTry},
+		{"deferred", cur.Deferred, old.Deferred},
+		{"data_warnings", cur.DataWarnings, old.DataWarnings},
+		{"critical_features", cur.CriticalFeatures, old.CriticalFeatures},
+	}
+	for _, l := range lists {
+		oldSet := make(map[string]bool, len(l.old))
+		for _, v := range l.old {
+			oldSet[v] = true
+		}
+		curSet := make(map[string]bool, len(l.cur))
+		for _, v := range l.cur {
+			curSet[v] = true
+			if !oldSet[v] {
+				d.Added = append(d.Added, l.field+": "+v)
+			}
+		}
+		for _, v := range l.old {
+			if !curSet[v] {
+				d.Removed = append(d.Removed, l.field+": "+v)
+			}
+		}
+	}
+	if cur.Notes != old.Notes {
+		d.Changed = append(d.Changed, "notes")
+	}
+	return d
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(src, path)
+		if skipInCopy(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// The lock file belongs to the live store and temp files are
+		// half-written YAML; neither belongs in a copy.
+		if rel == ".lock" || strings.HasPrefix(info.Name(), ".marrow-tmp-") {
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+
+		return copyFile(path, target)
+	})
+}
+
+// skipInCopy reports whether a path relative to .marrow/ stays out of
+// snapshot copies.
+func skipInCopy(rel string) bool {
+	for _, dir := range []string{"snapshots", "artifacts"} {
+		if rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
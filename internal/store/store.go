@@ -14,10 +14,15 @@ const marrowDir = ".marrow"
 
 type Store struct {
 	root string // absolute path to the .marrow/ directory
+	lock *storeLock
+	held bool // set on the view passed to WithLock callbacks
 }
 
 func New(projectDir string) *Store {
-	return &Store{root: filepath.Join(projectDir, marrowDir)}
+	return &Store{
+		root: filepath.Join(projectDir, marrowDir),
+		lock: &storeLock{timeout: DefaultLockTimeout},
+	}
 }
 
 func (s *Store) Root() string {
@@ -91,6 +96,12 @@ func (s *Store) experimentPath(id string) string {
 	return filepath.Join(s.root, "experiments", id+".yaml")
 }
 
+// experimentDir holds files that belong to one experiment, such as its
+// curves.
+func (s *Store) experimentDir(id string) string {
+	return filepath.Join(s.root, "experiments", id)
+}
+
 func (s *Store) learningsPath() string {
 	return filepath.Join(s.root, "learnings", "learnings.yaml")
 }
@@ -0,0 +1,416 @@
+// Package tui implements "marrow tui", a terminal browser for the experiment
+// DAG. It draws with plain ANSI escapes so the store stays dependency-free.
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/index"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+type mode int
+
+const (
+	modeBrowse    mode = iota
+	modeStatus         // waiting for a status key
+	modeTags           // editing the selected experiment's tags
+	modeTagFilter      // typing a tag filter
+)
+
+type panel int
+
+const (
+	panelLearnings panel = iota
+	panelGraveyard
+	panelPinned
+	panelCount
+)
+
+var panelNames = [panelCount]string{"Learnings", "Graveyard", "Pinned"}
+
+var statusKeys = map[string]string{"i": "improved", "d": "degraded", "n": "neutral", "f": "failed"}
+
+var statusCycle = []string{"", "improved", "degraded", "neutral", "failed"}
+
+// App is the TUI state. Feed applies keyboard input and View renders a
+// frame, so the whole interface can be driven without a terminal.
+type App struct {
+	store *store.Store
+	color bool
+
+	proj      model.Project
+	idx       model.Index
+	learnings model.LearningsFile
+	graveyard model.GraveyardFile
+	rows      []row
+	chain     map[string]bool
+
+	statusFilter string
+	tagFilter    string
+	visible      []int // indexes into rows shown under the current filters
+	match        map[int]bool
+
+	cursor   int // index into visible
+	top      int // first visible row on screen
+	scroll   int // details pane offset
+	panel    panel
+	mode     mode
+	input    string
+	message  string
+	bodyRows int // tree height from the last View, for paging
+}
+
+// NewApp loads the store into a new App. Colors are off when NO_COLOR is set.
+func NewApp(s *store.Store) (*App, error) {
+	a := &App{store: s, color: os.Getenv("NO_COLOR") == "", bodyRows: 10}
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *App) reload() error {
+	proj, err := a.store.ReadProject()
+	if err != nil {
+		return fmt.Errorf("reading project: %w", err)
+	}
+	exps, err := a.store.ListExperiments()
+	if err != nil {
+		return fmt.Errorf("listing experiments: %w", err)
+	}
+	idx, err := a.store.ReadIndex()
+	if err != nil {
+		return fmt.Errorf("reading index: %w", err)
+	}
+	learnings, err := a.store.ReadLearnings()
+	if err != nil {
+		return fmt.Errorf("reading learnings: %w", err)
+	}
+	graveyard, err := a.store.ReadGraveyard()
+	if err != nil {
+		return fmt.Errorf("reading graveyard: %w", err)
+	}
+
+	selected := a.selectedID()
+	a.proj, a.idx, a.learnings, a.graveyard = proj, idx, learnings, graveyard
+	a.rows = buildTree(exps)
+	a.chain = make(map[string]bool, len(idx.Computed.ExperimentChain))
+	for _, id := range idx.Computed.ExperimentChain {
+		a.chain[id] = true
+	}
+	a.applyFilter()
+	a.selectID(selected)
+	return nil
+}
+
+// applyFilter shows experiments matching the status and tag filters, plus
+// their ancestors in the tree (dimmed) so the matches keep their context.
+func (a *App) applyFilter() {
+	a.visible = a.visible[:0]
+	a.match = make(map[int]bool)
+	if a.statusFilter == "" && a.tagFilter == "" {
+		for i := range a.rows {
+			a.visible = append(a.visible, i)
+			a.match[i] = true
+		}
+		return
+	}
+
+	keep := make([]bool, len(a.rows))
+	depth := func(i int) int { return len([]rune(a.rows[i].prefix)) }
+	for i, r := range a.rows {
+		if !a.matches(r.exp) {
+			continue
+		}
+		a.match[i] = true
+		keep[i] = true
+		// Walk back up to each shallower row: those are the ancestors.
+		d := depth(i)
+		for j := i - 1; j >= 0 && d > 0; j-- {
+			if depth(j) < d {
+				keep[j] = true
+				d = depth(j)
+			}
+		}
+	}
+	for i := range a.rows {
+		if keep[i] {
+			a.visible = append(a.visible, i)
+		}
+	}
+}
+
+func (a *App) matches(e model.Experiment) bool {
+	if a.statusFilter != "" && e.Status != a.statusFilter {
+		return false
+	}
+	if a.tagFilter != "" {
+		for _, t := range e.Tags {
+			if strings.EqualFold(t, a.tagFilter) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (a *App) selected() (model.Experiment, bool) {
+	if a.cursor < 0 || a.cursor >= len(a.visible) {
+		return model.Experiment{}, false
+	}
+	return a.rows[a.visible[a.cursor]].exp, true
+}
+
+func (a *App) selectedID() string {
+	e, _ := a.selected()
+	return e.ID
+}
+
+// selectID moves the cursor to id, or clamps it when id is not visible.
+func (a *App) selectID(id string) {
+	for i, ri := range a.visible {
+		if a.rows[ri].exp.ID == id {
+			a.cursor = i
+			return
+		}
+	}
+	a.moveTo(a.cursor)
+}
+
+func (a *App) moveTo(i int) {
+	if i >= len(a.visible) {
+		i = len(a.visible) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i != a.cursor {
+		a.scroll = 0
+	}
+	a.cursor = i
+}
+
+// Feed applies raw terminal input and reports whether the user quit.
+func (a *App) Feed(input []byte) bool {
+	for _, k := range parseKeys(input) {
+		if a.handle(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) handle(k string) bool {
+	if k == keyCtrlC {
+		return true
+	}
+	switch a.mode {
+	case modeStatus:
+		a.mode = modeBrowse
+		if status, ok := statusKeys[k]; ok {
+			a.setStatus(status)
+		}
+		return false
+	case modeTags, modeTagFilter:
+		a.handleInput(k)
+		return false
+	}
+
+	a.message = ""
+	switch k {
+	case "q":
+		return true
+	case keyUp, "k":
+		a.moveTo(a.cursor - 1)
+	case keyDown, "j":
+		a.moveTo(a.cursor + 1)
+	case keyPageUp:
+		a.moveTo(a.cursor - a.bodyRows)
+	case keyPageDown:
+		a.moveTo(a.cursor + a.bodyRows)
+	case keyHome, "g":
+		a.moveTo(0)
+	case keyEnd, "G":
+		a.moveTo(len(a.visible) - 1)
+	case "]":
+		a.scroll++
+	case "[":
+		if a.scroll > 0 {
+			a.scroll--
+		}
+	case keyTab:
+		a.panel = (a.panel + 1) % panelCount
+	case "b":
+		if best := a.idx.Computed.BestExperiment; best != "" {
+			a.selectID(best)
+		}
+	case "s":
+		if _, ok := a.selected(); ok {
+			a.mode = modeStatus
+		}
+	case "e":
+		if e, ok := a.selected(); ok {
+			a.mode = modeTags
+			a.input = strings.Join(e.Tags, ",")
+		}
+	case "t":
+		a.mode = modeTagFilter
+		a.input = a.tagFilter
+	case "f":
+		for i, st := range statusCycle {
+			if st == a.statusFilter {
+				a.statusFilter = statusCycle[(i+1)%len(statusCycle)]
+				break
+			}
+		}
+		a.refilter()
+	case "c":
+		a.statusFilter, a.tagFilter = "", ""
+		a.refilter()
+	case "r":
+		if err := a.reload(); err != nil {
+			a.message = "reload failed: " + err.Error()
+		} else {
+			a.message = "reloaded"
+		}
+	}
+	return false
+}
+
+func (a *App) handleInput(k string) {
+	switch k {
+	case keyEsc:
+		a.mode = modeBrowse
+	case keyEnter:
+		m := a.mode
+		a.mode = modeBrowse
+		if m == modeTags {
+			a.setTags(util.SplitTags(a.input))
+		} else {
+			a.tagFilter = strings.TrimSpace(a.input)
+			a.refilter()
+		}
+	case keyBackspace:
+		if r := []rune(a.input); len(r) > 0 {
+			a.input = string(r[:len(r)-1])
+		}
+	default:
+		if len([]rune(k)) == 1 {
+			a.input += k
+		}
+	}
+}
+
+func (a *App) refilter() {
+	id := a.selectedID()
+	a.applyFilter()
+	a.selectID(id)
+}
+
+func (a *App) setStatus(status string) {
+	e, _ := a.selected()
+	a.edit(e.ID, fmt.Sprintf("status → %s", status), func(exp *model.Experiment) {
+		exp.Status = status
+	})
+}
+
+func (a *App) setTags(tags []string) {
+	e, _ := a.selected()
+	a.edit(e.ID, fmt.Sprintf("tags → %s", strings.Join(tags, ",")), func(exp *model.Experiment) {
+		exp.Tags = tags
+	})
+}
+
+// edit applies change to an experiment the same way "marrow exp edit" does:
+// under the store lock, followed by an index rebuild and a changelog entry.
+func (a *App) edit(id, what string, change func(*model.Experiment)) {
+	var warnings []string
+	err := a.store.WithLock(func(s *store.Store) error {
+		exp, err := s.ReadExperiment(id)
+		if err != nil {
+			return fmt.Errorf("reading experiment %s: %w", id, err)
+		}
+		change(&exp)
+		if err := s.WriteExperiment(exp); err != nil {
+			return err
+		}
+		if _, err := index.Rebuild(s); err != nil {
+			warnings = append(warnings, "index rebuild failed: "+err.Error())
+		}
+		if err := s.AppendChangelog(model.ChangelogEntry{
+			Action:  "exp_edited",
+			ID:      id,
+			Summary: "edited experiment " + id + ": " + what,
+		}); err != nil {
+			warnings = append(warnings, "changelog append failed: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		a.message = "edit failed: " + err.Error()
+		return
+	}
+	if err := a.reload(); err != nil {
+		warnings = append(warnings, "reload failed: "+err.Error())
+	}
+	a.message = fmt.Sprintf("%s: %s", id, what)
+	if len(warnings) > 0 {
+		a.message += " (warning: " + strings.Join(warnings, "; ") + ")"
+	}
+}
+
+// sidePanel returns the lines of the active side panel.
+func (a *App) sidePanel() []string {
+	var lines []string
+	switch a.panel {
+	case panelLearnings:
+		for _, l := range a.learnings.Proven {
+			lines = append(lines, format.LearningOneLiner(l))
+		}
+		for _, l := range a.learnings.Assumptions {
+			lines = append(lines, format.LearningOneLiner(l))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No learnings yet.")
+		}
+	case panelGraveyard:
+		for _, g := range a.graveyard.Entries {
+			lines = append(lines, format.GraveyardOneLiner(g))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "The graveyard is empty.")
+		}
+	case panelPinned:
+		p := a.idx.Pinned
+		section := func(title, mark string, items []string) {
+			if len(items) == 0 {
+				return
+			}
+			lines = append(lines, title+":")
+			for _, it := range items {
+				lines = append(lines, "  "+mark+" "+it)
+			}
+		}
+		section("Do not try", "✗", p.DoNotTry)
+		section("Deferred", "·", p.Deferred)
+		section("Data warnings", "⚠", p.DataWarnings)
+		section("Critical features", "★", p.CriticalFeatures)
+		if p.Notes != "" {
+			lines = append(lines, "Notes:")
+			for _, n := range strings.Split(strings.TrimRight(p.Notes, "\n"), "\n") {
+				lines = append(lines, "  "+n)
+			}
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "Nothing pinned.")
+		}
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+package tui
+
+import "unicode/utf8"
+
+// Special keys. Printable input is passed through as the typed character.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEsc       = "esc"
+	keyTab       = "tab"
+	keyBackspace = "backspace"
+	keyCtrlC     = "ctrl+c"
+)
+
+var escapes = map[string]string{
+	"\x1b[A": keyUp, "\x1b[B": keyDown, "\x1b[C": keyRight, "\x1b[D": keyLeft,
+	"\x1bOA": keyUp, "\x1bOB": keyDown, "\x1bOC": keyRight, "\x1bOD": keyLeft,
+	"\x1b[5~": keyPageUp, "\x1b[6~": keyPageDown,
+	"\x1b[H": keyHome, "\x1b[F": keyEnd, "\x1b[1~": keyHome, "\x1b[4~": keyEnd,
+}
+
+// parseKeys splits raw terminal input into keys. Unknown escape sequences
+// are dropped; a lone ESC is the escape key.
+func parseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			n := escapeLen(b)
+			if k, ok := escapes[string(b[:n])]; ok {
+				keys = append(keys, k)
+			} else if n == 1 {
+				keys = append(keys, keyEsc)
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, keyEnter)
+		case c == '\t':
+			keys = append(keys, keyTab)
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, keyBackspace)
+		case c == 0x03:
+			keys = append(keys, keyCtrlC)
+		case c < 0x20:
+			// other control characters have no binding
+		default:
+			r, n := utf8.DecodeRune(b)
+			keys = append(keys, string(r))
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeLen returns the length of the escape sequence at the start of b:
+// ESC [ params final, ESC O x, or a lone ESC.
+func escapeLen(b []byte) int {
+	if len(b) < 2 {
+		return 1
+	}
+	switch b[1] {
+	case 'O':
+		if len(b) >= 3 {
+			return 3
+		}
+		return 2
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(b)
+	}
+	return 1
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+// Run starts the interactive UI on the process's terminal and returns when
+// the user quits.
+func Run(s *store.Store) error {
+	app, err := NewApp(s)
+	if err != nil {
+		return err
+	}
+
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("\x1b[?1049h\x1b[?25l") // alternate screen, hide cursor
+	defer func() {
+		out.WriteString("\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	input := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			b := make([]byte, n)
+			copy(b, buf[:n])
+			input <- b
+		}
+	}()
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+
+	for {
+		width, height, err := termSize(os.Stdin)
+		if err != nil {
+			width, height = 80, 24
+		}
+		frame := app.View(width, height)
+		out.WriteString("\x1b[H")
+		out.WriteString(strings.ReplaceAll(frame, "\n", "\x1b[K\r\n"))
+		out.WriteString("\x1b[K")
+		if err := out.Flush(); err != nil {
+			return err
+		}
+
+		select {
+		case b, ok := <-input:
+			if !ok || app.Feed(b) {
+				return nil
+			}
+		case <-resize:
+			out.WriteString("\x1b[2J")
+		}
+	}
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// makeRaw puts the terminal behind in into raw mode with stty and returns a
+// function that restores the previous settings.
+func makeRaw(in *os.File) (func(), error) {
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("marrow tui needs an interactive terminal: %w", err)
+	}
+	if _, err := stty(in, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(in, strings.TrimSpace(saved)) }, nil
+}
+
+// termSize returns the terminal's columns and rows.
+func termSize(in *os.File) (int, int, error) {
+	out, err := stty(in, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	var rows, cols int
+	if _, err := fmt.Sscanf(out, "%d %d", &rows, &cols); err != nil {
+		return 0, 0, fmt.Errorf("parsing stty size %q: %w", out, err)
+	}
+	return cols, rows, nil
+}
+
+// notifyResize delivers terminal resize events on ch.
+func notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
+
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
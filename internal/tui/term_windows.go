@@ -0,0 +1,18 @@
+//go:build windows
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+func makeRaw(*os.File) (func(), error) {
+	return nil, errors.New("marrow tui is not supported on Windows yet")
+}
+
+func termSize(*os.File) (int, int, error) {
+	return 0, 0, errors.New("terminal size unavailable")
+}
+
+func notifyResize(chan<- os.Signal) {}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"sort"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// row is one line of the experiment tree.
+type row struct {
+	exp    model.Experiment
+	prefix string   // box-drawing indent, e.g. "│  ├─ "
+	extra  []string // parents besides the one the row is drawn under
+}
+
+// buildTree lays the experiment DAG out as a tree. Each experiment is drawn
+// under its first known parent; further parents are listed on the row.
+// Experiments whose parents are all missing become roots.
+func buildTree(exps []model.Experiment) []row {
+	byID := make(map[string]model.Experiment, len(exps))
+	for _, e := range exps {
+		byID[e.ID] = e
+	}
+
+	children := make(map[string][]model.Experiment)
+	var roots []model.Experiment
+	for _, e := range exps {
+		parent := ""
+		for _, p := range e.Parents {
+			if _, ok := byID[p]; ok && p != e.ID {
+				parent = p
+				break
+			}
+		}
+		if parent == "" {
+			roots = append(roots, e)
+		} else {
+			children[parent] = append(children[parent], e)
+		}
+	}
+	sortByID := func(list []model.Experiment) {
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	}
+	sortByID(roots)
+	for _, list := range children {
+		sortByID(list)
+	}
+
+	var rows []row
+	visited := make(map[string]bool)
+	var walk func(e model.Experiment, indent string, last, root bool)
+	walk = func(e model.Experiment, indent string, last, root bool) {
+		if visited[e.ID] {
+			return
+		}
+		visited[e.ID] = true
+
+		prefix, childIndent := "", ""
+		if !root {
+			if last {
+				prefix, childIndent = indent+"└─ ", indent+"   "
+			} else {
+				prefix, childIndent = indent+"├─ ", indent+"│  "
+			}
+		}
+
+		r := row{exp: e, prefix: prefix}
+		drawnUnder := ""
+		for _, p := range e.Parents {
+			if _, ok := byID[p]; ok {
+				if drawnUnder == "" {
+					drawnUnder = p
+					continue
+				}
+				r.extra = append(r.extra, p)
+			}
+		}
+		rows = append(rows, r)
+
+		kids := children[e.ID]
+		for i, c := range kids {
+			walk(c, childIndent, i == len(kids)-1, false)
+		}
+	}
+	for _, r := range roots {
+		walk(r, "", true, true)
+	}
+
+	// A parent cycle leaves experiments unreachable from any root; list them
+	// at the top level rather than hiding them.
+	for _, e := range exps {
+		if !visited[e.ID] {
+			walk(e, "", true, true)
+		}
+	}
+	return rows
+}
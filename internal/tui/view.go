@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rzzdr/marrow/internal/format"
+)
+
+const (
+	sgrReset   = "\x1b[0m"
+	sgrBold    = "\x1b[1m"
+	sgrDim     = "\x1b[2m"
+	sgrReverse = "\x1b[7m"
+	sgrGreen   = "\x1b[32m"
+	sgrRed     = "\x1b[31m"
+	sgrYellow  = "\x1b[33m"
+	sgrCyan    = "\x1b[36m"
+)
+
+const helpLine = "↑↓ move  b best  s status  e tags  t tag filter  f status filter  c clear  tab panel  [ ] scroll  r reload  q quit"
+
+// View renders a width×height frame as newline-separated lines.
+func (a *App) View(width, height int) string {
+	if width < 40 {
+		width = 40
+	}
+	if height < 10 {
+		height = 10
+	}
+
+	body := height - 4 // header, two rules, footer
+	a.bodyRows = body
+	left := width * 45 / 100
+	right := width - left - 3
+
+	lines := make([]string, 0, height)
+	lines = append(lines, a.style(sgrBold, fit(a.header(), width)))
+	lines = append(lines, a.style(sgrDim, strings.Repeat("─", width)))
+
+	tree := a.treeLines(left, body)
+	side := a.rightLines(right, body)
+	sep := a.style(sgrDim, " │ ")
+	for i := 0; i < body; i++ {
+		lines = append(lines, tree[i]+sep+side[i])
+	}
+
+	lines = append(lines, a.style(sgrDim, strings.Repeat("─", width)))
+	lines = append(lines, a.footer(width))
+	return strings.Join(lines, "\n")
+}
+
+func (a *App) header() string {
+	h := "marrow · " + a.proj.Name
+	if c := a.idx.Computed; c.BestExperiment != "" && c.BestMetric != nil {
+		h += fmt.Sprintf("   best %s (%s %.4f)", c.BestExperiment, c.BestMetric.Name, c.BestMetric.Value)
+	}
+	h += fmt.Sprintf("   %d experiments", len(a.rows))
+	var filters []string
+	if a.statusFilter != "" {
+		filters = append(filters, "status="+a.statusFilter)
+	}
+	if a.tagFilter != "" {
+		filters = append(filters, "tag="+a.tagFilter)
+	}
+	if len(filters) > 0 {
+		h += "   filter: " + strings.Join(filters, " ")
+	}
+	return h
+}
+
+func (a *App) footer(width int) string {
+	switch a.mode {
+	case modeStatus:
+		return a.style(sgrYellow, fit("status: [i]mproved [d]egraded [n]eutral [f]ailed  (any other key cancels)", width))
+	case modeTags:
+		return a.style(sgrYellow, fit("tags (comma-separated, enter saves, esc cancels): "+a.input+"▏", width))
+	case modeTagFilter:
+		return a.style(sgrYellow, fit("filter by tag (empty clears): "+a.input+"▏", width))
+	}
+	if a.message != "" {
+		return a.style(sgrCyan, fit(a.message, width))
+	}
+	return a.style(sgrDim, fit(helpLine, width))
+}
+
+// treeLines renders the visible part of the tree, keeping the cursor on
+// screen. Experiments on the winning chain are starred and green.
+func (a *App) treeLines(width, height int) []string {
+	if a.cursor < a.top {
+		a.top = a.cursor
+	}
+	if a.cursor >= a.top+height {
+		a.top = a.cursor - height + 1
+	}
+	if a.top < 0 {
+		a.top = 0
+	}
+
+	lines := make([]string, height)
+	if len(a.visible) == 0 {
+		msg := "No experiments yet."
+		if a.statusFilter != "" || a.tagFilter != "" {
+			msg = "No experiments match the filter."
+		}
+		lines[0] = fit(msg, width)
+		for i := 1; i < height; i++ {
+			lines[i] = fit("", width)
+		}
+		return lines
+	}
+
+	for i := 0; i < height; i++ {
+		vi := a.top + i
+		if vi >= len(a.visible) {
+			lines[i] = fit("", width)
+			continue
+		}
+		ri := a.visible[vi]
+		r := a.rows[ri]
+		e := r.exp
+
+		mark := "  "
+		if a.chain[e.ID] {
+			mark = "★ "
+		}
+		text := fmt.Sprintf("%s%s%s %-8s %.4f", r.prefix, mark, e.ID, e.Status, e.PrimaryValue(a.proj.PrimaryMetric()))
+		if len(r.extra) > 0 {
+			text += " (+" + strings.Join(r.extra, ",") + ")"
+		}
+		if len(e.Tags) > 0 {
+			text += " [" + strings.Join(e.Tags, ",") + "]"
+		}
+		text = fit(text, width)
+
+		switch {
+		case vi == a.cursor:
+			// Reverse video is not a color, so the cursor shows under NO_COLOR too.
+			text = sgrReverse + text + sgrReset
+		case !a.match[ri]:
+			text = a.style(sgrDim, text)
+		case a.chain[e.ID]:
+			text = a.style(sgrGreen+sgrBold, text)
+		case e.Status == "failed":
+			text = a.style(sgrRed, text)
+		}
+		lines[i] = text
+	}
+	return lines
+}
+
+// rightLines stacks the details pane (the selected experiment as YAML) over
+// the side panel tabs.
+func (a *App) rightLines(width, height int) []string {
+	panelHeight := height * 2 / 5
+	if panelHeight < 3 {
+		panelHeight = 3
+	}
+	detailHeight := height - panelHeight - 1
+
+	var detail []string
+	if e, ok := a.selected(); ok {
+		if y, err := format.MarshalYAMLString(e); err != nil {
+			detail = []string{"error: " + err.Error()}
+		} else {
+			detail = strings.Split(strings.TrimRight(y, "\n"), "\n")
+		}
+	}
+	if limit := len(detail) - detailHeight; a.scroll > limit {
+		a.scroll = limit
+	}
+	if a.scroll < 0 {
+		a.scroll = 0
+	}
+
+	lines := make([]string, 0, height)
+	for i := 0; i < detailHeight; i++ {
+		line := ""
+		if j := a.scroll + i; j < len(detail) {
+			line = detail[j]
+		}
+		lines = append(lines, fit(line, width))
+	}
+
+	var tabs []string
+	for p := panel(0); p < panelCount; p++ {
+		if p == a.panel {
+			tabs = append(tabs, "["+panelNames[p]+"]")
+		} else {
+			tabs = append(tabs, " "+panelNames[p]+" ")
+		}
+	}
+	lines = append(lines, a.style(sgrBold, fit(strings.Join(tabs, " "), width)))
+
+	content := a.sidePanel()
+	for i := 0; i < panelHeight; i++ {
+		line := ""
+		switch {
+		case i == panelHeight-1 && len(content) > panelHeight:
+			line = fmt.Sprintf("… %d more", len(content)-panelHeight+1)
+		case i < len(content):
+			line = content[i]
+		}
+		lines = append(lines, fit(line, width))
+	}
+	return lines
+}
+
+func (a *App) style(sgr, s string) string {
+	if !a.color {
+		return s
+	}
+	return sgr + s + sgrReset
+}
+
+// fit pads or truncates s to exactly width runes.
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n == width {
+		return s
+	}
+	if n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	r := []rune(s)
+	if width <= 1 {
+		return string(r[:width])
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,180 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+var parentPrefix = regexp.MustCompile(`^(exp_\d{3,}):`)
+
+var gitCommitPattern = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
+
+// ValidGitCommit reports whether s is a hex commit hash, full or
+// abbreviated. Anything else, such as a value starting with "-", must never
+// reach git as a revision.
+func ValidGitCommit(s string) bool {
+	return gitCommitPattern.MatchString(s)
+}
+
+// ParseChange parses a change spec such as "param:lr:0.1->0.01",
+// "added:target_encoding", "removed:dropout" or "changed:optimizer:adam->sgd".
+// A leading experiment ID ("exp_002:param:lr:0.1->0.01") names the parent the
+// change is relative to; parent is empty when the spec has none.
+func ParseChange(spec string) (parent string, c model.Change, err error) {
+	body := strings.TrimSpace(spec)
+	if m := parentPrefix.FindStringSubmatch(body); m != nil {
+		parent = m[1]
+		body = body[len(m[0]):]
+	}
+
+	typ, rest, _ := strings.Cut(body, ":")
+	c.Type = strings.TrimSpace(typ)
+	name, value, hasValue := strings.Cut(rest, ":")
+	name = strings.TrimSpace(name)
+
+	switch c.Type {
+	case "param":
+		c.Param = name
+	case "added", "removed", "changed":
+		c.What = name
+	}
+	if hasValue {
+		if from, to, ok := strings.Cut(value, "->"); ok {
+			c.From, c.To = strings.TrimSpace(from), strings.TrimSpace(to)
+		} else {
+			c.To = strings.TrimSpace(value)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return "", model.Change{}, fmt.Errorf("invalid change %q: %w", spec, err)
+	}
+	return parent, c, nil
+}
+
+// ParseChanges parses change specs and groups them by parent. Specs without a
+// parent prefix apply to the only parent, so experiments with several
+// parents must prefix every spec.
+func ParseChanges(specs, parents []string) (map[string][]model.Change, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]bool, len(parents))
+	for _, p := range parents {
+		known[p] = true
+	}
+
+	changes := make(map[string][]model.Change)
+	for _, spec := range specs {
+		parent, c, err := ParseChange(spec)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case parent != "" && !known[parent]:
+			return nil, fmt.Errorf("change %q refers to %s, which is not a parent", spec, parent)
+		case parent == "" && len(parents) == 0:
+			return nil, fmt.Errorf("change %q needs a parent experiment", spec)
+		case parent == "" && len(parents) > 1:
+			return nil, fmt.Errorf("change %q is ambiguous with several parents: prefix it with the parent ID (e.g. %s:%s)", spec, parents[0], spec)
+		case parent == "":
+			parent = parents[0]
+		}
+		changes[parent] = append(changes[parent], c)
+	}
+	return changes, nil
+}
+
+// ParseEvidence parses "exp_id=observation" specs into a reasoning evidence map.
+func ParseEvidence(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	evidence := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		id, obs, ok := strings.Cut(spec, "=")
+		id, obs = strings.TrimSpace(id), strings.TrimSpace(obs)
+		if !ok || id == "" || obs == "" {
+			return nil, fmt.Errorf("invalid evidence %q: expected exp_id=observation", spec)
+		}
+		if _, dup := evidence[id]; dup {
+			return nil, fmt.Errorf("evidence for %s given more than once", id)
+		}
+		evidence[id] = obs
+	}
+	return evidence, nil
+}
+
+// ParseReasoning assembles and validates an experiment's reasoning from its
+// text, type and evidence specs. The type defaults to unknown when text or
+// evidence is given.
+func ParseReasoning(text, typ string, evidenceSpecs []string) (model.Reasoning, error) {
+	evidence, err := ParseEvidence(evidenceSpecs)
+	if err != nil {
+		return model.Reasoning{}, err
+	}
+	r := model.Reasoning{Type: typ, Text: text, Evidence: evidence}
+	if r.Type == "" && (r.Text != "" || len(r.Evidence) > 0) {
+		r.Type = "unknown"
+	}
+	return r, r.Validate()
+}
+
+// ParseEnvironment parses comma-separated key=value pairs such as
+// "python=3.11,gpu=A100,split_seed=42,torch=2.3". python, gpu, data_hash,
+// split_seed, preprocessing_hash, git_commit and git_branch fill the
+// matching fields; any other key is recorded as a key package version.
+func ParseEnvironment(s string) (*model.Environment, error) {
+	pairs := SplitTags(s)
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	env := &model.Environment{}
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid environment entry %q: expected key=value", pair)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("environment key %q given more than once", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "python":
+			env.Python = value
+		case "gpu":
+			env.GPU = value
+		case "data_hash":
+			env.DataHash = value
+		case "preprocessing_hash":
+			env.PreprocessingHash = value
+		case "git_commit":
+			value = strings.ToLower(value)
+			if !ValidGitCommit(value) {
+				return nil, fmt.Errorf("invalid git_commit %q: must be a hex commit hash", value)
+			}
+			env.GitCommit = value
+		case "git_branch":
+			env.GitBranch = value
+		case "split_seed":
+			seed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid split_seed %q: must be an integer", value)
+			}
+			env.SplitSeed = &seed
+		default:
+			if env.KeyPackages == nil {
+				env.KeyPackages = make(map[string]string)
+			}
+			env.KeyPackages[key] = value
+		}
+	}
+	return env, nil
+}
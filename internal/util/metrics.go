@@ -0,0 +1,32 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMetrics parses comma-separated name=value pairs such as
+// "f1=0.71,latency_ms=12".
+func ParseMetrics(s string) (map[string]float64, error) {
+	metrics := make(map[string]float64)
+	for _, pair := range SplitTags(s) {
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid metric %q: expected name=value", pair)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for metric %q: %w", name, err)
+		}
+		if _, dup := metrics[name]; dup {
+			return nil, fmt.Errorf("metric %q given more than once", name)
+		}
+		metrics[name] = v
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+	return metrics, nil
+}
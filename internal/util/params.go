@@ -0,0 +1,190 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadParamsFile reads a YAML or JSON config file and flattens it with
+// FlattenParams. Files ending in .json are parsed as JSON, anything else as
+// YAML.
+func LoadParamsFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading params file: %w", err)
+	}
+
+	var raw any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing params file %s: %w", path, err)
+	}
+
+	params, err := FlattenParams(raw)
+	if err != nil {
+		return nil, fmt.Errorf("params file %s: %w", path, err)
+	}
+	return params, nil
+}
+
+// FlattenParams turns a nested config into a flat map, joining nested keys
+// with dots: {"optimizer": {"lr": 0.01}} becomes {"optimizer.lr": 0.01}.
+// Lists and scalars are kept as they are. The top level must be a mapping.
+func FlattenParams(raw any) (map[string]any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	top, ok := asMap(raw)
+	if !ok {
+		return nil, fmt.Errorf("params must be a mapping of names to values, got %T", raw)
+	}
+	params := make(map[string]any)
+	flattenInto(params, "", top)
+	if len(params) == 0 {
+		return nil, nil
+	}
+	return params, nil
+}
+
+func flattenInto(out map[string]any, prefix string, m map[string]any) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := asMap(v); ok && len(nested) > 0 {
+			flattenInto(out, key, nested)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// asMap accepts both JSON objects and YAML mappings, whose keys may decode
+// as non-strings (e.g. "1: foo").
+func asMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			out[fmt.Sprint(k)] = val
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// FormatParam renders a param value for display and comparison. Numbers
+// print in their shortest form, so 3 from YAML and 3.0 from JSON match.
+func FormatParam(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case []any:
+		parts := make([]string, len(x))
+		for i, item := range x {
+			parts[i] = FormatParam(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+// DiffParams describes how params changed from parent to child: a param
+// change for each differing value, "added" for new names and "removed" for
+// dropped ones, sorted by name.
+func DiffParams(parent, child map[string]any) []model.Change {
+	names := make(map[string]bool, len(parent)+len(child))
+	for k := range parent {
+		names[k] = true
+	}
+	for k := range child {
+		names[k] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []model.Change
+	for _, k := range sorted {
+		pv, inParent := parent[k]
+		cv, inChild := child[k]
+		switch {
+		case !inParent:
+			changes = append(changes, model.Change{Type: "added", What: k, To: FormatParam(cv)})
+		case !inChild:
+			changes = append(changes, model.Change{Type: "removed", What: k, From: FormatParam(pv)})
+		case FormatParam(pv) != FormatParam(cv):
+			changes = append(changes, model.Change{Type: "param", Param: k, From: FormatParam(pv), To: FormatParam(cv)})
+		}
+	}
+	return changes
+}
+
+// AddParamChanges records the param diff from parentID to the child in
+// changes, which it allocates if needed. Changes already recorded for the
+// same name, such as ones given with --change, win over computed ones.
+// Nothing is computed unless both sides have params.
+func AddParamChanges(changes map[string][]model.Change, parentID string, parent, child map[string]any) map[string][]model.Change {
+	if len(parent) == 0 || len(child) == 0 {
+		return changes
+	}
+	explicit := make(map[string]bool)
+	for _, c := range changes[parentID] {
+		explicit[c.Param+c.What] = true
+	}
+	var computed []model.Change
+	for _, c := range DiffParams(parent, child) {
+		if !explicit[c.Param+c.What] {
+			computed = append(computed, c)
+		}
+	}
+	if len(computed) == 0 {
+		return changes
+	}
+	if changes == nil {
+		changes = make(map[string][]model.Change)
+	}
+	changes[parentID] = append(computed, changes[parentID]...)
+	return changes
+}
+
+// ParseScalar converts a param stored as text, as MLflow does, into an int,
+// float or bool when it reads as one, and keeps it as a string otherwise.
+func ParseScalar(s string) any {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return int(i)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	switch s {
+	case "true", "True":
+		return true
+	case "false", "False":
+		return false
+	}
+	return s
+}
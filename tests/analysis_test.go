@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/analysis"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func paramExperiments() []model.Experiment {
+	exp := func(id string, v float64, params map[string]any, parent string, changes ...model.Change) model.Experiment {
+		e := model.Experiment{ID: id, Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: v}, Params: params}
+		if parent != "" {
+			e.Parents = []string{parent}
+			e.ChangesFrom = map[string][]model.Change{parent: changes}
+		}
+		return e
+	}
+	return []model.Experiment{
+		exp("exp_001", 0.70, map[string]any{"lr": 0.1, "depth": 6}, ""),
+		exp("exp_002", 0.80, map[string]any{"lr": 0.01, "depth": 6}, "exp_001",
+			model.Change{Type: "param", Param: "lr", From: "0.1", To: "0.01"}),
+		exp("exp_003", 0.81, map[string]any{"lr": 0.01, "depth": 8}, "exp_002",
+			model.Change{Type: "param", Param: "depth", From: "6", To: "8"}),
+		exp("exp_004", 0.79, map[string]any{"lr": 0.01, "depth": 10}, "exp_003",
+			model.Change{Type: "param", Param: "depth", From: "8", To: "10"}),
+		{ID: "exp_005", Status: "failed", Metric: model.MetricResult{Name: "accuracy", Value: 0}, Params: map[string]any{"lr": 1.0, "depth": 6}},
+	}
+}
+
+func TestAnalyzeParams(t *testing.T) {
+	proj := model.Project{Metric: model.MetricDef{Name: "accuracy", Direction: "higher_is_better"}}
+
+	report, err := analysis.Params(paramExperiments(), proj, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Experiments != 4 {
+		t.Errorf("failed experiments should be ignored, got %d", report.Experiments)
+	}
+	if len(report.Params) != 2 || report.Params[0].Name != "lr" {
+		t.Fatalf("expected lr ranked first, got %+v", report.Params)
+	}
+
+	lr, depth := report.Params[0], report.Params[1]
+	if lr.Importance != 0.909 || lr.Changes != 1 || lr.MeanDelta == nil || *lr.MeanDelta < 0.0999 {
+		t.Errorf("unexpected lr stats: %+v", lr)
+	}
+	if len(lr.Values) != 2 || lr.Values[0].Value != "0.01" || lr.Values[0].Count != 3 || lr.Values[0].Best != "exp_003" {
+		t.Errorf("unexpected lr values: %+v", lr.Values)
+	}
+	if depth.Changes != 2 || depth.Importance >= lr.Importance || len(depth.ChangeLog) != 2 {
+		t.Errorf("unexpected depth stats: %+v", depth)
+	}
+	if depth.Values[0].Value != "6" || depth.Values[2].Value != "10" {
+		t.Errorf("numeric values should sort numerically: %+v", depth.Values)
+	}
+
+	if _, err := analysis.Params(paramExperiments(), proj, "f1"); err == nil {
+		t.Error("expected an error for an undeclared metric")
+	}
+}
+
+func TestMCP_AnalyzeParams(t *testing.T) {
+	s := setupTestStore(t)
+	for _, e := range paramExperiments() {
+		if err := s.WriteExperiment(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := mcp.NewServer(s)
+
+	text := resultText(callTool(t, srv, "analyze_params", map[string]any{}))
+	if !strings.Contains(text, "lr") || !strings.Contains(text, "0.91") || strings.Contains(text, "best exp_") {
+		t.Errorf("summary should list scores without value details:\n%s", text)
+	}
+
+	text = resultText(callTool(t, srv, "analyze_params", map[string]any{"depth": "standard", "format": "yaml"}))
+	if !strings.Contains(text, "values:") || strings.Contains(text, "change_log:") {
+		t.Errorf("standard YAML should include values but not the change log:\n%s", text)
+	}
+
+	text = resultText(callTool(t, srv, "analyze_params", map[string]any{"depth": "full"}))
+	if !strings.Contains(text, "exp_003 ← exp_002: 6 → 8") {
+		t.Errorf("full text should list each change:\n%s", text)
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/artifact"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+func TestAttachArtifacts(t *testing.T) {
+	s := setupTestStore(t)
+	project := filepath.Dir(s.Root())
+	exp := model.Experiment{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.85}}
+
+	ckpt := filepath.Join(project, "checkpoints", "best.pt")
+	writeFile(t, ckpt, "weights v1")
+	a, err := artifact.Attach(s, &exp, ckpt, artifact.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path != "checkpoints/best.pt" || a.Kind != "checkpoint" || a.Size != 10 || len(a.SHA256) != 64 || a.Stored {
+		t.Errorf("unexpected artifact: %+v", a)
+	}
+
+	sub := filepath.Join(project, "out", "submission.csv")
+	writeFile(t, sub, "id,label\n1,0\n")
+	a, err = artifact.Attach(s, &exp, sub, artifact.Options{Copy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != "submission" || !a.Stored {
+		t.Errorf("unexpected artifact: %+v", a)
+	}
+	if data, err := os.ReadFile(s.ArtifactCopyPath(a.SHA256)); err != nil || string(data) != "id,label\n1,0\n" {
+		t.Errorf("expected a stored copy (%v): %q", err, data)
+	}
+
+	hfDir := filepath.Join(t.TempDir(), "hf_model")
+	writeFile(t, filepath.Join(hfDir, "config.json"), "{}")
+	writeFile(t, filepath.Join(hfDir, "model.safetensors"), "tensors")
+	a, err = artifact.Attach(s, &exp, hfDir, artifact.Options{Kind: "checkpoint"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path != filepath.ToSlash(hfDir) || a.Size != 9 {
+		t.Errorf("paths outside the project should be absolute: %+v", a)
+	}
+	if _, err := artifact.Attach(s, &exp, hfDir, artifact.Options{Copy: true}); err == nil {
+		t.Error("expected an error copying a directory")
+	}
+
+	big := filepath.Join(project, "big.bin")
+	f, _ := os.Create(big)
+	f.Truncate(artifact.MaxCopySize + 1)
+	f.Close()
+	if _, err := artifact.Attach(s, &exp, big, artifact.Options{Copy: true}); err == nil || !strings.Contains(err.Error(), "over the 10.0 MiB copy limit") {
+		t.Errorf("expected a copy limit error, got %v", err)
+	}
+
+	// Attaching a path again replaces its record.
+	writeFile(t, ckpt, "weights v2")
+	if _, err := artifact.Attach(s, &exp, ckpt, artifact.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Artifacts) != 3 || exp.Artifacts[2].Path != "checkpoints/best.pt" {
+		t.Fatalf("unexpected artifacts: %+v", exp.Artifacts)
+	}
+	if err := s.WriteExperiment(exp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hashing and copying don't wait for the store lock, so a long attach
+	// can't time out other writers.
+	writeFile(t, filepath.Join(project, "preds.csv"), "id,p\n")
+	err = s.WithLock(func(*store.Store) error {
+		other := store.New(project)
+		other.SetLockTimeout(50 * time.Millisecond)
+		_, err := artifact.Prepare(other, filepath.Join(project, "preds.csv"), artifact.Options{Copy: true})
+		return err
+	})
+	if err != nil {
+		t.Errorf("Prepare should not take the lock: %v", err)
+	}
+
+	if problems, checked, err := artifact.Verify(s, []model.Experiment{exp}); err != nil || checked != 3 || len(problems) != 0 {
+		t.Fatalf("expected 3 clean artifacts, got %d checked, %+v (%v)", checked, problems, err)
+	}
+
+	writeFile(t, ckpt, "weights v3, retrained")
+	os.Remove(sub)
+	os.Remove(s.ArtifactCopyPath(exp.Artifacts[0].SHA256))
+	os.Remove(filepath.Join(hfDir, "config.json"))
+	problems, _, err := artifact.Verify(s, []model.Experiment{exp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := make([]string, len(problems))
+	for i, p := range problems {
+		issues[i] = p.Issue + " " + p.Artifact.Path
+	}
+	want := []string{"missing out/submission.csv", "copy missing out/submission.csv", "modified " + filepath.ToSlash(hfDir), "modified checkpoints/best.pt"}
+	if strings.Join(issues, "; ") != strings.Join(want, "; ") {
+		t.Errorf("unexpected problems:\n got %v\nwant %v", issues, want)
+	}
+
+	// Snapshots leave the artifact store alone.
+	name, err := s.CreateSnapshot("before")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Root(), "snapshots", name, "artifacts")); !os.IsNotExist(err) {
+		t.Errorf("snapshots should not copy artifacts/: %v", err)
+	}
+
+	srv := mcp.NewServer(s)
+	text := resultText(callTool(t, srv, "get_experiment", map[string]any{"id": "exp_001", "depth": "full"}))
+	if !strings.Contains(text, "path: out/submission.csv") || !strings.Contains(text, "stored: true") {
+		t.Errorf("full depth should list artifacts:\n%s", text)
+	}
+}
+
+func TestCLI_ExpAttachVerify(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	if out, err := run("exp", "new", "--metric", "0.85"); err != nil {
+		t.Fatalf("exp new: %v\n%s", err, out)
+	}
+	writeFile(t, filepath.Join(dir, "preds", "val.csv"), "id,p\n1,0.9\n")
+
+	out, err := run("exp", "attach", "exp_001", "preds/val.csv", "--copy")
+	if err != nil || !strings.Contains(out, "Attached preds/val.csv to exp_001 (predictions, 11 B, sha256 ") || !strings.Contains(out, "A copy is stored") {
+		t.Fatalf("attach failed: %v\n%s", err, out)
+	}
+	if out, err := run("exp", "attach", "exp_001", "preds/val.csv", "--copy", "--link"); err == nil {
+		t.Errorf("--copy and --link should conflict:\n%s", out)
+	}
+	if out, err := run("exp", "verify"); err != nil || !strings.Contains(out, "All 1 artifacts match their recorded hashes") {
+		t.Errorf("verify failed: %v\n%s", err, out)
+	}
+
+	writeFile(t, filepath.Join(dir, "preds", "val.csv"), "id,p\n1,0.1\n")
+	out, err = run("exp", "verify", "exp_001")
+	if err == nil || !strings.Contains(out, "modified") || !strings.Contains(out, "1 of 1 artifacts failed verification") {
+		t.Errorf("expected a modified artifact:\n%s", out)
+	}
+	if strings.Contains(out, "Usage:") {
+		t.Errorf("a failed verify should not print usage:\n%s", out)
+	}
+}
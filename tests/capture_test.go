@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/capture"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// fakeBin puts shell scripts named after the given commands on an otherwise
+// empty PATH, so capture sees only them.
+func fakeBin(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake executables are shell scripts")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaptureEnvironment(t *testing.T) {
+	fakeBin(t, map[string]string{
+		"python": `case "$1" in
+--version) echo "Python 3.11.4" ;;
+-m) printf 'numpy==1.26.4\nscikit_learn==1.4.2\nrequests==2.31.0\n-e git+https://example.com/x.git#egg=x\n' ;;
+esac`,
+		// pip on PATH may belong to another interpreter; it must not be used.
+		"pip":        `echo "numpy==0.0.1"`,
+		"nvidia-smi": `printf 'NVIDIA A100-SXM4-80GB\nNVIDIA A100-SXM4-80GB\n'`,
+	})
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "data", "train.csv"), "a,b\n1,2\n")
+	writeFile(t, filepath.Join(dir, "prep.py"), "print('prep')\n")
+
+	env, warnings := capture.Environment(dir, model.EnvConfig{
+		Packages:      []string{"numpy", "scikit-learn", "torch"},
+		Data:          []string{"data"},
+		Preprocessing: []string{"prep.py"},
+	})
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	if env.Python != "3.11.4" {
+		t.Errorf("python = %q", env.Python)
+	}
+	if env.GPU != "2x NVIDIA A100-SXM4-80GB" {
+		t.Errorf("gpu = %q", env.GPU)
+	}
+	if len(env.KeyPackages) != 2 || env.KeyPackages["numpy"] != "1.26.4" || env.KeyPackages["scikit-learn"] != "1.4.2" {
+		t.Errorf("key packages = %v", env.KeyPackages)
+	}
+	if !strings.HasPrefix(env.DataHash, "sha256:") || !strings.HasPrefix(env.PreprocessingHash, "sha256:") {
+		t.Errorf("hashes = %q, %q", env.DataHash, env.PreprocessingHash)
+	}
+
+	before := env.DataHash
+	writeFile(t, filepath.Join(dir, "data", "train.csv"), "a,b\n1,3\n")
+	if after, err := capture.HashPaths(dir, []string{"data"}); err != nil || after == before {
+		t.Errorf("data hash should change with file contents: %q → %q (%v)", before, after, err)
+	}
+}
+
+func TestCaptureEnvironment_MissingTools(t *testing.T) {
+	fakeBin(t, nil)
+	dir := t.TempDir()
+
+	env, warnings := capture.Environment(dir, model.EnvConfig{Data: []string{"missing.csv"}})
+	if env.GPU != "" || env.Python != "" || env.DataHash != "" {
+		t.Errorf("nothing should be captured, got %+v", env)
+	}
+	// python, pip and the data path warn; a missing nvidia-smi just means no GPU.
+	if len(warnings) != 3 {
+		t.Errorf("expected 3 warnings, got %v", warnings)
+	}
+
+	// Without an interpreter, pip from PATH is the fallback.
+	fakeBin(t, map[string]string{"pip": `echo "numpy==1.26.4"`})
+	env, _ = capture.Environment(dir, model.EnvConfig{})
+	if env.KeyPackages["numpy"] != "1.26.4" {
+		t.Errorf("expected packages from pip, got %v", env.KeyPackages)
+	}
+}
+
+func TestLogExperiment_CaptureEnv(t *testing.T) {
+	fakeBin(t, map[string]string{
+		"python": `case "$1" in
+--version) echo "Python 3.12.1" ;;
+-m) echo "torch==2.3.0" ;;
+esac`,
+	})
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	result := callTool(t, srv, "log_experiment", map[string]any{
+		"metric_value": 0.8,
+		"status":       "neutral",
+		"capture_env":  true,
+		"environment":  "python=3.11,split_seed=7",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+
+	exp, err := s.ReadExperiment("exp_001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := exp.Environment
+	if env == nil || env.Python != "3.11" || env.KeyPackages["torch"] != "2.3.0" || env.SplitSeed == nil {
+		t.Errorf("explicit values should override captured ones: %+v", env)
+	}
+}
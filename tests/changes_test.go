@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+func TestParseChange(t *testing.T) {
+	cases := []struct {
+		spec, parent, typ, name, from, to string
+	}{
+		{"param:lr:0.1->0.01", "", "param", "lr", "0.1", "0.01"},
+		{"param:depth:8", "", "param", "depth", "", "8"},
+		{"added:target_encoding", "", "added", "target_encoding", "", ""},
+		{"exp_002:removed:dropout", "exp_002", "removed", "dropout", "", ""},
+		{"changed:optimizer:adam->sgd", "", "changed", "optimizer", "adam", "sgd"},
+	}
+	for _, tc := range cases {
+		parent, c, err := util.ParseChange(tc.spec)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.spec, err)
+			continue
+		}
+		name := c.What
+		if c.Type == "param" {
+			name = c.Param
+		}
+		if parent != tc.parent || c.Type != tc.typ || name != tc.name || c.From != tc.from || c.To != tc.to {
+			t.Errorf("%s: got parent=%q %+v", tc.spec, parent, c)
+		}
+	}
+
+	for _, spec := range []string{"", "tweak:lr", "param:lr", "param::0.1", "added:"} {
+		if _, _, err := util.ParseChange(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestParseChanges_Parents(t *testing.T) {
+	if _, err := util.ParseChanges([]string{"added:x"}, nil); err == nil {
+		t.Error("expected an error for a change without parents")
+	}
+	if _, err := util.ParseChanges([]string{"added:x"}, []string{"exp_001", "exp_002"}); err == nil {
+		t.Error("expected an error for an unprefixed change with several parents")
+	}
+	if _, err := util.ParseChanges([]string{"exp_009:added:x"}, []string{"exp_001"}); err == nil {
+		t.Error("expected an error for a prefix that is not a parent")
+	}
+
+	got, err := util.ParseChanges([]string{"exp_002:added:x", "exp_001:param:lr:0.1"}, []string{"exp_001", "exp_002"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got["exp_001"]) != 1 || len(got["exp_002"]) != 1 {
+		t.Errorf("changes not grouped by parent: %+v", got)
+	}
+}
+
+func TestParseReasoningAndEnvironment(t *testing.T) {
+	r, err := util.ParseReasoning("lower lr should stabilise", "", []string{"exp_001=loss spiked"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Type != "unknown" || r.Evidence["exp_001"] != "loss spiked" {
+		t.Errorf("unexpected reasoning: %+v", r)
+	}
+	if _, err := util.ParseReasoning("x", "hunch", nil); err == nil {
+		t.Error("expected an error for an invalid reasoning type")
+	}
+	if _, err := util.ParseReasoning("x", "", []string{"exp_001"}); err == nil {
+		t.Error("expected an error for evidence without an observation")
+	}
+
+	env, err := util.ParseEnvironment("python=3.11,gpu=A100,split_seed=42,torch=2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env.Python != "3.11" || env.GPU != "A100" || env.SplitSeed == nil || *env.SplitSeed != 42 || env.KeyPackages["torch"] != "2.3" {
+		t.Errorf("unexpected environment: %+v", env)
+	}
+	if _, err := util.ParseEnvironment("split_seed=abc"); err == nil {
+		t.Error("expected an error for a non-integer split_seed")
+	}
+}
+
+func TestLogExperiment_StructuredFields(t *testing.T) {
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	callTool(t, srv, "log_experiment", map[string]any{"metric_value": 0.80, "status": "neutral"})
+
+	result := callTool(t, srv, "log_experiment", map[string]any{
+		"metric_value":   0.82,
+		"status":         "improved",
+		"parents":        "exp_001",
+		"changes":        []any{"param:lr:0.1->0.01", "added:target_encoding"},
+		"reasoning":      "lower lr should stabilise training",
+		"reasoning_type": "assumption",
+		"evidence":       []any{"exp_001=loss spiked after epoch 3"},
+		"local_cv":       0.815,
+		"public_lb":      0.0,
+		"data_version":   2,
+		"environment":    "python=3.11,torch=2.3",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+
+	exp, err := s.ReadExperiment("exp_002")
+	if err != nil {
+		t.Fatalf("read experiment: %v", err)
+	}
+	if len(exp.ChangesFrom["exp_001"]) != 2 {
+		t.Errorf("expected 2 changes from exp_001, got %+v", exp.ChangesFrom)
+	}
+	if exp.Reasoning.Type != "assumption" || exp.Reasoning.Evidence["exp_001"] == "" {
+		t.Errorf("unexpected reasoning: %+v", exp.Reasoning)
+	}
+	if exp.LocalCV == nil || *exp.LocalCV != 0.815 {
+		t.Errorf("unexpected local_cv: %v", exp.LocalCV)
+	}
+	if exp.PublicLB == nil || *exp.PublicLB != 0 {
+		t.Errorf("an explicit public_lb of 0 should be recorded, got %v", exp.PublicLB)
+	}
+	if exp.DataVersion != 2 || exp.Environment == nil || exp.Environment.KeyPackages["torch"] != "2.3" {
+		t.Errorf("unexpected data version or environment: %d %+v", exp.DataVersion, exp.Environment)
+	}
+
+	line := format.ExperimentOneLiner(exp)
+	for _, want := range []string{"lr=0.1→0.01", "+target_encoding", "cv 0.8150", "lb 0.0000", "data v2", "improved (assumption)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("one-liner %q missing %q", line, want)
+		}
+	}
+}
+
+func TestLogExperiment_RejectsUnknownEvidence(t *testing.T) {
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	result := callTool(t, srv, "log_experiment", map[string]any{
+		"metric_value": 0.8,
+		"status":       "neutral",
+		"reasoning":    "x",
+		"evidence":     []any{"exp_042=never ran"},
+	})
+	if !result.IsError || !strings.Contains(resultText(result), "exp_042") {
+		t.Errorf("expected an error naming the missing evidence experiment, got %q", resultText(result))
+	}
+	if exps, _ := s.ListExperiments(); len(exps) != 0 {
+		t.Errorf("nothing should be written on error, got %d experiments", len(exps))
+	}
+}
+
+func TestCLI_ExpNew_StructuredFlags(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	if out, err := run("exp", "new", "--metric", "0.8"); err != nil {
+		t.Fatalf("exp new: %v\n%s", err, out)
+	}
+	out, err := run("exp", "new", "--metric", "0.82", "--parents", "exp_001",
+		"--change", "param:lr:0.1->0.01", "--reasoning", "lower lr", "--reasoning-type", "proven",
+		"--evidence", "exp_001=diverged", "--local-cv", "0.81", "--data-version", "3")
+	if err != nil {
+		t.Fatalf("exp new: %v\n%s", err, out)
+	}
+
+	exp, err := store.New(dir).ReadExperiment("exp_002")
+	if err != nil {
+		t.Fatalf("read experiment: %v", err)
+	}
+	if exp.ChangesFrom["exp_001"][0].From != "0.1" || exp.Reasoning.Type != "proven" || exp.LocalCV == nil || exp.PublicLB != nil || exp.DataVersion != 3 {
+		t.Errorf("unexpected experiment: %+v", exp)
+	}
+
+	if out, err := run("exp", "new", "--metric", "0.8", "--change", "added:x"); err == nil {
+		t.Errorf("expected a change without parents to fail, got %s", out)
+	}
+	if out, err := run("exp", "new", "--metric", "0.8", "--reasoning-type", "hunch"); err == nil {
+		t.Errorf("expected an invalid reasoning type to fail, got %s", out)
+	}
+}
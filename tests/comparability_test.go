@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/index"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func splitSeed(n int) *int { return &n }
+
+func driftExperiments() []model.Experiment {
+	return []model.Experiment{
+		{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.80}, DataVersion: 1,
+			Environment: &model.Environment{DataHash: "sha256:aaaa", SplitSeed: splitSeed(42)}},
+		{ID: "exp_002", Status: "improved", Parents: []string{"exp_001"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.90}, DataVersion: 1,
+			Environment: &model.Environment{DataHash: "sha256:aaaa", SplitSeed: splitSeed(42)}},
+		{ID: "exp_003", Status: "improved", Parents: []string{"exp_002"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.85}, DataVersion: 2,
+			Environment: &model.Environment{DataHash: "sha256:bbbb", SplitSeed: splitSeed(42)}},
+		{ID: "exp_004", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.70}},
+	}
+}
+
+func TestDataGroupsAndDifferences(t *testing.T) {
+	exps := driftExperiments()
+
+	groups := index.DataGroups(exps)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", groups)
+	}
+	if strings.Join(groups[0].Experiments, ",") != "exp_001,exp_002" {
+		t.Errorf("unexpected first group: %+v", groups[0])
+	}
+	if groups[2].Label() != "data not recorded" {
+		t.Errorf("unexpected label for unrecorded group: %q", groups[2].Label())
+	}
+
+	if diffs := index.DataDifferences(exps[0], exps[1]); len(diffs) != 0 {
+		t.Errorf("same data should have no differences, got %v", diffs)
+	}
+	if diffs := index.DataDifferences(exps[1], exps[2]); len(diffs) != 2 {
+		t.Errorf("expected version and hash to differ, got %v", diffs)
+	}
+	if diffs := index.DataDifferences(exps[2], exps[3]); len(diffs) != 0 {
+		t.Errorf("unrecorded fields should not count as differences, got %v", diffs)
+	}
+}
+
+func TestDataGroups_PartiallyRecorded(t *testing.T) {
+	exps := []model.Experiment{
+		{ID: "exp_001", DataVersion: 2},
+		{ID: "exp_002", DataVersion: 2, Environment: &model.Environment{DataHash: "sha256:bbbb"}},
+		{ID: "exp_003", Environment: &model.Environment{DataHash: "sha256:bbbb", SplitSeed: splitSeed(7)}},
+		{ID: "exp_004", DataVersion: 2, Environment: &model.Environment{DataHash: "sha256:cccc"}},
+	}
+	if diffs := index.DataDifferences(exps[0], exps[1]); len(diffs) != 0 {
+		t.Fatalf("expected exp_001 and exp_002 to be comparable, got %v", diffs)
+	}
+
+	groups := index.DataGroups(exps)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	if strings.Join(groups[0].Experiments, ",") != "exp_001,exp_002,exp_003" {
+		t.Errorf("comparable experiments should share a group: %+v", groups[0])
+	}
+	if got := groups[0].Label(); got != "data v2, hash sha256:bbbb, split seed 7" {
+		t.Errorf("the group should carry every recorded field, got %q", got)
+	}
+	if strings.Join(groups[1].Experiments, ",") != "exp_004" {
+		t.Errorf("a different hash should start a new group: %+v", groups[1])
+	}
+
+	// Agrees with check_comparability's pairwise view in the digest.
+	proj := model.Project{Metric: model.MetricDef{Name: "accuracy", Direction: "higher_is_better"}}
+	ci := index.Compute(exps[:3], model.LearningsFile{}, model.GraveyardFile{}, proj)
+	if len(ci.DataGroups) != 0 {
+		t.Errorf("comparable experiments should not be reported as drift: %+v", ci.DataGroups)
+	}
+}
+
+func TestCompute_CurrentDataOnly(t *testing.T) {
+	exps := driftExperiments()
+	proj := model.Project{
+		Metric:      model.MetricDef{Name: "accuracy", Direction: "higher_is_better"},
+		DataVersion: 2,
+	}
+
+	ci := index.Compute(exps, model.LearningsFile{}, model.GraveyardFile{}, proj)
+	if ci.BestExperiment != "exp_002" {
+		t.Errorf("without restriction expected exp_002, got %s", ci.BestExperiment)
+	}
+	if len(ci.DataGroups) != 3 {
+		t.Errorf("expected data groups in the index, got %+v", ci.DataGroups)
+	}
+
+	proj.Index = &model.IndexConfig{CurrentDataOnly: true}
+	ci = index.Compute(exps, model.LearningsFile{}, model.GraveyardFile{}, proj)
+	if ci.BestExperiment != "exp_003" {
+		t.Errorf("restricted to data v2 expected exp_003, got %s", ci.BestExperiment)
+	}
+}
+
+func TestMCP_DataDriftWarnings(t *testing.T) {
+	s := setupTestStore(t)
+	for _, e := range driftExperiments() {
+		if err := s.WriteExperiment(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := index.Rebuild(s); err != nil {
+		t.Fatal(err)
+	}
+	srv := mcp.NewServer(s)
+
+	text := resultText(callTool(t, srv, "compare_experiments", map[string]any{"id1": "exp_002", "id2": "exp_003"}))
+	if !strings.Contains(text, "different data") || !strings.Contains(text, "data version 1 vs 2") {
+		t.Errorf("compare should warn about different data, got:\n%s", text)
+	}
+	text = resultText(callTool(t, srv, "compare_experiments", map[string]any{"id1": "exp_001", "id2": "exp_002"}))
+	if strings.Contains(text, "different data") {
+		t.Errorf("compare should not warn for the same data, got:\n%s", text)
+	}
+
+	text = resultText(callTool(t, srv, "check_comparability", map[string]any{}))
+	for _, want := range []string{"3 different data setups", "data v1, hash sha256:aaaa, split seed 42 (2): exp_001, exp_002", "data not recorded (1): exp_004"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("check_comparability output missing %q:\n%s", want, text)
+		}
+	}
+	text = resultText(callTool(t, srv, "check_comparability", map[string]any{"ids": "exp_001,exp_002"}))
+	if !strings.Contains(text, "comparable") || strings.Contains(text, "different data setups") {
+		t.Errorf("expected the pair to be comparable, got:\n%s", text)
+	}
+}
+
+func TestMCP_ChainWarnsOnDataChange(t *testing.T) {
+	s := setupTestStore(t)
+	exps := driftExperiments()
+	exps[2].Metric.Value = 0.95 // exp_003 becomes best, so the chain crosses data versions
+	for _, e := range exps {
+		if err := s.WriteExperiment(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := index.Rebuild(s); err != nil {
+		t.Fatal(err)
+	}
+
+	text := resultText(callTool(t, mcp.NewServer(s), "get_experiment_chain", map[string]any{}))
+	if !strings.Contains(text, "exp_002 → exp_003 ran on different data") {
+		t.Errorf("chain should flag the data change, got:\n%s", text)
+	}
+}
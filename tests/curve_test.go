@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/curve"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func points(values ...float64) []model.CurvePoint {
+	out := make([]model.CurvePoint, len(values))
+	for i, v := range values {
+		out[i] = model.CurvePoint{Step: int64(i * 10), Value: v}
+	}
+	return out
+}
+
+func TestCurveStats(t *testing.T) {
+	rising := curve.Stats(points(0.5, 0.6, 0.7, 0.8, 0.9), true)
+	if rising.BestStep != 40 || rising.Gap != 0 || rising.Plateau {
+		t.Errorf("a curve still improving should not plateau: %+v", rising)
+	}
+
+	// Reaches its best by step 30, holds, then drops off: overfit.
+	overfit := curve.Stats(points(0.5, 0.7, 0.8, 0.9, 0.899, 0.898, 0.897, 0.85), true)
+	if overfit.Best != 0.9 || overfit.BestStep != 30 || overfit.Final != 0.85 || overfit.FinalStep != 70 {
+		t.Errorf("unexpected best/final: %+v", overfit)
+	}
+	if overfit.Gap < 0.0499 || overfit.Gap > 0.0501 || !overfit.Declining || overfit.Plateau {
+		t.Errorf("expected a 0.05 gap and a decline, not a plateau: %+v", overfit)
+	}
+
+	// Falling steadily from the first step is a decline too.
+	falling := curve.Stats(points(0.89, 0.87, 0.85, 0.84, 0.82), true)
+	if !falling.Declining || falling.Plateau {
+		t.Errorf("a steadily falling curve should be declining: %+v", falling)
+	}
+
+	flat := curve.Stats(points(0.5, 0.7, 0.9, 0.8995, 0.9, 0.8998, 0.9, 0.8999), true)
+	if !flat.Plateau || flat.PlateauSince != 20 || flat.Declining {
+		t.Errorf("expected a plateau since step 20: %+v", flat)
+	}
+	if dip := curve.Stats(points(0.5, 0.7, 0.9, 0.85, 0.9, 0.9, 0.9, 0.9), true); !dip.Plateau || dip.PlateauSince != 40 {
+		t.Errorf("a plateau starts after the last dip: %+v", dip)
+	}
+
+	loss := curve.Stats(points(2.0, 1.0, 0.5, 0.6), false)
+	if loss.Best != 0.5 || loss.Direction != "lower_is_better" || loss.Plateau {
+		t.Errorf("unexpected loss stats: %+v", loss)
+	}
+
+	merged := curve.Merge(points(1, 2, 3), model.CurvePoint{Step: 10, Value: 9}, model.CurvePoint{Step: 5, Value: 4})
+	if len(merged) != 4 || merged[1].Step != 5 || merged[2].Value != 9 {
+		t.Errorf("merge should sort and replace repeated steps: %v", merged)
+	}
+	if _, err := curve.ParsePoint("10;0.5"); err == nil {
+		t.Error("expected an error for a malformed point")
+	}
+}
+
+func TestCurveDownsample(t *testing.T) {
+	var long []model.CurvePoint
+	for i := 0; i < 1000; i++ {
+		long = append(long, model.CurvePoint{Step: int64(i), Value: float64(i % 97)})
+	}
+	got := curve.Downsample(long, 10, 96)
+	if len(got) != 10 || got[0].Step != 0 || got[9].Step != 999 {
+		t.Fatalf("expected 10 points spanning the curve, got %v", got)
+	}
+	found := false
+	for i, p := range got {
+		found = found || p.Step == 96
+		if i > 0 && p.Step <= got[i-1].Step {
+			t.Errorf("points out of order: %v", got)
+		}
+	}
+	if !found {
+		t.Errorf("the best step should be kept: %v", got)
+	}
+	if short := curve.Downsample(long[:5], 10, 0); len(short) != 5 {
+		t.Errorf("short curves are returned whole, got %d points", len(short))
+	}
+}
+
+func TestCLI_ExpCurve(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	if out, err := run("exp", "new", "--metric", "0.85"); err != nil {
+		t.Fatalf("exp new: %v\n%s", err, out)
+	}
+
+	out, err := run("exp", "curve", "exp_001", "--append", "0,0.5", "--append", "10,0.9", "--append", "20,0.85")
+	if err != nil || !strings.Contains(out, "Added 3 points to the accuracy curve of exp_001 (3 points, best 0.9000 at step 10)") {
+		t.Fatalf("append failed: %v\n%s", err, out)
+	}
+	if out, err := run("exp", "curve", "exp_001", "--append", "30,0.84"); err != nil || !strings.Contains(out, "(4 points") {
+		t.Fatalf("second append failed: %v\n%s", err, out)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, ".marrow", "experiments", "exp_001", "curves", "accuracy.csv"))
+	if err != nil || string(raw) != "step,value\n0,0.5\n10,0.9\n20,0.85\n30,0.84\n" {
+		t.Errorf("unexpected curve file (%v):\n%s", err, raw)
+	}
+
+	out, err = run("exp", "curve", "exp_001")
+	if err != nil || !strings.Contains(out, "best   0.9000 at step 10") || !strings.Contains(out, "final  0.8400 at step 30 (0.0600 behind best)") || !strings.Contains(out, "\n20,0.85\n") {
+		t.Errorf("unexpected curve output: %v\n%s", err, out)
+	}
+
+	if out, err := run("exp", "curve", "exp_001", "--metric", "val_loss", "--direction", "lower_is_better", "--append", "0,1.2", "--append", "10,0.7"); err != nil || !strings.Contains(out, "best 0.7000 at step 10") {
+		t.Fatalf("undeclared metric append failed: %v\n%s", err, out)
+	}
+	if out, err := run("exp", "curve", "exp_001", "--direction", "lower_is_better", "--append", "40,0.8"); err == nil || !strings.Contains(out, "declared higher_is_better") {
+		t.Errorf("expected a direction conflict error, got:\n%s", out)
+	}
+	if out, err := run("exp", "curve", "exp_001", "--metric", "f1"); err == nil || !strings.Contains(out, "it has accuracy, val_loss") {
+		t.Errorf("expected the available curves to be listed, got:\n%s", out)
+	}
+
+	out, _ = run("exp", "show", "exp_001")
+	if !strings.Contains(out, "curves:") || !strings.Contains(out, "path: .marrow/experiments/exp_001/curves/val_loss.csv") {
+		t.Errorf("exp show should list curve stats and artifacts:\n%s", out)
+	}
+}
+
+func TestGetExperimentCurve(t *testing.T) {
+	s := setupTestStore(t)
+	exp := model.Experiment{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.85}}
+	var series []model.CurvePoint
+	for i := 0; i < 200; i++ {
+		v := 0.9 - float64((i-120)*(i-120))/200000
+		series = append(series, model.CurvePoint{Step: int64(i), Value: v})
+	}
+	if err := curve.Record(s, &exp, "accuracy", series, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteExperiment(exp); err != nil {
+		t.Fatal(err)
+	}
+	srv := mcp.NewServer(s)
+
+	text := resultText(callTool(t, srv, "get_experiment_curve", map[string]any{"id": "exp_001", "points": 20}))
+	if !strings.Contains(text, "exp_001 accuracy: 200 points, higher is better") || !strings.Contains(text, "best   0.9000 at step 120") || !strings.Contains(text, "step,value (20 of 200 points)") || !strings.Contains(text, "\n120,0.9\n") {
+		t.Errorf("unexpected curve:\n%s", text)
+	}
+	if !strings.Contains(text, "declining since best at step 120") || strings.Contains(text, "plateaued") {
+		t.Errorf("expected a decline:\n%s", text)
+	}
+
+	text = resultText(callTool(t, srv, "get_experiment", map[string]any{"id": "exp_001", "depth": "standard"}))
+	if !strings.Contains(text, "best_step: 120") || strings.Contains(text, "artifacts:") {
+		t.Errorf("standard depth should carry curve stats but not artifacts:\n%s", text)
+	}
+
+	if r := callTool(t, srv, "get_experiment_curve", map[string]any{"id": "exp_001", "metric": "loss"}); !r.IsError || !strings.Contains(resultText(r), "has no loss curve") {
+		t.Errorf("expected an error for a missing curve: %s", resultText(r))
+	}
+}
+
+func TestCurveRecord_DistinctFiles(t *testing.T) {
+	s := setupTestStore(t)
+	exp := model.Experiment{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.85}}
+	names := []string{"train/loss", "train_loss", "train%2Floss", ".loss", "..", "über"}
+	for i, name := range names {
+		if err := curve.Record(s, &exp, name, points(float64(i)), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(exp.Artifacts) != len(names) || len(exp.Curves) != len(names) {
+		t.Fatalf("every name should get its own file: %+v", exp.Artifacts)
+	}
+	for i, name := range names {
+		got, err := s.ReadCurve("exp_001", name)
+		if err != nil || len(got) != 1 || got[0].Value != float64(i) {
+			t.Errorf("curve %q read back %v (%v)", name, got, err)
+		}
+	}
+	for _, a := range exp.Artifacts {
+		if dir := filepath.Dir(a.Path); dir != ".marrow/experiments/exp_001/curves" {
+			t.Errorf("curve written outside its directory: %s", a.Path)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/artifact"
+	"github.com/rzzdr/marrow/internal/capture"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+// git runs git in dir and returns its trimmed output.
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitRepo turns dir into a repository on branch main with train.py
+// committed, and returns the commit.
+func gitRepo(t *testing.T, dir string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	git(t, dir, "init", "-q", "-b", "main")
+	git(t, dir, "config", "user.name", "Test")
+	git(t, dir, "config", "user.email", "test@example.com")
+	writeFile(t, filepath.Join(dir, "train.py"), "lr = 0.1\n")
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "baseline")
+	return git(t, dir, "rev-parse", "HEAD")
+}
+
+func TestCaptureGit(t *testing.T) {
+	if env := capture.Git(t.TempDir()); env != nil {
+		t.Errorf("expected nothing outside a repository, got %+v", env)
+	}
+
+	dir := t.TempDir()
+	commit := gitRepo(t, dir)
+	env := capture.Git(dir)
+	if env == nil || env.GitCommit != commit || env.GitBranch != "main" || env.GitDirty {
+		t.Fatalf("unexpected git state: %+v", env)
+	}
+
+	// Logging writes to .marrow/, which must not make the tree dirty.
+	writeFile(t, filepath.Join(dir, ".marrow", "experiments", "exp_001.yaml"), "id: exp_001\n")
+	if env := capture.Git(dir); env.GitDirty {
+		t.Error("changes under .marrow/ should not count")
+	}
+
+	writeFile(t, filepath.Join(dir, "train.py"), "lr = 0.01\n")
+	env = capture.Git(dir)
+	if !env.GitDirty {
+		t.Error("a modified tracked file should make the tree dirty")
+	}
+	patch, err := capture.GitPatch(dir, env)
+	if err != nil || !strings.Contains(string(patch), "+lr = 0.01") || strings.Contains(string(patch), ".marrow") {
+		t.Errorf("unexpected patch (%v):\n%s", err, patch)
+	}
+
+	git(t, dir, "checkout", "-q", "--detach")
+	if env := capture.Git(dir); env.GitBranch != "" {
+		t.Errorf("a detached HEAD has no branch, got %q", env.GitBranch)
+	}
+	env.GitCommit = "0123456789"
+	if _, err := capture.GitPatch(dir, env); err == nil || !strings.Contains(err.Error(), "is not HEAD") {
+		t.Errorf("expected an error for a commit other than HEAD, got %v", err)
+	}
+
+	// Recorded commits are never passed to git as options.
+	target := filepath.Join(t.TempDir(), "pwned")
+	if _, err := capture.CommitRange(dir, "--output="+target, commit); err == nil {
+		t.Error("expected an error for a commit that is not a hash")
+	}
+	if err := capture.AddWorktree(dir, filepath.Join(t.TempDir(), "wt"), "--output="+target); err == nil {
+		t.Error("expected an error for a commit that is not a hash")
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("git wrote %s", target)
+	}
+	if _, err := util.ParseEnvironment("git_commit=--output=" + target); err == nil {
+		t.Error("expected --env to reject a git_commit that is not a hash")
+	}
+	if env, err := util.ParseEnvironment("git_commit=ABC1234"); err != nil || env.GitCommit != "abc1234" {
+		t.Errorf("hex commits should be accepted and lowercased: %+v, %v", env, err)
+	}
+}
+
+func TestCLI_GitIntegration(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	first := gitRepo(t, dir)
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return out
+	}
+
+	mustRun("exp", "new", "--metric", "0.80")
+	writeFile(t, filepath.Join(dir, "train.py"), "lr = 0.01\n")
+	mustRun("exp", "new", "--metric", "0.85", "--git-diff")
+	git(t, dir, "commit", "-q", "-am", "lower lr")
+	second := git(t, dir, "rev-parse", "HEAD")
+	mustRun("exp", "new", "--metric", "0.90", "--no-git")
+	mustRun("exp", "new", "--metric", "0.91")
+
+	s := store.New(dir)
+	exp1, _ := s.ReadExperiment("exp_001")
+	exp2, _ := s.ReadExperiment("exp_002")
+	if env := exp1.Environment; env == nil || env.GitCommit != first || env.GitBranch != "main" || env.GitDirty {
+		t.Errorf("unexpected git state for exp_001: %+v", env)
+	}
+	if env := exp2.Environment; env == nil || env.GitCommit != first || !env.GitDirty {
+		t.Errorf("exp_002 should be dirty at the first commit: %+v", env)
+	}
+	if len(exp2.Artifacts) != 1 || exp2.Artifacts[0].Kind != "diff" || exp2.Artifacts[0].Path != ".marrow/experiments/exp_002/git.diff" {
+		t.Errorf("expected a saved diff: %+v", exp2.Artifacts)
+	}
+	if exp3, _ := s.ReadExperiment("exp_003"); exp3.Environment != nil {
+		t.Errorf("--no-git should record nothing: %+v", exp3.Environment)
+	}
+	if out := mustRun("exp", "query", "git_branch=main"); !strings.Contains(out, "exp_004") || strings.Contains(out, "exp_003") {
+		t.Errorf("unexpected query result:\n%s", out)
+	}
+
+	if out := mustRun("exp", "checkout", "exp_001"); strings.TrimSpace(out) != first {
+		t.Errorf("expected the commit, got %q", out)
+	}
+	worktree := filepath.Join(t.TempDir(), "exp_002")
+	out := mustRun("exp", "checkout", "exp_002", "--worktree", worktree)
+	if !strings.Contains(out, "Applied uncommitted changes from .marrow/experiments/exp_002/git.diff") {
+		t.Errorf("unexpected checkout output:\n%s", out)
+	}
+	if data, err := os.ReadFile(filepath.Join(worktree, "train.py")); err != nil || string(data) != "lr = 0.01\n" {
+		t.Errorf("the worktree should have exp_002's code (%v): %q", err, data)
+	}
+	// A saved diff that was changed since logging is not applied, and no
+	// worktree is left behind.
+	diffFile := filepath.Join(dir, ".marrow", "experiments", "exp_002", "git.diff")
+	saved, _ := os.ReadFile(diffFile)
+	writeFile(t, diffFile, "not a patch\n")
+	tampered := filepath.Join(t.TempDir(), "tampered")
+	if out, err := run("exp", "checkout", "exp_002", "--worktree", tampered); err == nil || !strings.Contains(out, "was modified since exp_002 was logged") {
+		t.Errorf("expected an error for a modified diff:\n%s", out)
+	}
+	if _, err := os.Stat(tampered); !os.IsNotExist(err) {
+		t.Errorf("no worktree should be created for a modified diff: %v", err)
+	}
+	// A diff that matches its hash but does not apply removes the worktree.
+	savedSum := exp2.Artifacts[0].SHA256
+	_, exp2.Artifacts[0].SHA256, _ = artifact.Hash(diffFile)
+	if err := s.WriteExperiment(exp2); err != nil {
+		t.Fatal(err)
+	}
+	failed := filepath.Join(t.TempDir(), "failed")
+	if out, err := run("exp", "checkout", "exp_002", "--worktree", failed); err == nil || !strings.Contains(out, "the worktree was removed") {
+		t.Errorf("expected an error for a diff that does not apply:\n%s", out)
+	}
+	if _, err := os.Stat(failed); !os.IsNotExist(err) {
+		t.Errorf("the worktree should be removed when the diff fails to apply: %v", err)
+	}
+	writeFile(t, diffFile, string(saved))
+	exp2.Artifacts[0].SHA256 = savedSum
+	if err := s.WriteExperiment(exp2); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := run("exp", "checkout", "exp_003"); err == nil || !strings.Contains(out, "has no git commit recorded") {
+		t.Errorf("expected an error for an experiment without a commit:\n%s", out)
+	}
+
+	srv := mcp.NewServer(s)
+	text := resultText(callTool(t, srv, "compare_experiments", map[string]any{"id1": "exp_002", "id2": "exp_004"}))
+	want := "Code: " + first[:7] + ".." + second[:7] + ", 1 commit\n  " + second[:7] + " lower lr\n  exp_002 had uncommitted changes (saved in .marrow/experiments/exp_002/git.diff)"
+	if !strings.Contains(text, want) {
+		t.Errorf("expected the commit range in:\n%s", text)
+	}
+	text = resultText(callTool(t, srv, "compare_experiments", map[string]any{"id1": "exp_004", "id2": "exp_001"}))
+	if !strings.Contains(text, ", 0 commits\n  exp_001 ran on code 1 commit older than exp_004") {
+		t.Errorf("expected exp_001 to be behind:\n%s", text)
+	}
+
+	text = resultText(callTool(t, srv, "log_experiment", map[string]any{"status": "neutral", "metric_value": 0.9}))
+	if exp5, _ := s.ReadExperiment("exp_005"); !strings.Contains(text, "exp_005") || exp5.Environment == nil || exp5.Environment.GitCommit != second {
+		t.Errorf("log_experiment should record the commit: %s", text)
+	}
+}
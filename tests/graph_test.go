@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/graph"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func graphExperiments() []model.Experiment {
+	return []model.Experiment{
+		{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.80}},
+		{ID: "exp_002", Status: "improved", Parents: []string{"exp_001"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.85}, Tags: []string{"lr"}},
+		{ID: "exp_003", Status: "improved", Parents: []string{"exp_002"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.88}},
+		{ID: "exp_004", Status: "failed", Parents: []string{"exp_001", "exp_002"}, Metric: model.MetricResult{Name: "accuracy", Value: 0}, Tags: []string{"fe"}},
+		{ID: "exp_005", Status: "degraded", Parents: []string{"exp_004"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.79}, Tags: []string{"fe"}},
+	}
+}
+
+func nodeIDs(g graph.Graph) string {
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestGraphBuildAndFilters(t *testing.T) {
+	proj := model.Project{Metric: model.MetricDef{Name: "accuracy", Direction: "higher_is_better"}}
+	chain := []string{"exp_001", "exp_002", "exp_003"}
+
+	g, err := graph.Build(graphExperiments(), chain, proj, graph.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 5 || len(g.Edges) != 5 || g.Best != "exp_003" {
+		t.Fatalf("unexpected graph: %+v", g)
+	}
+	chainEdges := 0
+	for _, e := range g.Edges {
+		if e.OnChain {
+			chainEdges++
+		}
+	}
+	if chainEdges != 2 {
+		t.Errorf("expected 2 chain edges, got %d", chainEdges)
+	}
+
+	cases := []struct {
+		filter graph.Filter
+		want   string
+	}{
+		{graph.Filter{Root: "exp_002"}, "exp_002,exp_003,exp_004,exp_005"},
+		{graph.Filter{Ancestors: "exp_005"}, "exp_001,exp_002,exp_004,exp_005"},
+		{graph.Filter{Tags: []string{"fe"}}, "exp_004,exp_005"},
+		{graph.Filter{Root: "exp_002", Tags: []string{"fe", "lr"}}, "exp_002,exp_004,exp_005"},
+	}
+	for _, c := range cases {
+		g, err := graph.Build(graphExperiments(), chain, proj, c.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := nodeIDs(g); got != c.want {
+			t.Errorf("filter %+v: got %s, want %s", c.filter, got, c.want)
+		}
+		for _, e := range g.Edges {
+			if !strings.Contains(c.want, e.From) || !strings.Contains(c.want, e.To) {
+				t.Errorf("filter %+v: edge %s -> %s leaves the subgraph", c.filter, e.From, e.To)
+			}
+		}
+	}
+
+	if _, err := graph.Build(graphExperiments(), chain, proj, graph.Filter{Root: "exp_999"}); err == nil {
+		t.Error("expected an error for an unknown root")
+	}
+}
+
+func TestRenderGraph(t *testing.T) {
+	proj := model.Project{Metric: model.MetricDef{Name: "accuracy", Direction: "higher_is_better"}}
+	g, err := graph.Build(graphExperiments(), []string{"exp_001", "exp_002", "exp_003"}, proj, graph.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dot, err := format.RenderGraph(g, "dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph marrow {",
+		`"exp_003" [label="exp_003\naccuracy 0.8800\nimproved", style="rounded,filled,bold"`,
+		`"exp_001" -> "exp_002" [color="#2e7d32", penwidth=2];`,
+		`"exp_001" -> "exp_004";`,
+		`style="rounded,dashed"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT missing %q:\n%s", want, dot)
+		}
+	}
+	if _, err := exec.LookPath("dot"); err == nil {
+		cmd := exec.Command("dot", "-Tsvg")
+		cmd.Stdin = strings.NewReader(dot)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("dot rejected the output: %v\n%s", err, out)
+		}
+	}
+
+	mm, err := format.RenderGraph(g, "mermaid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"flowchart LR",
+		`exp_002["exp_002<br/>accuracy 0.8500<br/>improved"]`,
+		"exp_002 ==> exp_003",
+		"exp_002 --> exp_004",
+		"class exp_001,exp_002 chain",
+		"class exp_003 best",
+		"class exp_004 failed",
+	} {
+		if !strings.Contains(mm, want) {
+			t.Errorf("Mermaid missing %q:\n%s", want, mm)
+		}
+	}
+
+	js, err := format.RenderGraph(g, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back graph.Graph
+	if err := json.Unmarshal([]byte(js), &back); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(back.Nodes) != 5 || !back.Nodes[0].OnChain || back.Metric != "accuracy" {
+		t.Errorf("unexpected JSON graph: %+v", back)
+	}
+
+	if _, err := format.RenderGraph(g, "png"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestMCP_GetExperimentGraph(t *testing.T) {
+	s := tuiStore(t)
+	srv := mcp.NewServer(s)
+
+	text := resultText(callTool(t, srv, "get_experiment_graph", map[string]any{}))
+	if !strings.Contains(text, "flowchart LR") || !strings.Contains(text, "exp_002 ==> exp_003") {
+		t.Errorf("expected a Mermaid graph with the chain highlighted:\n%s", text)
+	}
+
+	text = resultText(callTool(t, srv, "get_experiment_graph", map[string]any{"ancestors": "exp_004", "format": "json"}))
+	if !strings.Contains(text, `"id": "exp_004"`) || strings.Contains(text, `"id": "exp_003"`) {
+		t.Errorf("ancestors filter should drop exp_003:\n%s", text)
+	}
+
+	r := callTool(t, srv, "get_experiment_graph", map[string]any{"root": "exp_999"})
+	if !r.IsError {
+		t.Error("expected an error for an unknown root")
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/mcp"
+)
+
+type httpClient struct {
+	t       *testing.T
+	url     string
+	token   string
+	session string
+}
+
+func (c *httpClient) post(msg map[string]any) (*http.Response, string) {
+	c.t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+mcp.HTTPPath, bytes.NewReader(data))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.session != "" {
+		req.Header.Set(server.HeaderKeySessionID, c.session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func (c *httpClient) initialize() {
+	c.t.Helper()
+	resp, body := c.post(map[string]any{"jsonrpc": "2.0", "id": 0, "method": "initialize", "params": map[string]any{
+		"protocolVersion": "2025-03-26",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "test", "version": "0"},
+	}})
+	if resp.StatusCode != http.StatusOK {
+		c.t.Fatalf("initialize: %d %s", resp.StatusCode, body)
+	}
+	c.session = resp.Header.Get(server.HeaderKeySessionID)
+	if c.session == "" {
+		c.t.Fatal("initialize did not return a session ID")
+	}
+	c.post(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+}
+
+func startHTTP(t *testing.T, opts mcp.ServeOptions) string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	opts.WatchInterval = -1
+	handler, err := mcp.NewHTTPHandler(ctx, setupTestStore(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestHTTP_BearerToken(t *testing.T) {
+	url := startHTTP(t, mcp.ServeOptions{Token: "s3cret"})
+
+	c := &httpClient{t: t, url: url}
+	resp, _ := c.post(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "ping"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	c.token = "wrong"
+	resp, _ = c.post(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "ping"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", resp.StatusCode)
+	}
+
+	c.token = "s3cret"
+	c.initialize()
+	_, body := c.post(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/call",
+		"params": map[string]any{"name": "get_project_summary", "arguments": map[string]any{}}})
+	if !strings.Contains(body, "test-project") {
+		t.Errorf("expected project summary, got %s", body)
+	}
+}
+
+func TestHTTP_ReadOnlyHidesWriteTools(t *testing.T) {
+	c := &httpClient{t: t, url: startHTTP(t, mcp.ServeOptions{ReadOnly: true})}
+	c.initialize()
+
+	_, body := c.post(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/list"})
+	if !strings.Contains(body, "get_experiment") {
+		t.Errorf("expected read tools, got %s", body)
+	}
+	for _, name := range []string{"log_experiment", "update_pinned", "restore_snapshot"} {
+		if strings.Contains(body, `"`+name+`"`) {
+			t.Errorf("read-only server lists %s", name)
+		}
+	}
+}
+
+func TestHTTP_Subscribe(t *testing.T) {
+	c := &httpClient{t: t, url: startHTTP(t, mcp.ServeOptions{})}
+	c.initialize()
+
+	resp, body := c.post(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "resources/subscribe",
+		"params": map[string]any{"uri": "marrow://index"}})
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"result"`) {
+		t.Errorf("subscribe: %d %s", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/importer"
+)
+
+func TestImportMLflow(t *testing.T) {
+	runs, warnings, err := importer.ReadMLflow(filepath.Join("testdata", "mlruns"), "churn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 || len(runs) != 4 {
+		t.Fatalf("expected 4 live runs in churn, got %d (warnings %v)", len(runs), warnings)
+	}
+
+	s := setupTestStore(t)
+	res, err := importer.Import(s, "mlflow", runs, importer.Options{Tags: []string{"legacy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 3 || len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], `eee555 (no-metric) has no "accuracy" metric`) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	// The failed run started before its parent but must be written after it.
+	want := map[string]string{"aaa111": "exp_001", "bbb222": "exp_002", "ccc333": "exp_003"}
+	for _, im := range res.Imported {
+		if want[im.RunID] != im.Experiment {
+			t.Errorf("run %s became %s, want %s", im.RunID, im.Experiment, want[im.RunID])
+		}
+	}
+
+	base, _ := s.ReadExperiment("exp_001")
+	if base.Metric.Value != 0.8 || base.Params["lr"] != 0.1 || base.Params["depth"] != 6 || base.Params["model"] != "xgboost" {
+		t.Errorf("unexpected baseline: %+v", base)
+	}
+	if strings.Join(base.Tags, ",") != "feature_eng,team=tabular,legacy" || base.Source == nil || base.Source.Name != "baseline" || base.Source.Group != "churn" {
+		t.Errorf("unexpected baseline tags or source: %v %+v", base.Tags, base.Source)
+	}
+	if base.Timestamp.UnixMilli() != 1700000100000 {
+		t.Errorf("timestamp should come from start_time, got %v", base.Timestamp)
+	}
+
+	child, _ := s.ReadExperiment("exp_002")
+	if child.Metric.Value != 0.85 || child.Status != "improved" || child.Notes != "lower lr" || len(child.Parents) != 1 || child.Parents[0] != "exp_001" {
+		t.Errorf("unexpected child: %+v", child)
+	}
+	if c := child.ChangesFrom["exp_001"]; len(c) != 1 || c[0].Param != "lr" || c[0].From != "0.1" || c[0].To != "0.01" {
+		t.Errorf("expected an lr change from the parent's params, got %+v", child.ChangesFrom)
+	}
+
+	failed, _ := s.ReadExperiment("exp_003")
+	if failed.Status != "failed" || failed.Parents[0] != "exp_002" {
+		t.Errorf("unexpected failed run: %+v", failed)
+	}
+	if failed.Metric.Baseline != 0 || failed.Metric.Delta != 0 {
+		t.Errorf("a failed run without the metric should have no delta: %+v", failed.Metric)
+	}
+
+	idx, _ := s.ReadIndex()
+	if idx.Computed.BestExperiment != "exp_002" {
+		t.Errorf("index should be rebuilt, best is %q", idx.Computed.BestExperiment)
+	}
+
+	// Importing again adds nothing.
+	res, err = importer.Import(s, "mlflow", runs, importer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 0 || res.Skipped != 3 {
+		t.Errorf("re-import should skip everything, got %+v", res)
+	}
+
+	if _, _, err := importer.ReadMLflow(filepath.Join("testdata", "mlruns"), "nope"); err == nil {
+		t.Error("expected an error for an unknown MLflow experiment")
+	}
+}
+
+func TestCLI_ImportMLflow(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	fixture, err := filepath.Abs(filepath.Join("testdata", "mlruns"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func() string {
+		cmd := exec.Command(bin, "import", "mlflow", fixture)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("import failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+	if out := run(); !strings.Contains(out, "exp_004 ← fff666") || !strings.Contains(out, "Imported 4 mlflow runs") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if out := run(); !strings.Contains(out, "Imported 0 mlflow runs (4 already imported)") {
+		t.Errorf("re-import should be a no-op:\n%s", out)
+	}
+}
+
+func TestImportWandb(t *testing.T) {
+	runs, warnings, err := importer.ReadWandb(filepath.Join("testdata", "wandb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 || len(runs) != 3 {
+		t.Fatalf("expected 3 runs and no duplicate from latest-run, got %d (warnings %v)", len(runs), warnings)
+	}
+
+	s := setupTestStore(t)
+	res, err := importer.Import(s, "wandb", runs, importer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 2 || len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], `r3nometric has no "accuracy" metric`) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	base, _ := s.ReadExperiment("exp_001")
+	if base.Source == nil || base.Source.RunID != "r1base" || base.Metric.Value != 0.8 {
+		t.Fatalf("unexpected baseline: %+v", base)
+	}
+	if base.Params["optimizer.lr"] != 0.1 || base.Params["model"] != "resnet18" || base.Params["epochs"] != 10 || len(base.Params) != 4 {
+		t.Errorf("config should become flat params without W&B keys, got %v", base.Params)
+	}
+	env := base.Environment
+	if env == nil || env.Python != "3.10.12" || env.GPU != "2x Tesla T4" || env.GitCommit != "4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39" {
+		t.Errorf("unexpected environment: %+v", env)
+	}
+
+	child, _ := s.ReadExperiment("exp_002")
+	if child.Status != "improved" || len(child.Parents) != 1 || child.Parents[0] != "exp_001" {
+		t.Errorf("marrow_parent should link the run to its parent: %+v", child)
+	}
+	if c := child.ChangesFrom["exp_001"]; len(c) != 1 || c[0].Param != "optimizer.lr" {
+		t.Errorf("expected the lr change from the parent, got %+v", child.ChangesFrom)
+	}
+	if _, ok := child.Params["marrow_parent"]; ok {
+		t.Error("marrow_parent should not be recorded as a param")
+	}
+	if child.Environment == nil || child.Environment.Python != "3.11.9" || child.Environment.GPU != "NVIDIA A100-SXM4-80GB" {
+		t.Errorf("unexpected child environment: %+v", child.Environment)
+	}
+	if !child.Timestamp.Equal(time.Date(2024, 3, 1, 11, 0, 0, 500000000, time.UTC)) {
+		t.Errorf("timestamp should come from startedAt, got %v", child.Timestamp)
+	}
+
+	// A single run directory can be imported on its own, and only once.
+	one, _, err := importer.ReadWandb(filepath.Join("testdata", "wandb", "offline-run-20240301_110000-r2lowlr"))
+	if err != nil || len(one) != 1 {
+		t.Fatalf("expected one run, got %d: %v", len(one), err)
+	}
+	res, err = importer.Import(s, "wandb", append(runs, one...), importer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 0 || res.Skipped != 2 {
+		t.Errorf("re-import should skip everything, got %+v", res)
+	}
+}
+
+func TestCLI_ImportWandb(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	fixture, err := filepath.Abs(filepath.Join("testdata", "wandb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func() string {
+		cmd := exec.Command(bin, "import", "wandb", fixture, "--tags", "wandb")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("import failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+	out := run()
+	if !strings.Contains(out, "exp_002 ← r2lowlr") || !strings.Contains(out, "Imported 2 wandb runs") || !strings.Contains(out, "warning: run r3nometric") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if out := run(); !strings.Contains(out, "Imported 0 wandb runs (2 already imported)") {
+		t.Errorf("re-import should be a no-op:\n%s", out)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/index"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func TestCompute_PrimaryMetricFromMetricsList(t *testing.T) {
+	proj := model.Project{
+		Metrics: []model.MetricDef{
+			{Name: "auc", Direction: "higher_is_better"},
+			{Name: "latency_ms", Direction: "lower_is_better", Primary: true},
+		},
+	}
+	exps := []model.Experiment{
+		{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "auc", Value: 0.90}, Metrics: map[string]float64{"latency_ms": 30}},
+		{ID: "exp_002", Status: "improved", Parents: []string{"exp_001"}, Metric: model.MetricResult{Name: "auc", Value: 0.85}, Metrics: map[string]float64{"latency_ms": 10}},
+		{ID: "exp_003", Status: "failed", Metric: model.MetricResult{Name: "auc", Value: 0.99}, Metrics: map[string]float64{"latency_ms": 1}},
+	}
+
+	ci := index.Compute(exps, model.LearningsFile{}, model.GraveyardFile{}, proj)
+	if ci.BestExperiment != "exp_002" {
+		t.Errorf("expected exp_002 best on latency_ms, got %s", ci.BestExperiment)
+	}
+	if ci.BestMetric == nil || ci.BestMetric.Name != "latency_ms" || ci.BestMetric.Value != 10 {
+		t.Errorf("unexpected best metric: %+v", ci.BestMetric)
+	}
+	if len(ci.ExperimentChain) != 2 || ci.ExperimentChain[0] != "exp_001" {
+		t.Errorf("unexpected chain: %v", ci.ExperimentChain)
+	}
+	if ci.BestByMetric["auc"] != "exp_001" || ci.BestByMetric["latency_ms"] != "exp_002" {
+		t.Errorf("unexpected best_by_metric: %v", ci.BestByMetric)
+	}
+}
+
+func TestProject_ValidateMetrics(t *testing.T) {
+	proj := model.Project{
+		Metric: model.MetricDef{Name: "auc", Direction: "higher_is_better"},
+		Metrics: []model.MetricDef{
+			{Name: "auc", Direction: "higher_is_better"},
+		},
+	}
+	if err := proj.ValidateMetrics(); err != nil {
+		t.Errorf("primary repeated in metrics should be tolerated, got %v", err)
+	}
+
+	proj.Metrics = []model.MetricDef{
+		{Name: "f1", Direction: "higher_is_better", Primary: true},
+		{Name: "latency_ms", Direction: "lower_is_better", Primary: true},
+	}
+	if err := proj.ValidateMetrics(); err == nil {
+		t.Error("expected error for two primary metrics")
+	}
+
+	proj.Metrics = []model.MetricDef{{Name: "f1", Direction: "sideways"}}
+	if err := proj.ValidateMetrics(); err == nil {
+		t.Error("expected error for invalid direction")
+	}
+}
+
+func TestParetoFront_ExcludesDominatedAndFailed(t *testing.T) {
+	metrics := []model.MetricDef{
+		{Name: "auc", Direction: "higher_is_better"},
+		{Name: "latency_ms", Direction: "lower_is_better"},
+	}
+	exps := []model.Experiment{
+		{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "auc", Value: 0.80}, Metrics: map[string]float64{"latency_ms": 10}},
+		{ID: "exp_002", Status: "improved", Metric: model.MetricResult{Name: "auc", Value: 0.90}, Metrics: map[string]float64{"latency_ms": 40}},
+		{ID: "exp_003", Status: "degraded", Metric: model.MetricResult{Name: "auc", Value: 0.79}, Metrics: map[string]float64{"latency_ms": 12}},
+		{ID: "exp_004", Status: "failed", Metric: model.MetricResult{Name: "auc", Value: 0.99}, Metrics: map[string]float64{"latency_ms": 1}},
+		{ID: "exp_005", Status: "neutral", Metric: model.MetricResult{Name: "auc", Value: 0.95}},
+	}
+
+	front := index.ParetoFront(exps, metrics)
+	if len(front) != 2 || front[0] != "exp_001" || front[1] != "exp_002" {
+		t.Errorf("expected [exp_001 exp_002], got %v", front)
+	}
+
+	if got := index.ParetoFront(exps, metrics[:1]); got != nil {
+		t.Errorf("expected no front for a single metric, got %v", got)
+	}
+}
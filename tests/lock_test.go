@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+func TestCLI_ParallelExpNew_UniqueIDs(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(bin, "exp", "new", "--metric", fmt.Sprintf("0.%d", 50+i), "--status", "neutral")
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("writer %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	s := store.New(dir)
+	exps, err := s.ListExperiments()
+	if err != nil {
+		t.Fatalf("listing experiments: %v", err)
+	}
+	if len(exps) != writers {
+		t.Fatalf("expected %d experiments, got %d", writers, len(exps))
+	}
+
+	cf, err := s.ReadChangelog()
+	if err != nil {
+		t.Fatalf("reading changelog: %v", err)
+	}
+	if len(cf.Entries) != writers {
+		t.Errorf("expected %d changelog entries, got %d", writers, len(cf.Entries))
+	}
+
+	idx, err := s.ReadIndex()
+	if err != nil {
+		t.Fatalf("reading index: %v", err)
+	}
+	if idx.Computed.TotalExperiments != writers {
+		t.Errorf("expected index to count %d experiments, got %d", writers, idx.Computed.TotalExperiments)
+	}
+}
+
+func TestStore_ParallelAddLearning_UniqueIDs(t *testing.T) {
+	dir := setupCLIProject(t)
+
+	const writers = 10
+	var wg sync.WaitGroup
+	ids := make(chan string, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// A separate Store per writer mirrors separate processes.
+			s := store.New(dir)
+			id, err := s.AddLearning(model.Learning{Type: model.LearningProven, Text: fmt.Sprintf("finding %d", i)})
+			if err != nil {
+				t.Errorf("writer %d: %v", i, err)
+				return
+			}
+			ids <- id
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("duplicate learning ID %s", id)
+		}
+		seen[id] = true
+	}
+
+	lf, err := store.New(dir).ReadLearnings()
+	if err != nil {
+		t.Fatalf("reading learnings: %v", err)
+	}
+	if len(lf.Proven) != writers {
+		t.Errorf("expected %d learnings, got %d", writers, len(lf.Proven))
+	}
+}
+
+func TestStore_LockTimeout(t *testing.T) {
+	dir := setupCLIProject(t)
+	holder := store.New(dir)
+
+	held := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- holder.WithLock(func(*store.Store) error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	waiter := store.New(dir)
+	waiter.SetLockTimeout(100 * time.Millisecond)
+	err := waiter.WriteIndex(model.Index{})
+	if !errors.Is(err, store.ErrLockTimeout) {
+		t.Errorf("expected ErrLockTimeout, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("holder failed: %v", err)
+	}
+
+	if err := waiter.WriteIndex(model.Index{}); err != nil {
+		t.Errorf("expected write to succeed after release, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/format"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func digest() format.Digest {
+	d := format.Digest{
+		Project: model.Project{Name: "churn", TaskType: "classification", Metric: model.MetricDef{Name: "auc", Direction: "higher_is_better"}},
+		Index: model.Index{
+			Computed: model.ComputedIndex{
+				TotalExperiments: 3,
+				BestExperiment:   "exp_003",
+				BestMetric:       &model.MetricResult{Name: "auc", Value: 0.88},
+				StatusCounts:     map[string]int{"improved": 2, "neutral": 1},
+			},
+			Pinned: model.PinnedIndex{
+				DoNotTry:     []string{"SMOTE oversampling"},
+				DataWarnings: []string{"customer_id leaks the label"},
+			},
+		},
+		Chain: []model.Experiment{
+			{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "auc", Value: 0.80}, BaseModel: "xgboost"},
+			{ID: "exp_003", Status: "improved", Metric: model.MetricResult{Name: "auc", Value: 0.88}, Notes: "target encoding"},
+		},
+		Graveyard: model.GraveyardFile{Entries: []model.GraveyardEntry{{Approach: "LSTM", Reason: "OOM", ExperimentID: "exp_002"}}},
+	}
+	for i := 1; i <= 40; i++ {
+		d.Learnings.Proven = append(d.Learnings.Proven, model.Learning{Text: fmt.Sprintf("proven learning number %d about the features", i)})
+		d.Learnings.Assumptions = append(d.Learnings.Assumptions, model.Learning{Text: fmt.Sprintf("open assumption number %d to check later", i), Tags: []string{"fe"}})
+	}
+	return d
+}
+
+func TestMarkdownDigest(t *testing.T) {
+	full := format.Markdown(digest(), format.MarkdownOptions{})
+	for _, want := range []string{
+		"# churn\n",
+		"- **Experiments:** 3 (2 improved, 1 neutral)",
+		"## Best result\n\n**exp_003** with auc = 0.8800. It was reached through this chain:",
+		"- exp_001 → baseline xgboost",
+		"## Proven learnings",
+		"- open assumption number 40 to check later _(fe)_",
+		"- **LSTM**: OOM (exp_002)",
+		"## Do not try\n\n- SMOTE oversampling",
+		"## Data warnings\n\n- customer_id leaks the label",
+	} {
+		if !strings.Contains(full, want) {
+			t.Errorf("digest missing %q:\n%s", want, full)
+		}
+	}
+
+	budget := 400
+	small := format.Markdown(digest(), format.MarkdownOptions{Budget: budget})
+	if n := format.EstimateTokens(small); n > budget {
+		t.Errorf("digest is ~%d tokens, over the %d budget", n, budget)
+	}
+	// Assumptions go first, guardrails stay, and the newest items survive.
+	if !strings.Contains(small, "**LSTM**") {
+		t.Errorf("a one-entry graveyard should outlast a long list of learnings:\n%s", small)
+	}
+	for _, want := range []string{"SMOTE oversampling", "customer_id leaks the label", "**exp_003** with auc", "left out to fit the budget, see `marrow learn list`", "proven learning number 40"} {
+		if !strings.Contains(small, want) {
+			t.Errorf("budgeted digest missing %q:\n%s", want, small)
+		}
+	}
+	if strings.Contains(small, "open assumption number 40") {
+		t.Errorf("assumptions should be trimmed before proven learnings:\n%s", small)
+	}
+
+	nested := format.Markdown(digest(), format.MarkdownOptions{Level: 2})
+	if !strings.HasPrefix(nested, "## churn\n") || !strings.Contains(nested, "\n### Graveyard\n") {
+		t.Errorf("headings should start at level 2:\n%s", nested[:200])
+	}
+
+	empty := digest()
+	empty.Graveyard = model.GraveyardFile{}
+	for _, opts := range []format.MarkdownOptions{{}, {Budget: budget}} {
+		if out := format.Markdown(empty, opts); strings.Contains(out, "Graveyard") || strings.Contains(out, "did not work") {
+			t.Errorf("an empty graveyard should be left out (budget %d):\n%s", opts.Budget, out)
+		}
+	}
+}
+
+func TestSyncMarkedSection(t *testing.T) {
+	doc := "# My project\n\nHand-written notes.\n"
+	out, err := format.SyncMarkedSection(doc, "v1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != doc+"\n"+format.MarkerBegin+"\nv1\n"+format.MarkerEnd+"\n" {
+		t.Errorf("unexpected append:\n%s", out)
+	}
+
+	out = strings.Replace(out, format.MarkerEnd, format.MarkerEnd+"\n\nFooter stays.", 1)
+	out, err = format.SyncMarkedSection(out, "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "v1") || !strings.Contains(out, "\nv2\n") || !strings.Contains(out, "Hand-written notes.") || !strings.Contains(out, "Footer stays.") {
+		t.Errorf("unexpected replace:\n%s", out)
+	}
+
+	if _, err := format.SyncMarkedSection(format.MarkerEnd+"\n"+format.MarkerBegin, "x"); err == nil {
+		t.Error("expected an error for markers in the wrong order")
+	}
+}
+
+func TestCLI_ExportMarkdownSync(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	claude := filepath.Join(dir, "CLAUDE.md")
+	writeFile(t, claude, "# Agent notes\n\nKeep this.\n")
+
+	run := func(args ...string) string {
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v failed: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	run("exp", "new", "--metric", "0.8", "--status", "improved")
+	run("learn", "add", "Lag features help", "--type", "proven")
+
+	if out := run("export", "markdown", "--sync", "CLAUDE.md", "--sync", "AGENTS.md"); !strings.Contains(out, "Synced CLAUDE.md") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	run("learn", "add", "Dropout might help", "--type", "assumption")
+	run("export", "markdown", "--sync", "CLAUDE.md")
+
+	raw, err := os.ReadFile(claude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(raw)
+	if !strings.HasPrefix(text, "# Agent notes\n\nKeep this.\n") || strings.Count(text, format.MarkerBegin) != 1 {
+		t.Errorf("sync should keep the file and a single marked section:\n%s", text)
+	}
+	if !strings.Contains(text, "## test-project") || !strings.Contains(text, "Lag features help") || !strings.Contains(text, "Dropout might help") {
+		t.Errorf("marked section should hold the current digest:\n%s", text)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "AGENTS.md")); err != nil {
+		t.Errorf("missing files should be created: %v", err)
+	}
+
+	out := run("export", "markdown", "--budget", "1000")
+	if !strings.HasPrefix(out, "# test-project\n") {
+		t.Errorf("expected the digest on stdout:\n%s", out)
+	}
+}
@@ -397,3 +397,115 @@ func TestCompareExperiments_WarningOnUnreadableProject(t *testing.T) {
 		t.Errorf("expected fallback direction warning, got %q", text)
 	}
 }
+
+// setupMultiMetricStore creates a temp store declaring secondary metrics.
+func setupMultiMetricStore(t *testing.T) *store.Store {
+	t.Helper()
+	dir := t.TempDir()
+	s := store.New(dir)
+	proj := model.Project{
+		Name: "multi-metric",
+		Metric: model.MetricDef{
+			Name:      "auc",
+			Direction: "higher_is_better",
+		},
+		Metrics: []model.MetricDef{
+			{Name: "f1", Direction: "higher_is_better"},
+			{Name: "latency_ms", Direction: "lower_is_better"},
+		},
+	}
+	if err := s.Init(proj); err != nil {
+		t.Fatalf("failed to init store: %v", err)
+	}
+	return s
+}
+
+func TestLogExperiment_SecondaryMetrics(t *testing.T) {
+	s := setupMultiMetricStore(t)
+	srv := mcp.NewServer(s)
+
+	result := callTool(t, srv, "log_experiment", map[string]any{
+		"status":       "improved",
+		"metric_value": 0.85,
+		"metrics":      "f1=0.71,latency_ms=12",
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", resultText(result))
+	}
+
+	exp, err := s.ReadExperiment("exp_001")
+	if err != nil {
+		t.Fatalf("failed to read experiment: %v", err)
+	}
+	if exp.Metrics["f1"] != 0.71 || exp.Metrics["latency_ms"] != 12 {
+		t.Errorf("unexpected secondary metrics: %v", exp.Metrics)
+	}
+
+	result = callTool(t, srv, "log_experiment", map[string]any{
+		"status":       "improved",
+		"metric_value": 0.85,
+		"metrics":      "recall=0.5",
+	})
+	if !result.IsError {
+		t.Error("expected error for undeclared metric")
+	}
+	if !strings.Contains(resultText(result), "unknown metric") {
+		t.Errorf("expected 'unknown metric' message, got %q", resultText(result))
+	}
+}
+
+func TestCompareExperiments_SecondaryMetricDeltas(t *testing.T) {
+	s := setupMultiMetricStore(t)
+	srv := mcp.NewServer(s)
+
+	callTool(t, srv, "log_experiment", map[string]any{
+		"status":       "neutral",
+		"metric_value": 0.80,
+		"metrics":      "f1=0.70,latency_ms=20",
+	})
+	callTool(t, srv, "log_experiment", map[string]any{
+		"status":       "improved",
+		"metric_value": 0.82,
+		"metrics":      "f1=0.68,latency_ms=15",
+	})
+
+	result := callTool(t, srv, "compare_experiments", map[string]any{
+		"id1": "exp_001",
+		"id2": "exp_002",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "f1: -0.0200 (regression)") {
+		t.Errorf("expected f1 regression, got %q", text)
+	}
+	if !strings.Contains(text, "latency_ms: -5.0000 (improvement)") {
+		t.Errorf("expected latency improvement, got %q", text)
+	}
+}
+
+func TestGetParetoFront(t *testing.T) {
+	s := setupMultiMetricStore(t)
+	srv := mcp.NewServer(s)
+
+	for _, m := range []string{"f1=0.70,latency_ms=10", "f1=0.75,latency_ms=30", "f1=0.60,latency_ms=40"} {
+		callTool(t, srv, "log_experiment", map[string]any{
+			"status":       "neutral",
+			"metric_value": 0.80,
+			"metrics":      m,
+		})
+	}
+
+	result := callTool(t, srv, "get_pareto_front", map[string]any{})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "exp_001") || !strings.Contains(text, "exp_002") {
+		t.Errorf("expected exp_001 and exp_002 on the front, got %q", text)
+	}
+	if strings.Contains(text, "exp_003") {
+		t.Errorf("exp_003 is dominated and should be excluded, got %q", text)
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/util"
+)
+
+func TestLoadParamsFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	writeFile(t, yamlPath, "lr: 0.01\nepochs: 3\noptimizer:\n  name: adam\n  betas: [0.9, 0.999]\n")
+	jsonPath := filepath.Join(dir, "config.json")
+	writeFile(t, jsonPath, `{"lr": 0.01, "epochs": 3.0, "optimizer": {"name": "adam", "betas": [0.9, 0.999]}}`)
+
+	fromYAML, err := util.LoadParamsFile(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := util.LoadParamsFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromYAML["optimizer.name"] != "adam" || len(fromYAML) != 4 {
+		t.Errorf("unexpected flattened params: %v", fromYAML)
+	}
+	if diff := util.DiffParams(fromYAML, fromJSON); len(diff) != 0 {
+		t.Errorf("YAML and JSON configs with the same values should not differ, got %+v", diff)
+	}
+
+	writeFile(t, yamlPath, "- not\n- a mapping\n")
+	if _, err := util.LoadParamsFile(yamlPath); err == nil {
+		t.Error("expected an error for a top-level list")
+	}
+}
+
+func TestDiffParams(t *testing.T) {
+	parent := map[string]any{"lr": 0.1, "depth": 6, "dropout": 0.2}
+	child := map[string]any{"lr": 0.01, "depth": 6, "subsample": 0.8}
+
+	got := util.DiffParams(parent, child)
+	want := []model.Change{
+		{Type: "removed", What: "dropout", From: "0.2"},
+		{Type: "param", Param: "lr", From: "0.1", To: "0.01"},
+		{Type: "added", What: "subsample", To: "0.8"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLogExperiment_ParamsComputeChanges(t *testing.T) {
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	callTool(t, srv, "log_experiment", map[string]any{
+		"metric_value": 0.80,
+		"status":       "neutral",
+		"params":       map[string]any{"lr": 0.1, "model": map[string]any{"depth": 6}},
+	})
+	result := callTool(t, srv, "log_experiment", map[string]any{
+		"metric_value": 0.82,
+		"status":       "improved",
+		"parents":      "exp_001",
+		"params":       map[string]any{"lr": 0.01, "model": map[string]any{"depth": 8}},
+		"changes":      []any{"param:model.depth:6->8 (deeper trees)"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+
+	exp, err := s.ReadExperiment("exp_002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := exp.ChangesFrom["exp_001"]
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Param != "lr" || changes[0].From != "0.1" || changes[0].To != "0.01" {
+		t.Errorf("unexpected computed change: %+v", changes[0])
+	}
+	if changes[1].To != "8 (deeper trees)" {
+		t.Errorf("the explicit change should win over the computed one, got %+v", changes[1])
+	}
+	if exp.Params["model.depth"] != 8 {
+		t.Errorf("params should be stored flat, got %v", exp.Params)
+	}
+}
+
+func TestCLI_ExpNew_ParamsFile(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, out)
+		}
+	}
+
+	writeFile(t, filepath.Join(dir, "a.json"), `{"lr": 0.1, "epochs": 10}`)
+	writeFile(t, filepath.Join(dir, "b.yaml"), "lr: 0.1\nepochs: 20\nwarmup: 2\n")
+	run("exp", "new", "--metric", "0.8", "--params-file", "a.json")
+	run("exp", "new", "--metric", "0.81", "--parents", "exp_001", "--params-file", "b.yaml")
+
+	exp, err := store.New(dir).ReadExperiment("exp_002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := exp.ChangesFrom["exp_001"]
+	if len(changes) != 2 || changes[0].Param != "epochs" || changes[0].From != "10" || changes[1].Type != "added" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
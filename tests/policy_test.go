@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/mcp"
+)
+
+func TestPolicy_ReadOnlyBlocksAndLogsWrites(t *testing.T) {
+	s := setupTestStore(t)
+	sess, stop := startStdioSession(t, s, mcp.ServeOptions{WatchInterval: -1, ReadOnly: true})
+	defer stop()
+
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]any{
+		"name":      "update_pinned",
+		"arguments": map[string]any{"field": "do_not_try", "action": "set", "value": "everything"},
+	}})
+	line := sess.expect(`"id":1`)
+	if !strings.Contains(line, `"isError":true`) || !strings.Contains(line, "read-only") {
+		t.Errorf("expected read-only tool error, got %s", line)
+	}
+
+	cl, err := s.ReadChangelog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range cl.Entries {
+		if e.Action == "tool_blocked" && e.ID == "update_pinned" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected tool_blocked changelog entry, got %+v", cl.Entries)
+	}
+
+	idx, err := s.ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Pinned.DoNotTry) != 0 {
+		t.Errorf("blocked call modified pinned: %v", idx.Pinned.DoNotTry)
+	}
+}
+
+func TestPolicy_Allowlist(t *testing.T) {
+	s := setupTestStore(t)
+	sess, stop := startStdioSession(t, s, mcp.ServeOptions{WatchInterval: -1, Tools: []string{"get_*", "log_experiment"}})
+	defer stop()
+
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/list"})
+	line := sess.expect(`"id":1`)
+	if !strings.Contains(line, `"get_project_summary"`) || !strings.Contains(line, `"log_experiment"`) {
+		t.Errorf("expected allowed tools listed, got %s", line)
+	}
+	if strings.Contains(line, `"add_learning"`) || strings.Contains(line, `"query_experiments"`) {
+		t.Errorf("expected other tools hidden, got %s", line)
+	}
+
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": map[string]any{
+		"name":      "query_experiments",
+		"arguments": map[string]any{"query": "status=improved"},
+	}})
+	line = sess.expect(`"id":2`)
+	if !strings.Contains(line, `"isError":true`) || !strings.Contains(line, "allowlist") {
+		t.Errorf("expected allowlist tool error, got %s", line)
+	}
+
+	// Blocked reads are refused but not logged.
+	cl, err := s.ReadChangelog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range cl.Entries {
+		if e.Action == "tool_blocked" {
+			t.Errorf("unexpected changelog entry for blocked read: %+v", e)
+		}
+	}
+}
+
+func TestPolicy_UnknownToolPattern(t *testing.T) {
+	err := mcp.ServeIO(context.Background(), setupTestStore(t),
+		mcp.ServeOptions{WatchInterval: -1, Tools: []string{"get_*", "log_experiments"}},
+		strings.NewReader(""), &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "log_experiments") {
+		t.Errorf("expected error naming the bad pattern, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/index"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+func getPromptText(t *testing.T, srv *server.MCPServer, name string, args map[string]any) (string, error) {
+	t.Helper()
+	raw, err := rpc(t, srv, "prompts/get", map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func TestPrompts_BuiltFromStore(t *testing.T) {
+	s := setupTestStore(t)
+	now := time.Now().UTC()
+	for _, e := range []model.Experiment{
+		{ID: "exp_001", Timestamp: now.AddDate(0, 0, -30), Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.80}},
+		{ID: "exp_002", Timestamp: now, Status: "improved", Parents: []string{"exp_001"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.85}, Tags: []string{"fe"}},
+		{ID: "exp_003", Timestamp: now, Status: "failed", Parents: []string{"exp_002"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.50}, Tags: []string{"fe"}, Notes: "target leak"},
+	} {
+		if err := s.WriteExperiment(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddGraveyardEntry(model.GraveyardEntry{Approach: "mean encoding", Reason: "leaks", Tags: []string{"fe"}}); err != nil {
+		t.Fatal(err)
+	}
+	idx, _ := s.ReadIndex()
+	idx.Pinned.DoNotTry = []string{"drop id column"}
+	if err := s.WriteIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := index.Rebuild(s); err != nil {
+		t.Fatal(err)
+	}
+	srv := mcp.NewServer(s)
+
+	raw, err := rpc(t, srv, "prompts/list", map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"start_session", "plan_next_experiment", "postmortem_failed_run", "write_weekly_report"} {
+		if !strings.Contains(string(raw), name) {
+			t.Errorf("prompts/list missing %s", name)
+		}
+	}
+
+	text, err := getPromptText(t, srv, "start_session", map[string]any{"goal": "beat 0.9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"test-project", "exp_001", "drop id column", "beat 0.9"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("start_session missing %q", want)
+		}
+	}
+
+	text, err = getPromptText(t, srv, "plan_next_experiment", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "mean encoding") || !strings.Contains(text, "Best Chain") {
+		t.Errorf("plan_next_experiment missing graveyard or chain: %s", text)
+	}
+
+	// Defaults to the most recent failed run.
+	text, err = getPromptText(t, srv, "postmortem_failed_run", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "exp_003") || !strings.Contains(text, "target leak") || !strings.Contains(text, "mean encoding") {
+		t.Errorf("postmortem missing experiment or related graveyard: %s", text)
+	}
+
+	text, err = getPromptText(t, srv, "write_weekly_report", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recent := text[strings.Index(text, "Experiments since"):]
+	if !strings.Contains(recent, "exp_002") || strings.Contains(recent, "exp_001") {
+		t.Errorf("weekly report should only list recent experiments: %s", recent)
+	}
+
+	if _, err := getPromptText(t, srv, "write_weekly_report", map[string]any{"since": "last week"}); err == nil {
+		t.Error("expected error for invalid since date")
+	}
+}
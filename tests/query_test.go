@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/query"
+)
+
+func queryFixture() ([]model.Experiment, model.Project) {
+	proj := model.Project{
+		Name:   "q",
+		Metric: model.MetricDef{Name: "auc", Direction: "higher_is_better"},
+	}
+	cv := 0.81
+	lb := 0.79
+	seed := 42
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 12, 0, 0, 0, time.UTC) }
+	exps := []model.Experiment{
+		{ID: "exp_001", Timestamp: day(1), BaseModel: "xgboost", Status: "neutral",
+			Metric: model.MetricResult{Name: "auc", Value: 0.80}, Tags: []string{"baseline"}},
+		{ID: "exp_002", Timestamp: day(5), BaseModel: "xgboost", Status: "improved",
+			Metric: model.MetricResult{Name: "auc", Value: 0.87}, Tags: []string{"feature_eng"},
+			Notes: "Added target encoding", LocalCV: &cv, PublicLB: &lb, DataVersion: 2,
+			Environment: &model.Environment{Python: "3.11.4", SplitSeed: &seed,
+				KeyPackages: map[string]string{"xgboost": "2.0.3"}}},
+		{ID: "exp_003", Timestamp: day(9), BaseModel: "lightgbm", Status: "failed",
+			Metric: model.MetricResult{Name: "auc", Value: 0.60}, Tags: []string{"feature_eng"},
+			Reasoning: model.Reasoning{Type: "assumption", Text: "Suspected leakage in fold split"}},
+	}
+	return exps, proj
+}
+
+func queryIDs(t *testing.T, expr string) []string {
+	t.Helper()
+	exps, proj := queryFixture()
+	q, err := query.Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expr, err)
+	}
+	var ids []string
+	for _, e := range q.Filter(exps, proj) {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestQuery_Filter(t *testing.T) {
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"", "exp_001,exp_002,exp_003"},
+		{"model=xgboost and metric>0.85 and tag:feature_eng and since:2026-09-01", "exp_002"},
+		{"model=XGBoost metric<=0.80", "exp_001"},
+		{"status=improved or status=failed", "exp_002,exp_003"},
+		{"not (status=failed) and tag:feature_eng", "exp_002"},
+		{"leakage", "exp_003"},
+		{`"target encoding"`, "exp_002"},
+		{"text:encoding or reasoning_type=assumption", "exp_002,exp_003"},
+		{"local_cv>0.8 and public_lb<0.8 and data_version=2", "exp_002"},
+		{"local_cv<0.8", ""},
+		{"python~3.11 and split_seed=42 and pkg.xgboost=2.0.3", "exp_002"},
+		{"until:2026-09-05", "exp_001,exp_002"},
+		{"since:2026-09-05T12:00:00Z", "exp_002,exp_003"},
+		{"(until:2026-09-05T11:59:59Z)", "exp_001"},
+		{`since:"2026-09-05T12:00:00Z" and status=failed`, "exp_003"},
+		{"tag!=baseline", "exp_002,exp_003"},
+		{"id~00 and model!=lightgbm", "exp_001,exp_002"},
+	}
+	for _, c := range cases {
+		got := strings.Join(queryIDs(t, c.expr), ",")
+		if got != c.want {
+			t.Errorf("%q: got %q, want %q", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestQuery_ParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"metric>abc",
+		"metric~0.8",
+		"model>xgboost",
+		"since:yesterday",
+		"(status=improved",
+		"status=",
+		`notes="unterminated`,
+		"metric=>0.8",
+	} {
+		if _, err := query.Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error", expr)
+		}
+	}
+}
+
+func TestQuery_Sort(t *testing.T) {
+	exps, proj := queryFixture()
+	if err := query.Sort(exps, "best", proj); err != nil {
+		t.Fatal(err)
+	}
+	if exps[0].ID != "exp_002" || exps[2].ID != "exp_003" {
+		t.Errorf("best sort: got %s,%s,%s", exps[0].ID, exps[1].ID, exps[2].ID)
+	}
+
+	// Experiments without a value sort last in both directions.
+	for _, key := range []string{"local_cv", "-local_cv"} {
+		if err := query.Sort(exps, key, proj); err != nil {
+			t.Fatal(err)
+		}
+		if exps[0].ID != "exp_002" {
+			t.Errorf("%s: expected exp_002 first, got %s", key, exps[0].ID)
+		}
+	}
+
+	if err := query.Sort(exps, "tag", proj); err == nil {
+		t.Error("expected error sorting by a list field")
+	}
+}
+
+func TestQueryExperiments_Tool(t *testing.T) {
+	s := setupTestStore(t)
+	exps, _ := queryFixture()
+	for _, e := range exps {
+		e.Metric.Name = "accuracy"
+		if err := s.WriteExperiment(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := mcp.NewServer(s)
+
+	r := callTool(t, srv, "query_experiments", map[string]any{
+		"query": "tag:feature_eng",
+		"sort":  "best",
+		"limit": 1,
+	})
+	text := resultText(r)
+	if r.IsError || !strings.Contains(text, "exp_002") || strings.Contains(text, "exp_003") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	r = callTool(t, srv, "query_experiments", map[string]any{"query": "metric>"})
+	if !r.IsError {
+		t.Errorf("expected tool error for invalid query, got %s", resultText(r))
+	}
+	r = callTool(t, srv, "query_experiments", map[string]any{"query": "metrc>0.84"})
+	if !r.IsError || !strings.Contains(resultText(r), `unknown field "metrc"`) {
+		t.Errorf("expected tool error for a misspelled field, got %s", resultText(r))
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	exps, proj := queryFixture()
+	exps[1].Metrics = map[string]float64{"val_loss": 0.3}
+	for _, expr := range []string{"auc>0.8", "metrics.val_loss<0.5", "cv>0.8", "pkg.torch=2.3", "tag:x or not seed=1"} {
+		q, err := query.Parse(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Validate(proj, exps); err != nil {
+			t.Errorf("%q: %v", expr, err)
+		}
+	}
+	if ids := strings.Join(queryIDs(t, "auc>0.85"), ","); ids != "exp_002" {
+		t.Errorf("the primary metric should be queryable by name, got %q", ids)
+	}
+
+	q, _ := query.Parse("metrc>0.84 or status=improved")
+	err := q.Validate(proj, exps)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "metrc"`) || !strings.Contains(err.Error(), "metric, model") || !strings.Contains(err.Error(), "the metrics auc, val_loss") {
+		t.Errorf("expected an unknown field error listing valid names, got %v", err)
+	}
+	if err := query.Sort(exps, "-metrc", proj); err == nil {
+		t.Error("expected an unknown field error for the sort key")
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/report"
+)
+
+func TestReport_Write(t *testing.T) {
+	s := tuiStore(t)
+	if _, err := s.AddLearning(model.Learning{Type: model.LearningProven, Text: "Warmup <script>alert(1)</script> helps", Tags: []string{"lr"}}); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := s.ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.Pinned.DoNotTry = []string{"target leakage via ids"}
+	if err := s.WriteIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendChangelog(model.ChangelogEntry{Action: "exp_logged", ID: "exp_004", Summary: "logged exp_004"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "out", "report")
+	path, err := report.Write(s, dir, report.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "index.html") {
+		t.Errorf("unexpected path %s", path)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(raw)
+
+	for _, want := range []string{
+		"<title>test-project · marrow report</title>",
+		`aria-label="accuracy over time"`,
+		`aria-label="accuracy by tag"`,
+		`aria-label="experiment DAG"`,
+		`<g class="node improved chain best"><title>exp_003`,
+		`class="edge chain"`,
+		"<li>exp_001 → ",
+		"Warmup &lt;script&gt;alert(1)&lt;/script&gt; helps",
+		"<strong>label smoothing</strong> — hurt calibration",
+		"target leakage via ids",
+		"logged exp_004",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"<script", "http://", "https://"} {
+		if strings.Contains(page, unwanted) {
+			t.Errorf("report should be self-contained, found %q", unwanted)
+		}
+	}
+}
+
+func TestReport_CLI(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+
+	cmd := exec.Command(bin, "report", "--out", "site")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("report failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), filepath.Join("site", "index.html")) {
+		t.Errorf("unexpected output: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "site", "index.html")); err != nil {
+		t.Error(err)
+	}
+}
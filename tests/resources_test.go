@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gomcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+)
+
+// rpc sends a JSON-RPC request and returns the raw result for decoding.
+func rpc(t *testing.T, srv *server.MCPServer, method string, params map[string]any) (json.RawMessage, error) {
+	t.Helper()
+	msg, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	out, err := json.Marshal(srv.HandleMessage(context.Background(), msg))
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error != nil {
+		return nil, &rpcError{resp.Error.Message}
+	}
+	return resp.Result, nil
+}
+
+type rpcError struct{ msg string }
+
+func (e *rpcError) Error() string { return e.msg }
+
+func listResourceURIs(t *testing.T, srv *server.MCPServer) []string {
+	t.Helper()
+	raw, err := rpc(t, srv, "resources/list", map[string]any{})
+	if err != nil {
+		t.Fatalf("resources/list: %v", err)
+	}
+	var res gomcp.ListResourcesResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range res.Resources {
+		uris = append(uris, r.URI)
+	}
+	return uris
+}
+
+func readResourceText(t *testing.T, srv *server.MCPServer, uri string) (string, error) {
+	t.Helper()
+	raw, err := rpc(t, srv, "resources/read", map[string]any{"uri": uri})
+	if err != nil {
+		return "", err
+	}
+	var res struct {
+		Contents []struct {
+			Text string `json:"text"`
+		} `json:"contents"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Contents) == 0 {
+		return "", nil
+	}
+	return res.Contents[0].Text, nil
+}
+
+func TestResources_ListReflectsStore(t *testing.T) {
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	uris := strings.Join(listResourceURIs(t, srv), " ")
+	for _, want := range []string{"marrow://learnings", "marrow://graveyard", "marrow://index"} {
+		if !strings.Contains(uris, want) {
+			t.Errorf("expected %s in %s", want, uris)
+		}
+	}
+	if strings.Contains(uris, "marrow://experiments/") {
+		t.Errorf("expected no experiment resources yet, got %s", uris)
+	}
+
+	// Files written after startup show up on the next listing.
+	exp := model.Experiment{ID: "exp_001", Timestamp: time.Now().UTC(), Status: "improved",
+		Metric: model.MetricResult{Name: "accuracy", Value: 0.9}}
+	if err := s.WriteExperiment(exp); err != nil {
+		t.Fatal(err)
+	}
+	ctxPath := filepath.Join(s.Root(), "context", "eda.yaml")
+	if err := os.WriteFile(ctxPath, []byte("rows: 1000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	uris = strings.Join(listResourceURIs(t, srv), " ")
+	for _, want := range []string{"marrow://experiments/exp_001", "marrow://context/eda"} {
+		if !strings.Contains(uris, want) {
+			t.Errorf("expected %s in %s", want, uris)
+		}
+	}
+
+	if err := s.DeleteExperiment("exp_001"); err != nil {
+		t.Fatal(err)
+	}
+	if uris := strings.Join(listResourceURIs(t, srv), " "); strings.Contains(uris, "exp_001") {
+		t.Errorf("expected exp_001 to be dropped, got %s", uris)
+	}
+}
+
+func TestResources_Read(t *testing.T) {
+	s := setupTestStore(t)
+	exp := model.Experiment{ID: "exp_001", Timestamp: time.Now().UTC(), Status: "improved",
+		Metric: model.MetricResult{Name: "accuracy", Value: 0.9}, Notes: "first run"}
+	if err := s.WriteExperiment(exp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddLearning(model.Learning{Type: model.LearningProven, Text: "scaling helps"}); err != nil {
+		t.Fatal(err)
+	}
+	srv := mcp.NewServer(s)
+
+	text, err := readResourceText(t, srv, "marrow://experiments/exp_001")
+	if err != nil || !strings.Contains(text, "first run") {
+		t.Errorf("experiment resource: %q, %v", text, err)
+	}
+	text, err = readResourceText(t, srv, "marrow://learnings")
+	if err != nil || !strings.Contains(text, "scaling helps") {
+		t.Errorf("learnings resource: %q, %v", text, err)
+	}
+	if _, err := readResourceText(t, srv, "marrow://index"); err != nil {
+		t.Errorf("index resource: %v", err)
+	}
+
+	// Template lookups work for IDs not yet registered as concrete resources.
+	if err := s.WriteExperiment(model.Experiment{ID: "exp_002", Status: "neutral"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readResourceText(t, srv, "marrow://experiments/exp_002"); err != nil {
+		t.Errorf("templated experiment resource: %v", err)
+	}
+	if _, err := readResourceText(t, srv, "marrow://experiments/exp_999"); err == nil {
+		t.Error("expected error for missing experiment")
+	}
+	if _, err := readResourceText(t, srv, "marrow://context/..%2Fmarrow"); err == nil {
+		t.Error("expected error for unsafe context name")
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+func TestSnapshot_DiffRestoreDelete(t *testing.T) {
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	callTool(t, srv, "log_experiment", map[string]any{"status": "neutral", "metric_value": 0.80})
+	result := callTool(t, srv, "create_snapshot", map[string]any{"name": "checkpoint"})
+	if result.IsError {
+		t.Fatalf("create_snapshot failed: %s", resultText(result))
+	}
+
+	callTool(t, srv, "log_experiment", map[string]any{"status": "improved", "metric_value": 0.85, "parents": "exp_001"})
+	callTool(t, srv, "add_learning", map[string]any{"text": "risky finding", "type": "assumption"})
+	callTool(t, srv, "update_pinned", map[string]any{"field": "do_not_try", "action": "add", "value": "bagging"})
+
+	result = callTool(t, srv, "diff_snapshot", map[string]any{"name": "checkpoint"})
+	if result.IsError {
+		t.Fatalf("diff_snapshot failed: %s", resultText(result))
+	}
+	text := resultText(result)
+	for _, want := range []string{"+ exp_002", "+ learn_001", "+ do_not_try: bagging"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in diff, got %q", want, text)
+		}
+	}
+
+	result = callTool(t, srv, "restore_snapshot", map[string]any{"name": "checkpoint"})
+	if result.IsError {
+		t.Fatalf("restore_snapshot failed: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "before-restore") {
+		t.Errorf("expected automatic backup name in result, got %q", resultText(result))
+	}
+
+	exps, err := s.ListExperiments()
+	if err != nil {
+		t.Fatalf("listing experiments: %v", err)
+	}
+	if len(exps) != 1 {
+		t.Errorf("expected 1 experiment after restore, got %d", len(exps))
+	}
+	lf, err := s.ReadLearnings()
+	if err != nil {
+		t.Fatalf("reading learnings: %v", err)
+	}
+	if len(lf.Assumptions) != 0 {
+		t.Errorf("expected learnings to be restored, got %d assumptions", len(lf.Assumptions))
+	}
+
+	names, err := s.ListSnapshots()
+	if err != nil {
+		t.Fatalf("listing snapshots: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected checkpoint and automatic backup, got %v", names)
+	}
+
+	result = callTool(t, srv, "delete_snapshot", map[string]any{"name": "checkpoint"})
+	if result.IsError {
+		t.Fatalf("delete_snapshot failed: %s", resultText(result))
+	}
+	if _, err := s.ResolveSnapshot("checkpoint"); err == nil {
+		t.Error("expected checkpoint to be gone after delete")
+	}
+}
+
+func TestCLI_SnapshotRestore(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("marrow %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+
+	run("exp", "new", "--metric", "0.8")
+	run("snapshot", "create", "--name", "base")
+	run("exp", "new", "--metric", "0.9")
+
+	if out := run("snapshot", "diff", "base"); !strings.Contains(out, "+ exp_002") {
+		t.Errorf("expected exp_002 in diff, got %q", out)
+	}
+
+	run("snapshot", "restore", "base")
+
+	exps, err := store.New(dir).ListExperiments()
+	if err != nil {
+		t.Fatalf("listing experiments: %v", err)
+	}
+	if len(exps) != 1 {
+		t.Errorf("expected 1 experiment after restore, got %d", len(exps))
+	}
+
+	cf, err := store.New(dir).ReadChangelog()
+	if err != nil {
+		t.Fatalf("reading changelog: %v", err)
+	}
+	last := cf.Entries[len(cf.Entries)-1]
+	if last.Action != "snapshot_restored" {
+		t.Errorf("expected snapshot_restored as last changelog entry, got %s", last.Action)
+	}
+}
+
+func TestSnapshot_RestoreTwice(t *testing.T) {
+	s := setupTestStore(t)
+	srv := mcp.NewServer(s)
+
+	callTool(t, srv, "log_experiment", map[string]any{"status": "neutral", "metric_value": 0.80})
+	callTool(t, srv, "create_snapshot", map[string]any{"name": "s1"})
+
+	// Both restores land in the same second; their backups must not collide.
+	var backups []string
+	for i := 0; i < 2; i++ {
+		result := callTool(t, srv, "restore_snapshot", map[string]any{"name": "s1"})
+		if result.IsError {
+			t.Fatalf("restore %d failed: %s", i+1, resultText(result))
+		}
+		_, backup, _ := strings.Cut(resultText(result), "previous state saved as ")
+		backups = append(backups, strings.TrimSuffix(backup, ")"))
+	}
+	if backups[0] == backups[1] {
+		t.Errorf("expected two distinct backups, got %v", backups)
+	}
+	if names, _ := s.ListSnapshots(); len(names) != 3 {
+		t.Errorf("expected s1 and two backups, got %v", names)
+	}
+
+	cf, err := s.ReadChangelog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(cf.Entries)
+	if n < 2 || cf.Entries[n-2].Action != "snapshot_created" || cf.Entries[n-2].Summary != backups[1] || cf.Entries[n-1].Action != "snapshot_restored" {
+		t.Errorf("expected the backup to be logged before the restore, got %+v", cf.Entries)
+	}
+}
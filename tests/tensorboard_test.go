@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/importer"
+)
+
+// Minimal protobuf and TFRecord encoding for writing TensorBoard event files.
+
+func pbKey(num, wire int) []byte { return binary.AppendUvarint(nil, uint64(num<<3|wire)) }
+
+func pbBytes(num int, b []byte) []byte {
+	out := append(pbKey(num, 2), binary.AppendUvarint(nil, uint64(len(b)))...)
+	return append(out, b...)
+}
+
+func pbVarint(num int, v uint64) []byte { return binary.AppendUvarint(pbKey(num, 0), v) }
+
+func pbFloat(num int, f float32) []byte {
+	return binary.LittleEndian.AppendUint32(pbKey(num, 5), math.Float32bits(f))
+}
+
+// tbEvent encodes an Event with a wall time, a step and summary values.
+func tbEvent(wall float64, step int64, values ...[]byte) []byte {
+	ev := binary.LittleEndian.AppendUint64(pbKey(1, 1), math.Float64bits(wall))
+	ev = append(ev, pbVarint(2, uint64(step))...)
+	if len(values) == 0 {
+		return append(ev, pbBytes(3, []byte("brain.Event:2"))...) // file_version
+	}
+	var summary []byte
+	for _, v := range values {
+		summary = append(summary, pbBytes(1, v)...)
+	}
+	return append(ev, pbBytes(5, summary)...)
+}
+
+// simpleValue is a scalar as PyTorch and TF1 write it.
+func simpleValue(tag string, v float32) []byte {
+	return append(pbBytes(1, []byte(tag)), pbFloat(2, v)...)
+}
+
+// tensorValue is a float tensor as TF2 writes it, with packed float_val.
+func tensorValue(tag string, vs ...float32) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = binary.LittleEndian.AppendUint32(packed, math.Float32bits(v))
+	}
+	tensor := append(pbVarint(1, 1), pbBytes(5, packed)...)
+	return append(pbBytes(1, []byte(tag)), pbBytes(8, tensor)...)
+}
+
+func tbRecord(data []byte) []byte {
+	mask := func(b []byte) uint32 {
+		c := crc32.Checksum(b, crc32.MakeTable(crc32.Castagnoli))
+		return (c>>15 | c<<17) + 0xa282ead8
+	}
+	length := binary.LittleEndian.AppendUint64(nil, uint64(len(data)))
+	out := binary.LittleEndian.AppendUint32(length, mask(length))
+	out = append(out, data...)
+	return binary.LittleEndian.AppendUint32(out, mask(data))
+}
+
+func writeEvents(t *testing.T, path string, events ...[]byte) {
+	t.Helper()
+	var data []byte
+	for _, ev := range events {
+		data = append(data, tbRecord(ev)...)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tbLogdir writes two runs: "baseline" in PyTorch style and "nested/lr_small"
+// in TF2 style, resumed from a checkpoint and cut off mid-record.
+func tbLogdir(t *testing.T) string {
+	dir := t.TempDir()
+	writeEvents(t, filepath.Join(dir, "baseline", "events.out.tfevents.1700000000.host.1.0"),
+		tbEvent(1700000000.5, 0),
+		tbEvent(1700000001, 0, simpleValue("accuracy", 0.5), simpleValue("loss", 1.2)),
+		tbEvent(1700000002, 1, simpleValue("accuracy", 0.7)),
+		tbEvent(1700000003, 2, simpleValue("accuracy", 0.9), tensorValue("weights", 0.1, 0.2, 0.3)),
+		tbEvent(1700000004, 3, simpleValue("accuracy", 0.85)),
+		tbEvent(1700000005, 4, simpleValue("accuracy", 0.8), simpleValue("loss", 0.4)),
+	)
+
+	run := filepath.Join(dir, "nested", "lr_small")
+	writeEvents(t, filepath.Join(run, "events.out.tfevents.1700001000.host.2.v2"),
+		tbEvent(1700001000, 0),
+		tbEvent(1700001001, 0, tensorValue("accuracy", 0.6)),
+		tbEvent(1700001002, 1, tensorValue("accuracy", 0.75)),
+	)
+	second := filepath.Join(run, "events.out.tfevents.1700002000.host.3.v2")
+	writeEvents(t, second,
+		tbEvent(1700002000, 0),
+		tbEvent(1700002001, 1, tensorValue("accuracy", 0.77)),
+	)
+	f, err := os.OpenFile(second, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(tbRecord(tbEvent(1700002002, 2, tensorValue("accuracy", 0.9)))[:20])
+	f.Close()
+	return dir
+}
+
+func TestReadTensorBoard(t *testing.T) {
+	runs, warnings, err := importer.ReadTensorBoard(tbLogdir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 || len(runs) != 2 {
+		t.Fatalf("expected 2 runs without warnings, got %d: %v", len(runs), warnings)
+	}
+
+	base := runs[0]
+	if base.ID != "baseline" || base.Start.Unix() != 1700000000 || base.Metrics["accuracy"] != 0.8 || base.Metrics["loss"] != 0.4 {
+		t.Errorf("unexpected baseline run: %+v", base)
+	}
+	if c := base.Curves["accuracy"]; len(c) != 5 || c[2].Step != 2 || c[2].Value != 0.9 {
+		t.Errorf("unexpected accuracy curve: %v", c)
+	}
+	if _, ok := base.Curves["weights"]; ok {
+		t.Error("tensors with several values are not scalars")
+	}
+
+	// The resumed step 1 replaces the first write, and the torn record at
+	// the end is dropped.
+	resumed := runs[1]
+	if c := resumed.Curves["accuracy"]; resumed.ID != "nested/lr_small" || len(c) != 2 || c[1].Value != 0.77 {
+		t.Errorf("unexpected resumed run %s: %v", resumed.ID, c)
+	}
+}
+
+func TestImportTensorBoard(t *testing.T) {
+	logdir := tbLogdir(t)
+	runs, _, err := importer.ReadTensorBoard(logdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := setupTestStore(t)
+	res, err := importer.Import(s, "tensorboard", runs, importer.Options{Update: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	exp, _ := s.ReadExperiment("exp_001")
+	if exp.Metric.Value != 0.8 || exp.Notes != "accuracy: final 0.8000 at step 4, best 0.9000 at step 2" {
+		t.Errorf("unexpected experiment: value %v, notes %q", exp.Metric.Value, exp.Notes)
+	}
+	if len(exp.Artifacts) != 1 || exp.Artifacts[0].Kind != "curve" || exp.Artifacts[0].Path != ".marrow/experiments/exp_001/curves/accuracy.csv" || len(exp.Artifacts[0].SHA256) != 64 {
+		t.Fatalf("unexpected artifacts: %+v", exp.Artifacts)
+	}
+	csv, err := os.ReadFile(filepath.Join(filepath.Dir(s.Root()), exp.Artifacts[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(csv) != "step,value\n0,0.5\n1,0.7\n2,0.9\n3,0.85\n4,0.8\n" || exp.Artifacts[0].Size != int64(len(csv)) {
+		t.Errorf("unexpected curve file:\n%s", csv)
+	}
+	if st := exp.Curves["accuracy"]; st.Points != 5 || st.BestStep != 2 || st.FinalStep != 4 {
+		t.Errorf("unexpected curve stats: %+v", st)
+	}
+
+	// The run keeps training; a note written by hand survives the update.
+	other, _ := s.ReadExperiment("exp_002")
+	other.Notes = "resumed after OOM"
+	if err := s.WriteExperiment(other); err != nil {
+		t.Fatal(err)
+	}
+	writeEvents(t, filepath.Join(logdir, "baseline", "events.out.tfevents.1700000100.host.1.1"),
+		tbEvent(1700000100, 5, simpleValue("accuracy", 0.95)),
+	)
+	runs, _, err = importer.ReadTensorBoard(logdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = importer.Import(s, "tensorboard", runs, importer.Options{Update: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 0 || len(res.Updated) != 1 || res.Updated[0].Experiment != "exp_001" || res.Skipped != 1 {
+		t.Fatalf("expected one update and one unchanged run, got %+v", res)
+	}
+	exp, _ = s.ReadExperiment("exp_001")
+	if exp.Metric.Value != 0.95 || !strings.HasPrefix(exp.Notes, "accuracy: final 0.9500 at step 5") || len(exp.Artifacts) != 1 {
+		t.Errorf("update should refresh value, notes and curve: %+v", exp)
+	}
+	if other, _ = s.ReadExperiment("exp_002"); other.Notes != "resumed after OOM" {
+		t.Errorf("hand-written notes should be kept, got %q", other.Notes)
+	}
+
+	// --best takes the peak instead.
+	best := setupTestStore(t)
+	if _, err := importer.Import(best, "tensorboard", runs[:1], importer.Options{Best: true}); err != nil {
+		t.Fatal(err)
+	}
+	if exp, _ := best.ReadExperiment("exp_001"); exp.Metric.Value != 0.95 {
+		t.Errorf("best value should be 0.95, got %v", exp.Metric.Value)
+	}
+
+	if err := s.DeleteExperiment("exp_001"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Root(), "experiments", "exp_001")); !os.IsNotExist(err) {
+		t.Errorf("deleting an experiment should remove its curves: %v", err)
+	}
+}
+
+func TestReadTensorBoard_CorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.out.tfevents.1.host")
+	writeEvents(t, path, tbEvent(1, 0, simpleValue("accuracy", 0.5)), tbEvent(2, 1, simpleValue("accuracy", 0.6)))
+	data, _ := os.ReadFile(path)
+	data[len(data)-6] ^= 0xff
+	os.WriteFile(path, data, 0644)
+
+	runs, warnings, err := importer.ReadTensorBoard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "corrupt record") {
+		t.Errorf("expected a corrupt record warning, got %v", warnings)
+	}
+	if len(runs) != 1 || runs[0].ID != filepath.Base(dir) || len(runs[0].Curves["accuracy"]) != 1 {
+		t.Errorf("records before the corrupt one should be kept: %+v", runs)
+	}
+}
+
+func TestCLI_ImportTensorBoard(t *testing.T) {
+	bin := buildBinary(t)
+	dir := setupCLIProject(t)
+	logdir := tbLogdir(t)
+
+	run := func() string {
+		cmd := exec.Command(bin, "import", "tensorboard", logdir, "--best")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("import failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+	if out := run(); !strings.Contains(out, "exp_002 ← nested/lr_small") || !strings.Contains(out, "Imported 2 tensorboard runs") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	writeEvents(t, filepath.Join(logdir, "baseline", "events.out.tfevents.1700000100.host.1.1"),
+		tbEvent(1700000100, 5, simpleValue("accuracy", 0.95)),
+	)
+	if out := run(); !strings.Contains(out, "exp_001 ↻ baseline") || !strings.Contains(out, "Imported 0 tensorboard runs (1 updated, 1 unchanged)") {
+		t.Errorf("re-import should update the grown run:\n%s", out)
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rzzdr/marrow/internal/index"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+	"github.com/rzzdr/marrow/internal/tui"
+)
+
+var ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+func tuiStore(t *testing.T) *store.Store {
+	t.Helper()
+	s := setupTestStore(t)
+	exps := []model.Experiment{
+		{ID: "exp_001", Status: "neutral", Metric: model.MetricResult{Name: "accuracy", Value: 0.80}},
+		{ID: "exp_002", Status: "improved", Parents: []string{"exp_001"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.85}, Tags: []string{"lr"}},
+		{ID: "exp_003", Status: "improved", Parents: []string{"exp_002"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.88}},
+		{ID: "exp_004", Status: "degraded", Parents: []string{"exp_001", "exp_002"}, Metric: model.MetricResult{Name: "accuracy", Value: 0.79}, Tags: []string{"fe"}},
+	}
+	for _, e := range exps {
+		if err := s.WriteExperiment(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddGraveyardEntry(model.GraveyardEntry{Approach: "label smoothing", Reason: "hurt calibration"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := index.Rebuild(s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func view(a *tui.App) string {
+	return ansi.ReplaceAllString(a.View(120, 30), "")
+}
+
+func TestTUI_TreeAndPanels(t *testing.T) {
+	app, err := tui.NewApp(tuiStore(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := view(app)
+	for _, want := range []string{"★ exp_001", "├─ ★ exp_002", "└─ ★ exp_003", "└─   exp_004 degraded", "(+exp_002)", "id: exp_001", "[Learnings]", "best exp_003"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("view missing %q:\n%s", want, v)
+		}
+	}
+
+	app.Feed([]byte("\x1b[B")) // down
+	if v := view(app); !strings.Contains(v, "id: exp_002") {
+		t.Errorf("details should follow the cursor:\n%s", v)
+	}
+
+	app.Feed([]byte("\t"))
+	if v := view(app); !strings.Contains(v, "[Graveyard]") || !strings.Contains(v, "label smoothing") {
+		t.Errorf("tab should switch to the graveyard panel:\n%s", v)
+	}
+
+	app.Feed([]byte("tfe\r"))
+	v = view(app)
+	if !strings.Contains(v, "filter: tag=fe") || !strings.Contains(v, "exp_004") || strings.Contains(v, "exp_003 improved") {
+		t.Errorf("tag filter should keep exp_004 and its ancestors only:\n%s", v)
+	}
+	app.Feed([]byte("c"))
+	if v := view(app); !strings.Contains(v, "exp_003 improved") {
+		t.Errorf("clearing filters should show everything:\n%s", v)
+	}
+
+	if quit := app.Feed([]byte("q")); !quit {
+		t.Error("q should quit")
+	}
+}
+
+func TestTUI_EditStatusAndTags(t *testing.T) {
+	s := tuiStore(t)
+	app, err := tui.NewApp(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Feed([]byte("jj")) // exp_003
+	app.Feed([]byte("sf"))
+	exp, err := s.ReadExperiment("exp_003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Status != "failed" {
+		t.Errorf("expected status failed, got %s", exp.Status)
+	}
+	idx, _ := s.ReadIndex()
+	if idx.Computed.BestExperiment != "exp_002" {
+		t.Errorf("index should be rebuilt after the edit, best is %s", idx.Computed.BestExperiment)
+	}
+
+	app.Feed([]byte("e\x7fwarmup, sched\r"))
+	exp, _ = s.ReadExperiment("exp_003")
+	if strings.Join(exp.Tags, ",") != "warmup,sched" {
+		t.Errorf("unexpected tags: %v", exp.Tags)
+	}
+	if v := view(app); !strings.Contains(v, "exp_003: tags → warmup,sched") {
+		t.Errorf("expected a confirmation message:\n%s", v)
+	}
+
+	cl, err := s.ReadChangelog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edits := 0
+	for _, e := range cl.Entries {
+		if e.Action == "exp_edited" && e.ID == "exp_003" {
+			edits++
+		}
+	}
+	if edits != 2 {
+		t.Errorf("expected 2 changelog entries, got %d", edits)
+	}
+}
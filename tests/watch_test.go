@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzzdr/marrow/internal/mcp"
+	"github.com/rzzdr/marrow/internal/model"
+	"github.com/rzzdr/marrow/internal/store"
+)
+
+// stdioSession drives mcp.ServeIO over in-memory pipes.
+type stdioSession struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+}
+
+func startStdioSession(t *testing.T, s *store.Store, opts mcp.ServeOptions) (*stdioSession, func()) {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = mcp.ServeIO(ctx, s, opts, inR, outW)
+		outW.Close()
+	}()
+
+	lines := make(chan string, 64)
+	go func() {
+		sc := bufio.NewScanner(outR)
+		sc.Buffer(make([]byte, 1<<20), 1<<20)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+
+	sess := &stdioSession{t: t, in: inW, lines: lines}
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 0, "method": "initialize", "params": map[string]any{
+		"protocolVersion": "2025-03-26",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "test", "version": "0"},
+	}})
+	sess.expect(`"id":0`)
+	sess.send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+
+	return sess, func() {
+		inW.Close()
+		cancel()
+		<-done
+	}
+}
+
+func (s *stdioSession) send(msg map[string]any) {
+	s.t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if _, err := s.in.Write(append(data, '\n')); err != nil {
+		s.t.Fatalf("write: %v", err)
+	}
+}
+
+// expect waits for an output line containing all of the given substrings.
+func (s *stdioSession) expect(subs ...string) string {
+	s.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("session closed while waiting for %v", subs)
+			}
+			match := true
+			for _, sub := range subs {
+				if !strings.Contains(line, sub) {
+					match = false
+					break
+				}
+			}
+			if match {
+				return line
+			}
+		case <-timeout:
+			s.t.Fatalf("timed out waiting for %v", subs)
+		}
+	}
+}
+
+func TestWatch_NotifiesSubscribers(t *testing.T) {
+	s := setupTestStore(t)
+	sess, stop := startStdioSession(t, s, mcp.ServeOptions{WatchInterval: 20 * time.Millisecond})
+	defer stop()
+
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "resources/subscribe",
+		"params": map[string]any{"uri": "marrow://experiments/exp_001"}})
+	sess.expect(`"id":1`, `"result"`)
+
+	// Simulate a CLI write from another process.
+	exp := model.Experiment{ID: "exp_001", Timestamp: time.Now().UTC(), Status: "improved",
+		Metric: model.MetricResult{Name: "accuracy", Value: 0.9}}
+	if err := s.WriteExperiment(exp); err != nil {
+		t.Fatal(err)
+	}
+	sess.expect("notifications/resources/list_changed")
+	sess.expect("notifications/resources/updated", "marrow://experiments/exp_001")
+
+	// Unsubscribed resources do not notify.
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "resources/unsubscribe",
+		"params": map[string]any{"uri": "marrow://experiments/exp_001"}})
+	sess.expect(`"id":2`, `"result"`)
+	exp.Notes = "edited"
+	if err := s.WriteExperiment(exp); err != nil {
+		t.Fatal(err)
+	}
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 3, "method": "ping"})
+	time.Sleep(100 * time.Millisecond)
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 4, "method": "ping"})
+	for {
+		line := sess.expect(`"jsonrpc"`)
+		if strings.Contains(line, "resources/updated") {
+			t.Fatalf("unexpected notification after unsubscribe: %s", line)
+		}
+		if strings.Contains(line, `"id":4`) {
+			break
+		}
+	}
+}
+
+func TestWatch_SubscribeRequiresURI(t *testing.T) {
+	sess, stop := startStdioSession(t, setupTestStore(t), mcp.ServeOptions{WatchInterval: -1})
+	defer stop()
+
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "resources/subscribe", "params": map[string]any{}})
+	sess.expect(`"id":1`, `"error"`)
+
+	// Other requests still reach the server.
+	sess.send(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/call",
+		"params": map[string]any{"name": "get_project_summary", "arguments": map[string]any{}}})
+	sess.expect(`"id":2`, "test-project")
+}